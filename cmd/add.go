@@ -5,11 +5,9 @@ package cmd
 
 import (
 	zaplog "password_manager/common/log"
-	"password_manager/service/aes"
-	dbfilekit "password_manager/service/dbfile_Kit"
 	"password_manager/service/input"
 	"password_manager/service/password"
-	secretkey "password_manager/service/secret_key"
+	"strings"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -32,7 +30,38 @@ For example, to store a password for a website, you can use:
 The key should be a unique identifier (e.g., service name, username, or account),
 and the value is the password you want to store. The optional platform field can 
 be used to specify the service or application associated with the password.
-If you do not need to specify a platform, simply press Enter to skip.`,
+If you do not need to specify a platform, simply press Enter to skip.
+
+A username, URL and tags can be attached to the entry with flags. They are
+used by 'pm search':
+
+  pm add github_john.doe --username john.doe --url https://github.com --tag work,code
+
+With --env the password is stored as the value of an environment such as dev,
+staging or prod. When the key does not exist yet, the entry is created and the
+password is also used as its default value:
+
+  pm add prod_db --env prod
+
+With --type the entry is stored as a typed entry and the prompts follow the
+fields of that type. Values are validated, e.g. card numbers with the Luhn
+check, SSH private keys must be PEM encoded and URLs must be absolute.
+Supported types: login, api_key, card, identity, ssh_key, database, note.
+Fields can also be given with --field name=value, a value starting with @ is
+read from a file:
+
+  pm add visa --type card
+  pm add deploy_key --type ssh_key --field private_key=@$HOME/.ssh/id_ed25519
+
+The strength of every new password is shown with warnings about common words,
+keyboard patterns, sequences and dates. Passwords below the minimum score of
+the policy (see 'pm policy') are rejected unless --force is passed.
+
+With --expires the password expires on a date or has to be rotated after an
+interval counted from the last change. 'pm due' lists the entries to rotate:
+
+  pm add aws_root --expires 90d
+  pm add vpn --expires 2026-12-31`,
 	Run: func(cmd *cobra.Command, args []string) {
 		//初始化日志模块
 		if err := zaplog.LoggerInit(); err != nil {
@@ -55,52 +84,71 @@ If you do not need to specify a platform, simply press Enter to skip.`,
 			color.Red.Println("Key or account cannot be empty")
 			return
 		}
-		//获取密码的值
-		passwordValue, err := input.GetPasswordInput("Enter password")
+		if !checkExpiryFlag(cmd) {
+			return
+		}
+		env, err := cmd.Flags().GetString("env")
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		//输入平台
-		platform, err := input.GetOptionalInput("Enter platform (optional, press Enter to skip)")
+		if env != "" {
+			addEnvPassword(cmd, key, env)
+			return
+		}
+		kindName, err := cmd.Flags().GetString("type")
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		//初始化密钥模块
-		secretKeyInstance := secretkey.NewSecretKey()
-
-		//初始化数据库模块
-		kitInstance := dbfilekit.NewDBKit(secretKeyInstance)
-		if err := kitInstance.Init(); err != nil {
+		if kindName != "" || cmd.Flags().Changed("field") {
+			addRecord(cmd, key, kindName)
+			return
+		}
+		//获取密码的值
+		passwordValue, err := input.GetPasswordInput("Enter password")
+		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取数据库
-		db, err := kitInstance.GetDB()
+		//输入平台
+		platform, err := input.GetOptionalInput("Enter platform (optional, press Enter to skip)")
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取密钥
-		secretKey, err := secretKeyInstance.GetSecretKey()
+		//打开数据库
+		vaultInstance, err := openVault()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		//初始化加密模块
-		aesInstance := aes.NewAesService(secretKey)
-		//初始化密码保存模块
-		passwordInstance := password.NewPasswordService(aesInstance, db)
+		passwordInstance := vaultInstance.srv
+		username, _ := cmd.Flags().GetString("username")
+		if !checkPasswordStrength(cmd, vaultInstance, passwordValue, key, platform, username) {
+			return
+		}
 		err = passwordInstance.SavePassword(key, passwordValue, platform)
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
+		//保存附加信息
+		url, _ := cmd.Flags().GetString("url")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if username != "" || url != "" || len(tags) > 0 {
+			meta := password.Meta{Username: username, URL: url, Tags: tags}
+			if err := passwordInstance.SetMeta(key, meta); err != nil {
+				color.Red.Println(err)
+				return
+			}
+		}
+		//保存到期时间
+		if !applyExpiry(cmd, passwordInstance, key) {
+			return
+		}
 		//备份
-		err = kitInstance.BackupDB()
+		err = vaultInstance.kit.BackupDB()
 		if err != nil {
 			color.Red.Println(err)
 			return
@@ -109,8 +157,146 @@ If you do not need to specify a platform, simply press Enter to skip.`,
 	},
 }
 
+// addRecord 按照类型的字段定义输入并保存条目
+func addRecord(cmd *cobra.Command, key, kindName string) {
+	kind, err := password.LookupKind(kindName)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	fieldFlags, _ := cmd.Flags().GetStringArray("field")
+	fields, err := parseFieldFlags(fieldFlags)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	if cmd.Flags().Changed("username") {
+		fields[password.FieldUsername], _ = cmd.Flags().GetString("username")
+	}
+	if cmd.Flags().Changed("url") {
+		fields[password.FieldURL], _ = cmd.Flags().GetString("url")
+	}
+	//先检查参数中的字段，避免输入完才发现错误
+	for name := range fields {
+		if _, ok := kind.Field(name); !ok {
+			color.Red.Println("unknown field " + name + " for type " + kind.Name)
+			return
+		}
+	}
+	if err := promptRecordFields(kind, fields); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//输入平台
+	platform, err := input.GetOptionalInput("Enter platform (optional, press Enter to skip)")
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//打开数据库
+	vaultInstance, err := openVault()
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	passwordInstance := vaultInstance.srv
+	//登录和数据库的密码需要满足强度策略
+	if kind.Primary == "password" && fields["password"] != "" {
+		if !checkPasswordStrength(cmd, vaultInstance, fields["password"], key, platform, fields[password.FieldUsername]) {
+			return
+		}
+	}
+	if err := passwordInstance.SaveRecord(key, platform, password.Record{Type: kind.Name, Fields: fields}); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//保存标签
+	if tags, _ := cmd.Flags().GetStringSlice("tag"); len(tags) > 0 {
+		meta, err := passwordInstance.GetMeta(key)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		meta.Tags = tags
+		if err := passwordInstance.SetMeta(key, meta); err != nil {
+			color.Red.Println(err)
+			return
+		}
+	}
+	//保存到期时间
+	if !applyExpiry(cmd, passwordInstance, key) {
+		return
+	}
+	//备份
+	if err := vaultInstance.kit.BackupDB(); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	color.Green.Println(kind.Label + " saved and backup successfully!")
+}
+
+// addEnvPassword 保存 key 在指定环境下的密码，key 不存在时先创建条目
+func addEnvPassword(cmd *cobra.Command, key, env string) {
+	if err := password.ValidateEnv(env); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//打开数据库
+	vaultInstance, err := openVault()
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	passwordInstance := vaultInstance.srv
+	_, _, err = passwordInstance.GetPasswordWithKey(key)
+	exists := err == nil
+	//获取密码的值
+	passwordValue, err := input.GetPasswordInput("Enter password for " + env)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	if !checkPasswordStrength(cmd, vaultInstance, passwordValue, key, env) {
+		return
+	}
+	if !exists {
+		//输入平台
+		platform, err := input.GetOptionalInput("Enter platform (optional, press Enter to skip)")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if err := passwordInstance.SavePassword(key, passwordValue, platform); err != nil {
+			color.Red.Println(err)
+			return
+		}
+	}
+	if err := passwordInstance.SetEnvPassword(key, env, passwordValue); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//保存到期时间
+	if !applyExpiry(cmd, passwordInstance, key) {
+		return
+	}
+	//备份
+	if err := vaultInstance.kit.BackupDB(); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	color.Green.Println("Password for " + env + " saved and backup successfully!")
+}
+
 func init() {
 	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().String("username", "", "username of the account")
+	addCmd.Flags().String("url", "", "URL of the login page")
+	addCmd.Flags().StringSlice("tag", nil, "tags of the entry, can be repeated or comma separated")
+	addCmd.Flags().String("env", "", "store the password as the value of this environment, e.g. dev, staging, prod")
+	addCmd.Flags().String("type", "", "type of the entry: "+strings.Join(password.KindNames(), ", "))
+	addCmd.Flags().Bool("force", false, "save the password even if it does not meet the strength policy")
+	addCmd.Flags().StringArray("field", nil, "field of the typed entry as name=value, @file reads the value from a file")
+	addCmd.Flags().String("expires", "", "expiry date such as 2026-12-31 or rotation interval such as 90d")
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	zaplog "password_manager/common/log"
+	"password_manager/service/agent"
+	secretkey "password_manager/service/secret_key"
+	"syscall"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// agentCmd represents the agent command
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Keep the unlocked secret key in memory for other commands",
+	Long: `Start pm agent, a background process similar to ssh-agent or gpg-agent.
+
+The agent loads the secret key once, keeps it in memory for the given time and
+hands it out to other pm commands over a Unix socket. While the agent is running
+every command gets the key from the agent instead of reading the key file, so
+'pm lock' stops all commands until 'pm unlock' is run again.
+
+The socket is only accessible to the current user, and the agent also checks
+the user id of every connecting process where the platform supports it.
+
+The socket path is taken from the PM_AGENT_SOCK environment variable. If it is
+not set, $XDG_RUNTIME_DIR/pm-agent.sock or a private directory in the system
+temporary directory is used.
+
+The agent runs in the foreground; stop it with Ctrl-C.
+
+Example:
+  pm agent
+  pm agent --ttl 1h
+  pm lock
+  pm unlock`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		ttl, err := cmd.Flags().GetDuration("ttl")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if ttl <= 0 {
+			color.Red.Println("ttl must be positive")
+			return
+		}
+		socketPath := agent.DefaultSocketPath()
+		listener, err := agent.Listen(socketPath)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		defer listener.Close()
+
+		//初始化agent并加载密钥
+		server := agent.NewServer(secretkey.NewSecretKey(), ttl)
+		if err := server.Unlock(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		defer server.Lock()
+
+		//收到退出信号后关闭监听
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			<-signals
+			listener.Close()
+		}()
+
+		color.Green.Println("pm agent listening on " + socketPath)
+		color.Gray.Println("The key is kept for " + ttl.String() + ", press Ctrl-C to stop")
+		if err := server.Serve(listener); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Yellow.Println("pm agent stopped")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.Flags().Duration("ttl", agent.DefaultTTL, "clear the key from memory after this time")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// agentCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// agentCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	zaplog "password_manager/common/log"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// attachCmd represents the attach command
+var attachCmd = &cobra.Command{
+	Use:   "attach <key> <file>",
+	Short: "Attach an encrypted file to an entry",
+	Long: `Store a file such as a TLS private key, a kubeconfig or a PDF with recovery
+codes next to an entry.
+
+The file is encrypted in chunks with the same key as the passwords and saved in
+the database, so it is included in backups. The attachment is named after the
+file unless --name is given. An attachment may be at most 10 MiB and an entry
+may have at most 20 attachments. Use --force to replace an attachment with the
+same name.
+
+Example:
+  pm attach prod_cluster ~/.kube/config --name kubeconfig
+  pm attachments prod_cluster
+  pm extract prod_cluster kubeconfig -o ./kubeconfig`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		key, path := args[0], args[1]
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if name == "" {
+			name = filepath.Base(path)
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		defer file.Close()
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		info, err := vaultInstance.srv.AddAttachment(key, name, file, force)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//备份
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("attached " + info.Name + " (" + formatSize(info.Size) + ") to " + key + " and backup successfully!")
+	},
+}
+
+// formatSize 以易读的单位输出文件大小
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return strconv.FormatFloat(float64(size)/(1<<20), 'f', 1, 64) + " MiB"
+	case size >= 1<<10:
+		return strconv.FormatFloat(float64(size)/(1<<10), 'f', 1, 64) + " KiB"
+	default:
+		return strconv.FormatInt(size, 10) + " B"
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+	attachCmd.Flags().String("name", "", "name of the attachment (default: the file name)")
+	attachCmd.Flags().Bool("force", false, "replace an attachment with the same name")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// attachCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// attachCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
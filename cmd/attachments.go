@@ -0,0 +1,93 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	zaplog "password_manager/common/log"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// attachmentsCmd represents the attachments command
+var attachmentsCmd = &cobra.Command{
+	Use:   "attachments <key>",
+	Short: "List or delete the attachments of an entry",
+	Long: `List the attachments of an entry with their size, creation time and SHA-256.
+
+Use --delete to remove an attachment.
+
+Example:
+  pm attachments prod_cluster
+  pm attachments prod_cluster --delete kubeconfig`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		key := args[0]
+		deleteName, err := cmd.Flags().GetString("delete")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		passwordInstance := vaultInstance.srv
+		if _, _, err := passwordInstance.GetPasswordWithKey(key); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if deleteName != "" {
+			if err := passwordInstance.DeleteAttachment(key, deleteName); err != nil {
+				color.Red.Println(err)
+				return
+			}
+			if err := vaultInstance.kit.BackupDB(); err != nil {
+				color.Red.Println(err)
+				return
+			}
+			color.Green.Println("delete attachment " + deleteName + " of key:" + key + " success")
+			return
+		}
+		infos, err := passwordInstance.ListAttachments(key)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if len(infos) == 0 {
+			color.Yellow.Println("key:" + key + " has no attachments")
+			return
+		}
+		fmt.Println()
+		for _, info := range infos {
+			color.Blue.Printf("%-24s", info.Name)
+			color.Green.Printf(" %10s", formatSize(info.Size))
+			color.Gray.Println("  " + info.Created.Local().Format("2006-01-02 15:04") + "  sha256:" + info.SHA256[:12])
+		}
+		fmt.Println()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachmentsCmd)
+	attachmentsCmd.Flags().String("delete", "", "delete the attachment with this name")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// attachmentsCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// attachmentsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
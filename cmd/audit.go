@@ -0,0 +1,191 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/audit"
+	"password_manager/service/strength"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// 审计命令的退出码，发现问题时为 1，出错时为 2
+const (
+	auditExitIssues = 1
+	auditExitError  = 2
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Report weak, reused, similar and old passwords",
+	Long: `Check all stored passwords and report security issues:
+
+  - weak passwords, scored by the same estimator as 'pm add'
+  - the same password reused by several keys
+  - near-duplicate passwords, e.g. Summer2023! and Summer2024!
+  - passwords not changed for longer than --max-age
+  - entries without a platform
+
+Only entries that store a password (logins and databases) are checked for
+strength, reuse and similarity; cards, SSH keys and notes are skipped. The
+passwords of every environment (see 'pm add --env') are checked too and shown
+as "key (env: name)". Entries saved by older versions have no modification date and are listed separately.
+
+--tag and --folder restrict the audit to the entries with those tags or inside
+a folder; reuse and similarity are then only checked among those entries.
+
+The report is printed as a table, or as JSON with --format json. The exit code
+is 1 when any issue is found and 2 on errors, so the command can be used to
+gate CI jobs.
+
+Example:
+  pm audit
+  pm audit --max-age 90d --min-score 3
+  pm audit --format json > audit.json
+  pm audit --folder work --tag prod`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			os.Exit(auditExitError)
+		}
+		//标准输出只输出报告
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "json" {
+			color.Red.Println("unknown format " + format + ", expected table or json")
+			os.Exit(auditExitError)
+		}
+		maxAgeValue, _ := cmd.Flags().GetString("max-age")
+		maxAge, err := parseDays(maxAgeValue)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		distance, _ := cmd.Flags().GetInt("distance")
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		minScore, _ := cmd.Flags().GetInt("min-score")
+		if !cmd.Flags().Changed("min-score") {
+			//默认使用密码强度策略中的最低分数
+			db, err := vaultInstance.kit.GetDB()
+			if err != nil {
+				color.Red.Println(err)
+				os.Exit(auditExitError)
+			}
+			policy, err := strength.NewPolicyService(db).GetPolicy()
+			if err != nil {
+				color.Red.Println(err)
+				os.Exit(auditExitError)
+			}
+			//策略没有限制时使用建议的最低分数
+			if policy.MinScore > 0 {
+				minScore = policy.MinScore
+			}
+		}
+		entries, err := vaultInstance.srv.GetAllPasswords()
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		report := audit.Run(filter.Apply(entries), audit.Options{MinScore: minScore, MaxAge: maxAge, MaxDistance: distance})
+		if format == "json" {
+			encoder := json.NewEncoder(stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				color.Red.Println(err)
+				os.Exit(auditExitError)
+			}
+		} else {
+			printAuditReport(stdout, report)
+		}
+		if report.Issues() > 0 {
+			os.Exit(auditExitIssues)
+		}
+	},
+}
+
+// printAuditReport 以表格输出审计结果
+func printAuditReport(out io.Writer, report audit.Report) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	section := func(title string, count int) bool {
+		fmt.Fprintln(w, "\n"+title+" ("+strconv.Itoa(count)+")")
+		return count > 0
+	}
+	if section("Weak passwords", len(report.Weak)) {
+		fmt.Fprintln(w, "  KEY\tSCORE\tWARNING")
+		for _, entry := range report.Weak {
+			fmt.Fprintln(w, "  "+entry.Key+"\t"+strconv.Itoa(entry.Score)+" ("+strength.Label(entry.Score)+")\t"+strings.Join(entry.Warnings, "; "))
+		}
+	}
+	if section("Reused passwords", len(report.Reused)) {
+		for _, group := range report.Reused {
+			fmt.Fprintln(w, "  "+strings.Join(group.Keys, ", "))
+		}
+	}
+	if section("Similar passwords", len(report.Similar)) {
+		fmt.Fprintln(w, "  KEY\tOTHER\tDISTANCE")
+		for _, pair := range report.Similar {
+			fmt.Fprintln(w, "  "+pair.Key+"\t"+pair.Other+"\t"+strconv.Itoa(pair.Distance))
+		}
+	}
+	if section("Old passwords", len(report.Old)) {
+		fmt.Fprintln(w, "  KEY\tLAST CHANGED\tAGE")
+		for _, entry := range report.Old {
+			fmt.Fprintln(w, "  "+entry.Key+"\t"+entry.Modified.Local().Format("2006-01-02")+"\t"+strconv.Itoa(entry.AgeDays)+" days")
+		}
+	}
+	if section("Missing platform", len(report.MissingPlatform)) {
+		fmt.Fprintln(w, "  "+strings.Join(report.MissingPlatform, ", "))
+	}
+	if len(report.UnknownAge) > 0 {
+		fmt.Fprintln(w, "\nNo modification date (saved by an older version): "+strings.Join(report.UnknownAge, ", "))
+	}
+	fmt.Fprintln(w, "\n"+strconv.Itoa(report.Issues())+" issues found in "+strconv.Itoa(report.Total)+" entries")
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().String("format", "table", "output format: table or json")
+	auditCmd.Flags().String("max-age", "365d", "report passwords not changed for longer than this, e.g. 90d, 0 disables the check")
+	auditCmd.Flags().Int("min-score", strength.RecommendedMinScore, "report passwords below this score, defaults to the policy (see 'pm policy') or 2 when it is off")
+	addFilterFlags(auditCmd)
+	auditCmd.Flags().Int("distance", audit.DefaultMaxDistance, "report passwords within this edit distance of each other, 0 disables the check")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// auditCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// auditCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
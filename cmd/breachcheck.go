@@ -0,0 +1,182 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/breach"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// breachCorpusEnv 默认哈希库位置的环境变量
+const breachCorpusEnv = "PM_BREACH_CORPUS"
+
+// breachCheckCmd represents the breach-check command
+var breachCheckCmd = &cobra.Command{
+	Use:   "breach-check",
+	Short: "Check stored passwords against a breached password corpus",
+	Long: `Compare the SHA-1 hashes of all stored passwords with a Pwned Passwords style
+corpus and report the entries whose password appears in it. The corpus is one of:
+
+  - a directory of range files named by the 5 character hash prefix, e.g.
+    5BAA6.txt, each holding sorted SUFFIX:COUNT lines as served by the
+    Pwned Passwords range API
+  - a single binary file built with --build from the sorted HASH:COUNT dump
+  - an http(s) range endpoint, queried as <url>/range/<prefix>; only the hash
+    prefix leaves the machine
+
+The corpus defaults to the PM_BREACH_CORPUS environment variable. Environment
+passwords of database entries are checked as well; cards, SSH keys and notes
+are skipped. The exit code is 1 when a breached password is found and 2 on
+errors.
+
+Example:
+  pm breach-check --corpus ~/pwned/ranges
+  pm breach-check --build pwned-passwords-sha1-ordered-by-hash.txt --corpus ~/pwned/pwned.bin
+  pm breach-check --corpus https://api.pwnedpasswords.com --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			os.Exit(auditExitError)
+		}
+		//标准输出只输出报告
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "json" {
+			color.Red.Println("unknown format " + format + ", expected table or json")
+			os.Exit(auditExitError)
+		}
+		corpus, _ := cmd.Flags().GetString("corpus")
+		if corpus == "" {
+			corpus = os.Getenv(breachCorpusEnv)
+		}
+		if corpus == "" {
+			color.Red.Println("no corpus given, use --corpus or set " + breachCorpusEnv)
+			os.Exit(auditExitError)
+		}
+		//先把文本导出转换成二进制文件
+		if build, _ := cmd.Flags().GetString("build"); build != "" {
+			count, err := buildBreachCorpus(build, corpus)
+			if err != nil {
+				color.Red.Println(err)
+				os.Exit(auditExitError)
+			}
+			color.Green.Println("wrote " + strconv.Itoa(count) + " hashes to " + corpus)
+		}
+		source, err := breach.Open(corpus)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		if closer, ok := source.(io.Closer); ok {
+			defer closer.Close()
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		entries, err := vaultInstance.srv.GetAllPasswords()
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		results, err := breach.Check(source, entries)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		if format == "json" {
+			encoder := json.NewEncoder(stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(results); err != nil {
+				color.Red.Println(err)
+				os.Exit(auditExitError)
+			}
+		} else {
+			printBreachResults(stdout, results, len(entries))
+		}
+		if len(results) > 0 {
+			os.Exit(auditExitIssues)
+		}
+	},
+}
+
+// buildBreachCorpus 把排好序的 HASH:COUNT 文本转换成二进制哈希库
+func buildBreachCorpus(input, output string) (int, error) {
+	in, err := os.Open(input)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	out, err := os.Create(output)
+	if err != nil {
+		return 0, err
+	}
+	writer := bufio.NewWriter(out)
+	count, err := breach.WriteBinary(writer, in)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return 0, err
+	}
+	return count, nil
+}
+
+// printBreachResults 以表格输出泄露的条目
+func printBreachResults(out io.Writer, results []breach.Result, total int) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if len(results) > 0 {
+		fmt.Fprintln(w, "KEY\tENV\tSEEN")
+		for _, result := range results {
+			env := result.Env
+			if env == "" {
+				env = "-"
+			}
+			fmt.Fprintln(w, result.Key+"\t"+env+"\t"+strconv.Itoa(result.Count)+" times")
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, strconv.Itoa(len(results))+" breached passwords found in "+strconv.Itoa(total)+" entries")
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(breachCheckCmd)
+	breachCheckCmd.Flags().String("corpus", "", "corpus directory, binary file or range API url, defaults to $"+breachCorpusEnv)
+	breachCheckCmd.Flags().String("build", "", "convert this sorted HASH:COUNT text dump into the binary file given by --corpus first")
+	breachCheckCmd.Flags().String("format", "table", "output format: table or json")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// breachCheckCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// breachCheckCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -6,11 +6,7 @@ package cmd
 import (
 	"fmt"
 	zaplog "password_manager/common/log"
-	"password_manager/service/aes"
-	dbfilekit "password_manager/service/dbfile_Kit"
 	"password_manager/service/input"
-	"password_manager/service/password"
-	secretkey "password_manager/service/secret_key"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -32,6 +28,10 @@ Example usage:
 You will be prompted to enter the key associated with the password you want to delete.
 If the key exists, the password will be permanently removed from the database.
 
+With --env only the value of that environment is removed and the entry is kept:
+
+  pm del prod_db --env staging
+
 Warning: This action is irreversible.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		//初始化日志模块
@@ -55,56 +55,50 @@ Warning: This action is irreversible.`,
 			color.Red.Println("key is empty")
 			return
 		}
-		actionConfirm, err := input.GetInput("Are you sure to delete key:" + key + " (y/n)")
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		target := "key:" + key
+		if env != "" {
+			target = "environment " + env + " of key:" + key
+		}
+		actionConfirm, err := input.GetInput("Are you sure to delete " + target + " (y/n)")
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
 		if actionConfirm == "n" {
-			fmt.Println("delete key:" + key + " cancel")
+			fmt.Println("delete " + target + " cancel")
 			return
 		} else if actionConfirm != "y" {
 			fmt.Println("invalid input")
 			return
 		}
-		//初始化密钥模块
-		secretKeyInstance := secretkey.NewSecretKey()
-
-		//初始化数据库模块
-		kitInstance := dbfilekit.NewDBKit(secretKeyInstance)
-		if err := kitInstance.Init(); err != nil {
-			color.Red.Println(err)
-			return
-		}
-
-		//获取数据库
-		db, err := kitInstance.GetDB()
+		//打开数据库
+		vaultInstance, err := openVault()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取密钥
-		secretKey, err := secretKeyInstance.GetSecretKey()
-		if err != nil {
-			color.Red.Println(err)
-			return
+		passwordInstance := vaultInstance.srv
+		if env != "" {
+			err = passwordInstance.DeleteEnvPassword(key, env)
+		} else {
+			err = passwordInstance.DeletePassword(key)
 		}
-		//初始化加密模块
-		aesInstance := aes.NewAesService(secretKey)
-		//初始化密码保存模块
-		passwordInstance := password.NewPasswordService(aesInstance, db)
-		err = passwordInstance.DeletePassword(key)
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		color.Green.Println("delete key:" + key + " success")
+		color.Green.Println("delete " + target + " success")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(delCmd)
+	delCmd.Flags().String("env", "", "delete only the value of this environment")
 
 	// Here you will define your flags and configuration settings.
 
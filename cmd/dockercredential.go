@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/dockercredential"
+
+	"github.com/spf13/cobra"
+)
+
+// dockerCredentialCmd represents the docker-credential command
+var dockerCredentialCmd = &cobra.Command{
+	Use:   "docker-credential <store|get|erase|list|version>",
+	Short: "Docker credential helper backed by pm",
+	Long: `Keep Docker registry credentials in pm instead of ~/.docker/config.json.
+
+This command implements the docker-credential-helpers protocol: 'store' reads
+{"ServerURL","Username","Secret"} as JSON from stdin, 'get' and 'erase' read
+the server URL, and 'list' prints all server URLs with their usernames.
+
+Registry credentials are stored with the platform 'docker-registry', the
+server URL as URL and the tag 'docker'. Entries you add yourself are used as
+well when they have the 'docker' tag and the server URL as URL.
+
+Docker looks for a program named docker-credential-<name>, so create a link
+named docker-credential-pm to the pm executable on your PATH and set:
+
+  ~/.docker/config.json:
+  { "credsStore": "pm" }
+
+Example:
+  ln -s "$(command -v pm)" /usr/local/bin/docker-credential-pm
+  echo https://index.docker.io/v1/ | pm docker-credential get`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//标准输出用于和 docker 通信，其他输出都写到标准错误
+		stdout, restore := useStdoutForProtocol()
+		//协议要求出错时把错误信息写到标准输出并以状态码 1 退出
+		fail := func(err error) {
+			fmt.Fprintln(stdout, err)
+			restore()
+			os.Exit(1)
+		}
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			fail(err)
+		}
+		if args[0] == dockercredential.ActionVersion {
+			fmt.Fprintln(stdout, dockercredential.Version)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			fail(err)
+		}
+		helper := dockercredential.NewHelper(vaultInstance.srv)
+		err = helper.Serve(args[0], os.Stdin, stdout)
+		vaultInstance.kit.Close()
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dockerCredentialCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// dockerCredentialCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// dockerCredentialCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/password"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// dueCmd represents the due command
+var dueCmd = &cobra.Command{
+	Use:   "due",
+	Short: "List passwords that are expired or need rotating soon",
+	Long: `List the entries whose password has expired or has to be rotated within
+--within (14 days by default), sorted by due date.
+
+An entry expires on a fixed date or after a rotation interval counted from the
+last password change. Both are set with --expires on 'pm add' and 'pm update':
+
+  pm update aws_root --expires 90d
+  pm update vpn --expires 2026-12-31
+  pm update vpn --expires never
+
+Entries with a rotation interval but without a modification date (saved by
+older versions) are always due. The list is printed as a table, or as JSON with
+--format json. The exit code is 1 when any entry is due and 2 on errors, so the
+command can be used in cron jobs.
+
+Example:
+  pm due
+  pm due --within 30d
+  pm due --within 0 --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			os.Exit(auditExitError)
+		}
+		//标准输出只输出结果
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "json" {
+			color.Red.Println("unknown format " + format + ", expected table or json")
+			os.Exit(auditExitError)
+		}
+		withinValue, _ := cmd.Flags().GetString("within")
+		within, err := parseDays(withinValue)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		entries, err := vaultInstance.srv.GetAllPasswords()
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(auditExitError)
+		}
+		now := time.Now()
+		due := password.DueEntries(entries, within, now)
+		if format == "json" {
+			if due == nil {
+				due = []password.DueEntry{}
+			}
+			encoder := json.NewEncoder(stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(due); err != nil {
+				color.Red.Println(err)
+				os.Exit(auditExitError)
+			}
+		} else {
+			printDueEntries(stdout, due, now)
+		}
+		if len(due) > 0 {
+			os.Exit(auditExitIssues)
+		}
+	},
+}
+
+// printDueEntries 以表格输出需要轮换的条目
+func printDueEntries(out io.Writer, due []password.DueEntry, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if len(due) > 0 {
+		fmt.Fprintln(w, "KEY\tDUE\tSTATUS")
+		for _, entry := range due {
+			date := "-"
+			if !entry.Due.IsZero() {
+				date = entry.Due.Local().Format(password.ExpiryDateLayout)
+			}
+			fmt.Fprintln(w, entry.Key+"\t"+date+"\t"+describeDue(entry.Due, now))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, strconv.Itoa(len(due))+" passwords due")
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(dueCmd)
+	dueCmd.Flags().String("within", "14d", "also list passwords due within this time, e.g. 30d, 0 lists expired ones only")
+	dueCmd.Flags().String("format", "table", "output format: table or json")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// dueCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// dueCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseDays 解析时间长度，除了 time.ParseDuration 支持的格式外还支持 d（天）和 w（周），例如 90d、2w
+func parseDays(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "0" {
+		return 0, nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, errors.New("invalid duration " + value + ", expected e.g. 90d, 2w or 12h")
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, errors.New("invalid duration " + value + ", expected e.g. 90d, 2w or 12h")
+	}
+	return duration, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/dotenv"
+	"path/filepath"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env <group>",
+	Short: "Print the secrets of a group as shell export lines",
+	Long: `Print every entry of a group as a quoted variable assignment.
+
+A group contains the entries whose platform is <group> or that carry the tag
+<group>. The variable name is the key without the '<group>.' prefix added by
+'pm import'; other characters that are not allowed in a variable name are
+replaced by '_'.
+
+--shell selects the syntax: bash, zsh, fish or dotenv. The default is fish
+when $SHELL is fish and bash otherwise. Only the assignments are written to
+stdout.
+
+--tag and --folder only export the entries of the group that also carry the
+given tags or are inside the folder, e.g. one environment of an application.
+
+Example:
+  eval "$(pm env myapp)"
+  pm env myapp --shell fish | source
+  pm env myapp --shell dotenv > .env.local
+  pm env myapp --folder myapp/prod`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//标准输出只输出变量
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		shell, err := cmd.Flags().GetString("shell")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		if shell == "" {
+			shell = defaultEnvShell()
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		variables, err := dotenv.ExportFiltered(vaultInstance.srv, args[0], filter)
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		out, err := dotenv.Format(variables, shell)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		fmt.Fprint(stdout, out)
+	},
+}
+
+// defaultEnvShell 根据 $SHELL 选择输出的语法
+func defaultEnvShell() string {
+	if filepath.Base(os.Getenv("SHELL")) == dotenv.ShellFish {
+		return dotenv.ShellFish
+	}
+	return dotenv.ShellBash
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	addFilterFlags(envCmd)
+	envCmd.Flags().StringP("shell", "s", "", "output syntax: "+strings.Join(dotenv.Shells, ", ")+" (default from $SHELL)")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// envCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// envCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
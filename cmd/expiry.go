@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"password_manager/service/password"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// dueSoonWindow 提示即将到期的时间范围，也是 pm due 的默认范围
+const dueSoonWindow = 14 * 24 * time.Hour
+
+// parseExpiry 解析 --expires，日期设置到期日期，时间长度设置轮换周期，never 清除两者
+func parseExpiry(value string) (string, int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "never" || value == "0" {
+		return "", 0, nil
+	}
+	if _, err := password.ParseExpiryDate(value); err == nil {
+		return value, 0, nil
+	}
+	rotation, err := parseDays(value)
+	if err != nil {
+		return "", 0, errors.New("invalid expiry " + value + ", expected a date such as 2026-12-31, an interval such as 90d, or never")
+	}
+	day := 24 * time.Hour
+	if rotation < day || rotation%day != 0 {
+		return "", 0, errors.New("invalid rotation interval " + value + ", expected whole days such as 90d")
+	}
+	return "", int(rotation / day), nil
+}
+
+// checkExpiryFlag 在保存之前检查 --expires 的格式
+func checkExpiryFlag(cmd *cobra.Command) bool {
+	value, _ := cmd.Flags().GetString("expires")
+	if _, _, err := parseExpiry(value); err != nil {
+		color.Red.Println(err)
+		return false
+	}
+	return true
+}
+
+// applyExpiry 保存 --expires 设置的到期时间，没有使用该参数时直接返回 true
+func applyExpiry(cmd *cobra.Command, srv *password.PasswordService, key string) bool {
+	if !cmd.Flags().Changed("expires") {
+		return true
+	}
+	value, _ := cmd.Flags().GetString("expires")
+	expiresAt, rotationDays, err := parseExpiry(value)
+	if err == nil {
+		err = srv.SetExpiry(key, expiresAt, rotationDays)
+	}
+	if err != nil {
+		color.Red.Println(err)
+		return false
+	}
+	return true
+}
+
+// describeDue 描述轮换时间，例如 "expired 3 days ago"、"due in 5 days"
+func describeDue(due time.Time, now time.Time) string {
+	if due.IsZero() {
+		return "rotation due, last change unknown"
+	}
+	days := int(due.Sub(now).Hours() / 24)
+	switch {
+	case !now.Before(due) && days == 0:
+		return "expired today"
+	case !now.Before(due):
+		return "expired " + strconv.Itoa(-days) + " days ago"
+	case days == 0:
+		return "due today"
+	default:
+		return "due in " + strconv.Itoa(days) + " days"
+	}
+}
+
+// printExpiryWarning 条目已经到期时输出红色提示，window 内即将到期时输出黄色提示
+func printExpiryWarning(data password.PasswordData, window time.Duration) {
+	due, ok := data.DueDate()
+	now := time.Now()
+	if !ok || due.After(now.Add(window)) {
+		return
+	}
+	message := describeDue(due, now)
+	if !due.IsZero() {
+		message += " (" + due.Local().Format(password.ExpiryDateLayout) + ")"
+	}
+	if data.Expired(now) {
+		color.Red.Println("  ! password " + message + ", please rotate it")
+	} else {
+		color.Yellow.Println("  ! password " + message)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	zaplog "password_manager/common/log"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// extractCmd represents the extract command
+var extractCmd = &cobra.Command{
+	Use:   "extract <key> <name> [-o path]",
+	Short: "Decrypt an attachment to a file",
+	Long: `Decrypt an attachment of an entry and write it to a file.
+
+The file is written to --out (default: the attachment name in the current
+directory) with 0600 permissions. An existing file is only replaced with
+--force. Use '-o -' to write the content to stdout. The content is checked
+against the SHA-256 recorded when it was attached.
+
+Example:
+  pm extract prod_cluster kubeconfig -o ~/.kube/config --force
+  pm extract server id_ed25519 -o - | ssh-add -`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		//输出到标准输出时不能混入其他内容
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		key, name := args[0], args[1]
+		out, err := cmd.Flags().GetString("out")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		if out == "" {
+			out = name
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		if out != "-" && !force {
+			if _, err := os.Stat(out); err == nil {
+				color.Red.Println(out + " already exists, use --force to replace it")
+				os.Exit(1)
+			}
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		content, _, err := vaultInstance.srv.GetAttachment(key, name)
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		if out == "-" {
+			if _, err := stdout.Write(content); err != nil {
+				color.Red.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := writePrivateFile(out, content); err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		color.Green.Println("extracted " + name + " to " + out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(extractCmd)
+	extractCmd.Flags().StringP("out", "o", "", "file to write to, '-' for stdout (default: the attachment name)")
+	extractCmd.Flags().Bool("force", false, "replace an existing file")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// extractCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// extractCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"password_manager/service/password"
+
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags 添加按标签和文件夹筛选条目的参数
+func addFilterFlags(command *cobra.Command) {
+	command.Flags().StringSlice("tag", nil, "only entries with all of these tags, can be repeated or comma separated")
+	command.Flags().String("folder", "", "only entries in this folder or its subfolders, e.g. work/aws")
+}
+
+// filterFromFlags 根据 --tag 和 --folder 生成筛选条件
+func filterFromFlags(command *cobra.Command) (password.Filter, error) {
+	tags, _ := command.Flags().GetStringSlice("tag")
+	folder, _ := command.Flags().GetString("folder")
+	folder, err := password.NormalizeFolder(folder)
+	if err != nil {
+		return password.Filter{}, err
+	}
+	return password.Filter{Tags: password.NormalizeTags(tags), Folder: folder}, nil
+}
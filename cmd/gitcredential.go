@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/gitcredential"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// gitCredentialCmd represents the git-credential command
+var gitCredentialCmd = &cobra.Command{
+	Use:   "git-credential <get|store|erase>",
+	Short: "Git credential helper backed by pm",
+	Long: `Let git read and save its credentials in pm.
+
+This command implements the git credential helper protocol: git writes
+key=value lines to stdin and, for 'get', reads the username and password from
+stdout. Entries are matched by platform = host (for example github.com), the
+username of the entry and, when the entry has a URL, the protocol.
+
+  get     print the matching username and password
+  store   save the credential git used successfully; new entries are named
+          username@host and tagged 'git'
+  erase   delete the matching entry when git rejects it, only if the
+          password is the same; without a password the host and username
+          must both match
+
+Configure git to use it:
+  git config --global credential.helper '!pm git-credential'
+
+or create a link named git-credential-pm to the pm executable on your PATH and
+use 'credential.helper pm'.
+
+To use a token stored manually, enter the host as its platform and set the
+user name with --username:
+  pm add github_token --username john    # platform: github.com`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//标准输出用于和 git 通信，其他输出都写到标准错误
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		defer vaultInstance.kit.Close()
+		helper := gitcredential.NewHelper(vaultInstance.srv)
+		if err := helper.Run(args[0], os.Stdin, stdout); err != nil {
+			color.Red.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gitCredentialCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// gitCredentialCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// gitCredentialCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"io"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/dotenv"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// importFormats 支持导入的格式
+var importFormats = []string{"dotenv"}
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import --format dotenv --group <group> [file]",
+	Short: "Import secrets from a file into a group",
+	Long: `Import secrets from a file, or stdin when no file is given.
+
+Supported formats:
+  dotenv   a .env file. Every variable becomes an entry named <group>.<NAME>
+           with <group> as its platform and as a tag. Comments, 'export'
+           prefixes, single quotes and double quotes with escapes are
+           understood; ${VAR} is not expanded. Empty values are skipped.
+
+Existing entries are skipped unless --overwrite is given. Use 'pm env' to load
+the group back into a shell.
+
+Example:
+  pm import --format dotenv --group myapp .env
+  pm env myapp`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if format != "dotenv" {
+			color.Red.Println("unsupported format " + format + ", expected one of " + strings.Join(importFormats, ", "))
+			return
+		}
+		group, err := cmd.Flags().GetString("group")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if group == "" {
+			color.Red.Println("--group is required")
+			return
+		}
+		overwrite, err := cmd.Flags().GetBool("overwrite")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		var input io.Reader = os.Stdin
+		if len(args) == 1 && args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			defer file.Close()
+			input = file
+		}
+		variables, err := dotenv.Parse(input)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		defer vaultInstance.kit.Close()
+		result, err := dotenv.Import(vaultInstance.srv, group, variables, overwrite)
+		printImportResult(result)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+	},
+}
+
+// printImportResult 输出导入的结果
+func printImportResult(result dotenv.ImportResult) {
+	for _, key := range result.Added {
+		color.Green.Println("added: " + key)
+	}
+	for _, key := range result.Updated {
+		color.Green.Println("updated: " + key)
+	}
+	for _, key := range result.Skipped {
+		color.Yellow.Println("skipped: " + key)
+	}
+	color.Blue.Println("imported " + strconv.Itoa(len(result.Added)+len(result.Updated)) + ", skipped " + strconv.Itoa(len(result.Skipped)))
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringP("format", "f", "dotenv", "format of the input: "+strings.Join(importFormats, ", "))
+	importCmd.Flags().StringP("group", "g", "", "platform and tag of the imported entries")
+	importCmd.Flags().Bool("overwrite", false, "update the password of existing entries")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// importCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// importCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"io"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/secretref"
+	"path/filepath"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// injectCmd represents the inject command
+var injectCmd = &cobra.Command{
+	Use:   "inject [-i template] [-o output]",
+	Short: "Render a template with secret references",
+	Long: `Replace every {{ pm://[vault/]key[/field] }} in a template with its value.
+
+The template is read from --in-file (default stdin) and the result is written
+to --out-file (default stdout). Other {{ ... }} blocks are left untouched. See
+'pm read --help' for the reference syntax.
+
+If any reference cannot be resolved, all failing references are reported with
+their line numbers and nothing is written. The output file is created with
+0600 permissions and replaced atomically, an existing file gets the same
+permissions.
+
+Use --env to render the passwords of an environment (see 'pm add --env').
+
+Example:
+  pm inject -i config.tpl -o config.yaml
+  pm inject --env prod -i config.tpl -o config.prod.yaml
+
+config.tpl:
+  database:
+    user: {{ pm://prod_db/username }}
+    password: {{ pm://prod_db }}`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		//输出到标准输出时不能混入其他内容
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		inFile, err := cmd.Flags().GetString("in-file")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		outFile, err := cmd.Flags().GetString("out-file")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		var template []byte
+		if inFile == "" || inFile == "-" {
+			template, err = io.ReadAll(os.Stdin)
+		} else {
+			template, err = os.ReadFile(inFile)
+		}
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		rendered, err := secretref.Render(template, secretref.NewResolver(vaultInstance.srv).WithEnv(env).ResolveRef)
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		if outFile == "" || outFile == "-" {
+			if _, err := stdout.Write(rendered); err != nil {
+				color.Red.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := writePrivateFile(outFile, rendered); err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// writePrivateFile 以 0600 权限写入文件，先写到同目录的临时文件再替换，避免留下不完整的文件
+func writePrivateFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func init() {
+	rootCmd.AddCommand(injectCmd)
+	injectCmd.Flags().StringP("in-file", "i", "", "template to render (default stdin)")
+	injectCmd.Flags().StringP("out-file", "o", "", "file to write the result to (default stdout)")
+	injectCmd.Flags().String("env", "", "render the passwords of this environment")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// injectCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// injectCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
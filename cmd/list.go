@@ -5,11 +5,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	zaplog "password_manager/common/log"
-	"password_manager/service/aes"
-	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/listing"
 	"password_manager/service/password"
-	secretkey "password_manager/service/secret_key"
+	"strconv"
+	"strings"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -31,66 +32,208 @@ Output:
   github_john.doe (GitHub) : my-secure-password123
   email_jane.doe : another-password
 
-If a platform is associated with a password, it will be shown in parentheses next to the key.`,
+If a platform is associated with a password, it will be shown in parentheses next to the key.
+Entries with environment values (see 'pm add --env') show the environments below the entry:
+
+  prod_db (postgres) : default-password
+    envs: dev, prod
+
+Expired entries (see 'pm due') are marked with a warning below the entry.
+
+--tag and --folder only list the entries with all the given tags or inside a
+folder and its subfolders (see 'pm tag' and 'pm mv'). --tree shows the folders
+as a tree with the keys, platforms and tags of the entries but no passwords:
+
+  pm list --tree
+  /
+  ├── work/
+  │   ├── aws/
+  │   │   └── aws_root (aws)  #cloud
+  │   └── github_john.doe (GitHub)  #code
+  └── email_jane.doe
+
+  pm list --folder work/aws --tag cloud
+
+Entries are sorted by key. --sort key|platform|updated|created|last-used|uses
+changes the order (updated, created and last-used list the most recent first,
+uses the most used first; see 'pm stats') and --reverse inverts it; entries
+with the same value are always ordered by key. Sorting by created, last-used
+or uses also shows those values below each entry.
+
+--filter keeps the entries matching an expression <field><op><pattern>, where
+field is key, platform, tag, folder, type, username or url. = and != match a
+case-insensitive glob in which * also matches /, so folder=work* includes
+work/aws; ~ and !~ match a regular expression. Several filters must all match:
+
+  pm list --filter platform=github --filter 'key=*_work'
+  pm list --filter 'key~^(db|cache)_' --filter tag!=archived
+
+--limit and --offset show one page of the result, --group-by platform|folder|type
+prints the entries under a heading per group, and --keys-only prints just the
+keys, one per line, without decrypting any secret:
+
+  pm list --sort updated --limit 20
+  pm list --sort last-used --reverse --limit 10
+  pm list --limit 20 --offset 20
+  pm list --group-by platform
+  pm list --keys-only --filter platform=aws | xargs -n1 pm query`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 0 {
 			color.Red.Println("invalid input")
 			return
 		}
-		//初始化日志模块
-		if err := zaplog.LoggerInit(); err != nil {
+		keysOnly, _ := cmd.Flags().GetBool("keys-only")
+		tree, _ := cmd.Flags().GetBool("tree")
+		stdout := os.Stdout
+		if keysOnly {
+			//标准输出只输出 key，方便在脚本中使用
+			var restore func()
+			stdout, restore = useStdoutForProtocol()
+			defer restore()
+			if err := zaplog.LoggerInitFileOnly(); err != nil {
+				color.Red.Println(err)
+				return
+			}
+		} else if err := zaplog.LoggerInit(); err != nil {
+			//初始化日志模块
 			color.Red.Println(err)
 			return
 		}
-		//初始化密钥模块
-		secretKeyInstance := secretkey.NewSecretKey()
-
-		//初始化数据库模块
-		kitInstance := dbfilekit.NewDBKit(secretKeyInstance)
-		if err := kitInstance.Init(); err != nil {
+		opts, err := listOptionsFromFlags(cmd)
+		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取数据库
-		db, err := kitInstance.GetDB()
+		//打开数据库
+		vaultInstance, err := openVault()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取密钥
-		secretKey, err := secretKeyInstance.GetSecretKey()
+		passwordInstance := vaultInstance.srv
+		//筛选、排序和分页都不需要解密，只解密这一页要输出的密码
+		entries, err := passwordInstance.GetAllEntries()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		//初始化加密模块
-		aesInstance := aes.NewAesService(secretKey)
-		//初始化密码保存模块
-		passwordInstance := password.NewPasswordService(aesInstance, db)
-		results, err := passwordInstance.GetAllPasswords()
+		page, err := listing.Apply(entries, opts)
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		for k, v := range results {
-			if v.Platform == "" {
-				color.Blue.Printf("\n" + k + " : ")
-				color.Green.Printf(v.Password + "\n")
-			} else {
-				color.Blue.Printf("\n" + k)
-				color.Cyan.Printf(" (" + v.Platform + ") : ")
-				color.Green.Printf(v.Password + "\n")
+		if !keysOnly && !tree {
+			keys := make([]string, len(page.Entries))
+			for i, v := range page.Entries {
+				keys[i] = v.Key
+			}
+			if page.Entries, err = passwordInstance.GetPasswordsWithKeys(keys...); err != nil {
+				color.Red.Println(err)
+				return
+			}
+		}
+		//按创建或使用情况排序时输出排序的依据
+		showActivity := opts.Sort == listing.SortCreated || opts.Sort == listing.SortLastUsed || opts.Sort == listing.SortUses
+		switch {
+		case keysOnly:
+			for _, v := range page.Entries {
+				fmt.Fprintln(stdout, v.Key)
+			}
+			return
+		case tree:
+			//按文件夹输出树
+			fmt.Println()
+			color.Yellow.Println(password.FolderSeparator)
+			printFolderTree(buildFolderTree(page.Entries), "")
+		case opts.GroupBy != "":
+			groups, err := listing.Groups(page.Entries, opts.GroupBy)
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			for _, group := range groups {
+				name := group.Name
+				if name == "" {
+					name = "(no " + opts.GroupBy + ")"
+				}
+				color.Yellow.Println("\n== " + name + " (" + strconv.Itoa(len(group.Entries)) + ")")
+				for _, v := range group.Entries {
+					printListEntry(v, showActivity)
+				}
+			}
+		default:
+			for _, v := range page.Entries {
+				printListEntry(v, showActivity)
 			}
-
 		}
 		fmt.Println()
+		if opts.Offset > 0 || opts.Limit > 0 {
+			if len(page.Entries) == 0 {
+				color.Gray.Println("no entries after offset " + strconv.Itoa(opts.Offset) + " of " + strconv.Itoa(page.Total))
+			} else {
+				color.Gray.Println("showing " + strconv.Itoa(opts.Offset+1) + "-" + strconv.Itoa(opts.Offset+len(page.Entries)) + " of " + strconv.Itoa(page.Total))
+			}
+		}
 	},
 }
 
+// listOptionsFromFlags 根据参数生成筛选、排序、分组和分页的选项
+func listOptionsFromFlags(cmd *cobra.Command) (listing.Options, error) {
+	var opts listing.Options
+	filter, err := filterFromFlags(cmd)
+	if err != nil {
+		return opts, err
+	}
+	if !filter.Empty() {
+		opts.Filters = append(opts.Filters, filter.Match)
+	}
+	expressions, _ := cmd.Flags().GetStringArray("filter")
+	for _, expr := range expressions {
+		predicate, err := listing.ParseFilter(expr)
+		if err != nil {
+			return opts, err
+		}
+		opts.Filters = append(opts.Filters, predicate)
+	}
+	opts.Sort, _ = cmd.Flags().GetString("sort")
+	opts.Reverse, _ = cmd.Flags().GetBool("reverse")
+	opts.GroupBy, _ = cmd.Flags().GetString("group-by")
+	opts.Offset, _ = cmd.Flags().GetInt("offset")
+	opts.Limit, _ = cmd.Flags().GetInt("limit")
+	return opts, nil
+}
+
+// printListEntry 输出列表中的一条记录
+func printListEntry(v password.PasswordData, showActivity bool) {
+	if v.Platform == "" {
+		color.Blue.Printf("\n" + v.Key + " : ")
+		color.Green.Printf(displayValue(v) + "\n")
+	} else {
+		color.Blue.Printf("\n" + v.Key)
+		color.Cyan.Printf(" (" + v.Platform + ") : ")
+		color.Green.Printf(displayValue(v) + "\n")
+	}
+	if len(v.Envs) > 0 {
+		color.Gray.Println("  envs: " + strings.Join(envNames(v), ", "))
+	}
+	if showActivity {
+		printActivity(v)
+	}
+	//只提示已经到期的条目
+	printExpiryWarning(v, 0)
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
+	addFilterFlags(listCmd)
+	listCmd.Flags().Bool("tree", false, "show the entries as a folder tree without passwords")
+	listCmd.Flags().String("sort", listing.SortKey, "sort by "+strings.Join(listing.SortFields, ", "))
+	listCmd.Flags().Bool("reverse", false, "reverse the sort order")
+	listCmd.Flags().StringArray("filter", nil, "filter expression such as platform=github, key=aws_*, key~^db_ or tag!=old, can be repeated")
+	listCmd.Flags().Int("limit", 0, "show at most this many entries, 0 shows all")
+	listCmd.Flags().Int("offset", 0, "skip this many entries")
+	listCmd.Flags().String("group-by", "", "group the entries by "+strings.Join(listing.GroupFields, ", "))
+	listCmd.Flags().Bool("keys-only", false, "print only the keys, one per line, without decrypting any secret")
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	zaplog "password_manager/common/log"
+	"password_manager/service/agent"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Clear the secret key from pm agent",
+	Long: `Clear the secret key held by the running pm agent.
+
+Until 'pm unlock' is run, every command fails instead of reading passwords.
+
+Locking is a convenience for clearing the key from memory, not an access
+control: 'pm unlock' needs no credential because the agent loads the key again
+from the secret key file, which the current user can read anyway.
+
+Example:
+  pm lock`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		client := agent.NewClient(agent.DefaultSocketPath())
+		if err := client.Lock(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("pm agent locked")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// lockCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// lockCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
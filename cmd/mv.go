@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	zaplog "password_manager/common/log"
+	"password_manager/service/password"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// mvCmd represents the mv command
+var mvCmd = &cobra.Command{
+	Use:   "mv <key>... <folder>",
+	Short: "Move entries into a folder",
+	Long: `Move one or more entries into a folder. Folders are paths separated by '/',
+e.g. work/aws/prod, and do not have to be created first. Use / to move entries
+back to the top level. The key of the entry does not change.
+
+'pm list --tree' shows the folders as a tree, and --folder filters 'pm list',
+'pm search', 'pm audit' and 'pm env' to a folder and its subfolders.
+
+Example:
+  pm mv aws_root work/aws/prod
+  pm mv github gitlab work/code
+  pm mv github /`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		folder, err := password.NormalizeFolder(args[len(args)-1])
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		passwordInstance := vaultInstance.srv
+		target := folder
+		if target == "" {
+			target = password.FolderSeparator
+		}
+		for _, key := range args[:len(args)-1] {
+			if err := passwordInstance.MoveToFolder(key, folder); err != nil {
+				color.Red.Println(err)
+				return
+			}
+			color.Green.Println(key + " moved to " + target)
+		}
+		//备份
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// mvCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// mvCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
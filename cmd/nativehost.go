@@ -0,0 +1,193 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/nativehost"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// nativeHostCmd represents the native-host command
+var nativeHostCmd = &cobra.Command{
+	Use:   "native-host",
+	Short: "Native messaging host for the browser extension",
+	Long: `Run pm as a Chrome/Firefox native messaging host.
+
+The browser starts this command and exchanges length-prefixed JSON messages
+with it over stdin/stdout. The extension can ask which entries match the
+current page and, after you confirm it in a dialog, receive the username and
+password of one entry. Passwords are only released for entries whose URL or
+platform matches the page. A platform without a dot is only compared with the
+domain name in front of the public suffix, so github matches github.com but not
+github.evil.com.
+
+Only extensions on the allow-list can talk to the host. Register the host and
+add an extension to the allow-list with:
+
+  pm native-host install --browser chrome --extension-id <id>
+  pm native-host install --browser firefox --extension-id pm@example.com
+
+The confirmation dialog uses zenity or kdialog on Linux, osascript on macOS
+and PowerShell on Windows.
+
+Messages:
+  {"action":"ping"}
+  {"action":"match","url":"https://github.com/login"}
+  {"action":"get","url":"https://github.com/login","key":"github_john"}`,
+	// 浏览器会传入 chrome-extension://<id>/ 和 --parent-window 等参数
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, arg := range args {
+			if arg == "-h" || arg == "--help" {
+				cmd.Help()
+				return
+			}
+		}
+		//标准输出用于和浏览器通信，其他输出都写到标准错误
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		exeDir, err := executableDir()
+		if err != nil {
+			nativehost.WriteMessage(stdout, nativehost.Response{Error: err.Error()})
+			return
+		}
+		allowedOrigins, err := nativehost.LoadAllowList(filepath.Join(exeDir, nativehost.AllowListFileName))
+		if err != nil {
+			nativehost.WriteMessage(stdout, nativehost.Response{Error: err.Error()})
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			nativehost.WriteMessage(stdout, nativehost.Response{Error: err.Error()})
+			return
+		}
+		defer vaultInstance.kit.Close()
+		host := nativehost.NewHost(vaultInstance.srv, nativehost.Options{
+			Origin:         nativehost.CallerOrigin(args),
+			AllowedOrigins: allowedOrigins,
+			Confirm:        nativehost.DialogConfirm,
+		})
+		if err := host.Serve(os.Stdin, stdout); err != nil {
+			color.Red.Println(err)
+		}
+	},
+}
+
+// nativeHostInstallCmd represents the native-host install command
+var nativeHostInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Register the native messaging host with a browser",
+	Long: `Register the native messaging host with a browser and allow an extension to use it.
+
+This writes a small launcher next to the pm executable, adds the extension to
+the allow-list and installs the host manifest in the browser's directory.
+Use --print to only print the manifest, for example on Windows where it has
+to be registered in the registry.
+
+Example:
+  pm native-host install --browser chrome --extension-id abcdefghijklmnop
+  pm native-host install --browser firefox --extension-id pm@example.com
+  pm native-host install --browser chrome --extension-id abcdefghijklmnop --print`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		browser, _ := cmd.Flags().GetString("browser")
+		extensionID, _ := cmd.Flags().GetString("extension-id")
+		printOnly, _ := cmd.Flags().GetBool("print")
+
+		exePath, err := os.Executable()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		exeDir := filepath.Dir(exePath)
+		launcher := filepath.Join(exeDir, "pm-native-host")
+		if runtime.GOOS == "windows" {
+			launcher += ".bat"
+		}
+		manifest, err := nativehost.NewManifest(browser, extensionID, launcher)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//写入启动脚本并加入允许列表
+		if err := nativehost.WriteLauncher(launcher, exePath); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		origin, _ := nativehost.Origin(browser, extensionID)
+		if err := nativehost.AddAllowedOrigin(filepath.Join(exeDir, nativehost.AllowListFileName), origin); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if printOnly {
+			data, err := json.MarshalIndent(manifest, "", "  ")
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+		dir, err := nativehost.ManifestDir(browser)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		path, err := nativehost.WriteManifest(dir, manifest)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("native messaging host installed: " + path)
+	},
+}
+
+// executableDir 返回可执行文件所在的目录
+func executableDir() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(exePath), nil
+}
+
+func init() {
+	rootCmd.AddCommand(nativeHostCmd)
+	nativeHostCmd.AddCommand(nativeHostInstallCmd)
+	nativeHostInstallCmd.Flags().String("browser", nativehost.BrowserChrome, "browser to register with: chrome, chromium or firefox")
+	nativeHostInstallCmd.Flags().String("extension-id", "", "id of the extension that may use the host")
+	nativeHostInstallCmd.Flags().Bool("print", false, "print the manifest instead of installing it")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// nativeHostCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// nativeHostCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,280 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	zaplog "password_manager/common/log"
+	"password_manager/service/password"
+	"runtime"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// noteCmd represents the note command
+var noteCmd = &cobra.Command{
+	Use:   "note <key>",
+	Short: "Show, add or edit secure notes",
+	Long: `Secure notes are entries with multi-line content, for example recovery codes
+or the answers to security questions. They are encrypted like passwords.
+
+  pm note <key>          show a note
+  pm note add <key>      create a note
+  pm note edit <key>     edit a note in $EDITOR
+
+The content of a new note is read from --file, from stdin when it is not a
+terminal, or else from $VISUAL / $EDITOR. The temporary file used by the editor
+is created with 0600 permissions and removed afterwards.
+
+Example:
+  pm note add github_recovery --platform github < recovery-codes.txt
+  pm note github_recovery
+  pm note edit github_recovery`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		key := args[0]
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		passwordInstance := vaultInstance.srv
+		content, platform, err := passwordInstance.GetPasswordWithKey(key)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if isNote, err := passwordInstance.IsNote(key); err != nil || !isNote {
+			color.Red.Println("key:" + key + " is not a note")
+			return
+		}
+		//查看笔记时记录使用情况
+		passwordInstance.RecordAccess(key)
+		printNote(key, platform, content)
+	},
+}
+
+// noteAddCmd represents the note add command
+var noteAddCmd = &cobra.Command{
+	Use:   "add <key>",
+	Short: "Create a secure note",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		key := args[0]
+		platform, err := cmd.Flags().GetString("platform")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		content, err := readNoteContent(file, "")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if err := vaultInstance.srv.SaveNote(key, content, platform); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//备份
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("Note saved and backup successfully!")
+	},
+}
+
+// noteEditCmd represents the note edit command
+var noteEditCmd = &cobra.Command{
+	Use:   "edit <key>",
+	Short: "Edit a secure note in $EDITOR",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		key := args[0]
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		passwordInstance := vaultInstance.srv
+		oldContent, _, err := passwordInstance.GetPasswordWithKey(key)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if isNote, err := passwordInstance.IsNote(key); err != nil || !isNote {
+			color.Red.Println("key:" + key + " is not a note")
+			return
+		}
+		content, err := readNoteContent(file, oldContent)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if content == oldContent {
+			color.Yellow.Println("Note not changed")
+			return
+		}
+		if strings.TrimSpace(content) == "" {
+			color.Red.Println("note is empty")
+			return
+		}
+		if _, err := passwordInstance.UpdatePassword(key, content, "", ""); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("Note updated and backup successfully!")
+	},
+}
+
+// printNote 输出笔记的内容
+func printNote(key, platform, content string) {
+	fmt.Println()
+	if platform == "" {
+		color.Blue.Println(key + " :")
+	} else {
+		color.Blue.Println(key + "(" + platform + ") :")
+	}
+	fmt.Print(content)
+	if !strings.HasSuffix(content, "\n") {
+		fmt.Println()
+	}
+	fmt.Println()
+}
+
+// noteSummary 列表中代替笔记内容显示的摘要
+func noteSummary(content string) string {
+	lines := strings.Count(strings.TrimRight(content, "\n"), "\n") + 1
+	if lines == 1 {
+		return "[note, 1 line]"
+	}
+	return fmt.Sprintf("[note, %d lines]", lines)
+}
+
+// displayValue 列表中显示的值，笔记只显示摘要
+func displayValue(data password.PasswordData) string {
+	if data.Type == password.TypeNote {
+		return noteSummary(data.Password)
+	}
+	return data.Password
+}
+
+// readNoteContent 从文件、标准输入或编辑器读取笔记内容，initial 是编辑器中的初始内容
+func readNoteContent(file, initial string) (string, error) {
+	if file != "" {
+		content, err := os.ReadFile(file)
+		return string(content), err
+	}
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice == 0 {
+		content, err := io.ReadAll(os.Stdin)
+		return string(content), err
+	}
+	return editInEditor(initial)
+}
+
+// editInEditor 在 $VISUAL 或 $EDITOR 中编辑内容，临时文件只有当前用户可以读写，编辑后删除
+func editInEditor(initial string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	tmp, err := os.CreateTemp("", "pm-note-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil && runtime.GOOS != "windows" {
+		tmp.Close()
+		return "", err
+	}
+	if _, err := tmp.WriteString(initial); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	//编辑器可以带参数，例如 "code --wait"
+	fields := strings.Fields(editor)
+	command := exec.Command(fields[0], append(fields[1:], tmp.Name())...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return "", errors.New("editor " + editor + " failed: " + err.Error())
+	}
+	content, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.AddCommand(noteAddCmd)
+	noteCmd.AddCommand(noteEditCmd)
+	noteAddCmd.Flags().String("platform", "", "platform of the note")
+	noteAddCmd.Flags().StringP("file", "f", "", "read the content from this file")
+	noteEditCmd.Flags().StringP("file", "f", "", "replace the content with this file instead of opening an editor")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// noteCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// noteCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -6,12 +6,10 @@ package cmd
 import (
 	"fmt"
 	zaplog "password_manager/common/log"
-	"password_manager/service/aes"
-	dbfilekit "password_manager/service/dbfile_Kit"
 	"password_manager/service/input"
 	"password_manager/service/password"
-	secretkey "password_manager/service/secret_key"
-	"strings"
+	"password_manager/service/search"
+	"sort"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -29,6 +27,8 @@ provided as an argument, you will be prompted to enter one.
 
 Fuzzy search allows you to find results even if the platform name is partially matched
 or contains minor typos. For example, searching for "Gith" may return results for "GitHub".
+Chinese platform names can also be searched by full pinyin or initials, e.g. "weixin"
+or "wx" for "微信" and "zfb" for "支付宝".
 
 Examples:
   - List passwords for a specific platform:
@@ -58,53 +58,49 @@ match the search term. If no passwords are found, a message will be shown.`,
 				return
 			}
 		}
-		//初始化密钥模块
-		secretKeyInstance := secretkey.NewSecretKey()
-
-		//初始化数据库模块
-		kitInstance := dbfilekit.NewDBKit(secretKeyInstance)
-		if err := kitInstance.Init(); err != nil {
-			color.Red.Println(err)
-			return
-		}
-
-		//获取数据库
-		db, err := kitInstance.GetDB()
+		//打开数据库
+		vaultInstance, err := openVault()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取密钥
-		secretKey, err := secretKeyInstance.GetSecretKey()
+		passwordInstance := vaultInstance.srv
+		//只在平台信息中进行模糊搜索，按得分排序，只解密匹配的条目
+		cursor, err := passwordInstance.NewCursor()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		//初始化加密模块
-		aesInstance := aes.NewAesService(secretKey)
-		//初始化密码保存模块
-		passwordInstance := password.NewPasswordService(aesInstance, db)
-		results, err := passwordInstance.GetAllPasswords()
-		if err != nil {
+		defer cursor.Close()
+		var matches []password.PasswordData
+		scores := make(map[string]int)
+		for cursor.Next() {
+			entry := cursor.Entry()
+			score := search.Score(platform, entry.Platform)
+			if score == 0 {
+				continue
+			}
+			data, err := cursor.Decrypt()
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			scores[data.Key] = score
+			matches = append(matches, data)
+		}
+		if err := cursor.Err(); err != nil {
 			color.Red.Println(err)
 			return
 		}
-		cnt := 0
-		for k, v := range results {
-			if !strings.Contains(v.Platform, platform) {
-				continue
+		sort.Slice(matches, func(i, j int) bool {
+			if scores[matches[i].Key] != scores[matches[j].Key] {
+				return scores[matches[i].Key] > scores[matches[j].Key]
 			}
-			cnt++
-			if v.Platform == "" {
-				color.Blue.Printf("\n" + k + " : ")
-				color.Green.Printf(v.Password + "\n")
-			} else {
-				color.Blue.Printf("\n" + k)
-				color.Cyan.Printf(" (" + v.Platform + ") : ")
-				color.Green.Printf(v.Password + "\n")
-			}
-
+			return matches[i].Key < matches[j].Key
+		})
+		cnt := len(matches)
+		for _, v := range matches {
+			printPasswordData(v)
 		}
 		fmt.Println()
 		if cnt == 0 {
@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	zaplog "password_manager/common/log"
+	"password_manager/service/strength"
+	"strconv"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Show or change the password strength policy",
+	Long: `Show or change the minimum password strength required for new passwords.
+
+Passwords are scored from 0 to 4 by a zxcvbn style estimator that looks for
+common passwords, dictionary words, names, keyboard patterns, sequences,
+repeats and dates:
+
+  0 very weak, 1 weak, 2 fair, 3 strong, 4 very strong
+
+The policy applies wherever a password is saved: 'pm add', 'pm update', the
+API and web UI, 'pm ui', 'pm git-credential' and 'pm docker-credential'.
+Passwords below the minimum score are rejected unless --force is passed (the
+API accepts "force": true and answers 422 otherwise); 'pm ui' and the
+credential helpers have no way to force, lower the policy to save such a
+password there. The policy is off by default (minimum score 0, every password
+is accepted), 2 rejects weak and very weak passwords. The policy is stored in
+the database, so it is included in backups.
+
+Example:
+  pm policy
+  pm policy --min-score 3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		db, err := vaultInstance.kit.GetDB()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		policyInstance := strength.NewPolicyService(db)
+		if cmd.Flags().Changed("min-score") {
+			minScore, _ := cmd.Flags().GetInt("min-score")
+			if err := policyInstance.SetPolicy(strength.Policy{MinScore: minScore}); err != nil {
+				color.Red.Println(err)
+				return
+			}
+			if err := vaultInstance.kit.BackupDB(); err != nil {
+				color.Red.Println(err)
+				return
+			}
+		}
+		policy, err := policyInstance.GetPolicy()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("minimum password score: " + strconv.Itoa(policy.MinScore) + " (" + strength.Label(policy.MinScore) + ")")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.Flags().Int("min-score", strength.DefaultMinScore, "minimum score from 0 to 4 required for new passwords")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// policyCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// policyCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"password_manager/service/password"
+	"password_manager/service/search"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+// printPasswordData 以统一的格式输出一条密码记录
+func printPasswordData(data password.PasswordData) {
+	if data.Platform == "" {
+		color.Blue.Print("\n" + data.Key + " : ")
+	} else {
+		color.Blue.Print("\n" + data.Key)
+		color.Cyan.Print(" (" + data.Platform + ") : ")
+	}
+	color.Green.Print(displayValue(data) + "\n")
+	var extra []string
+	if data.Username != "" {
+		extra = append(extra, "user: "+data.Username)
+	}
+	if data.URL != "" {
+		extra = append(extra, "url: "+data.URL)
+	}
+	if len(data.Tags) > 0 {
+		extra = append(extra, "tags: "+strings.Join(data.Tags, ","))
+	}
+	if data.Folder != "" {
+		extra = append(extra, "folder: "+data.Folder)
+	}
+	if len(data.Envs) > 0 {
+		extra = append(extra, "envs: "+strings.Join(envNames(data), ","))
+	}
+	if data.ExpiresAt != "" {
+		extra = append(extra, "expires: "+data.ExpiresAt)
+	}
+	if data.RotationDays > 0 {
+		extra = append(extra, "rotate every: "+strconv.Itoa(data.RotationDays)+"d")
+	}
+	if len(extra) > 0 {
+		color.Gray.Println("  " + strings.Join(extra, "  "))
+	}
+	printExpiryWarning(data, 0)
+}
+
+// envNames 返回条目设置了密码的环境，按名称排序
+func envNames(data password.PasswordData) []string {
+	names := make([]string, 0, len(data.Envs))
+	for name := range data.Envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toSearchEntries 将密码记录转换为搜索条目
+func toSearchEntries(results map[string]password.PasswordData) []search.Entry {
+	entries := make([]search.Entry, 0, len(results))
+	for _, v := range results {
+		entries = append(entries, search.Entry{
+			Key:      v.Key,
+			Platform: v.Platform,
+			Username: v.Username,
+			URL:      v.URL,
+			Tags:     v.Tags,
+		})
+	}
+	return entries
+}
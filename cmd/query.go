@@ -6,11 +6,10 @@ package cmd
 import (
 	"fmt"
 	zaplog "password_manager/common/log"
-	"password_manager/service/aes"
-	dbfilekit "password_manager/service/dbfile_Kit"
 	"password_manager/service/input"
 	"password_manager/service/password"
-	secretkey "password_manager/service/secret_key"
+	"password_manager/service/search"
+	"strings"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -35,7 +34,22 @@ For example:
 
 If the key exists, the corresponding password and platform (if available) will be decrypted
 and displayed in the following format:
-  github_john.doe (GitHub) : my-secure-password123`,
+  github_john.doe (GitHub) : my-secure-password123
+
+Use --env to show the value of an environment. Entries without environment
+values return their default password for every environment:
+
+  pm query prod_db --env prod
+
+Typed entries (see 'pm add --type') are shown field by field:
+
+  visa(bank) [Credit card]
+    Cardholder: John Doe
+    Number: 4111 1111 1111 1111
+    Expiry (MM/YY): 12/29
+
+A warning is shown above the password when it has expired or is due for
+rotation within 14 days (see 'pm due').`,
 	Run: func(cmd *cobra.Command, args []string) {
 		//初始化日志模块
 		if err := zaplog.LoggerInit(); err != nil {
@@ -58,50 +72,102 @@ and displayed in the following format:
 			color.Red.Println("Key or account cannot be empty")
 			return
 		}
-		//初始化密钥模块
-		secretKeyInstance := secretkey.NewSecretKey()
-
-		//初始化数据库模块
-		kitInstance := dbfilekit.NewDBKit(secretKeyInstance)
-		if err := kitInstance.Init(); err != nil {
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取数据库
-		db, err := kitInstance.GetDB()
+		//打开数据库
+		vaultInstance, err := openVault()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取密钥
-		secretKey, err := secretKeyInstance.GetSecretKey()
+		passwordInstance := vaultInstance.srv
+		//查看密码时记录使用情况
+		passwordValue, platform, err := passwordInstance.GetPasswordTracked(key)
+		if err != nil {
+			color.Red.Println(err)
+			//找不到key时给出相近的key
+			keys, keysErr := passwordInstance.GetAllKeys()
+			if keysErr != nil {
+				return
+			}
+			if suggestions := search.Suggest(key, keys, 3); len(suggestions) > 0 {
+				color.Yellow.Println("Did you mean: " + strings.Join(suggestions, ", ") + " ?")
+			}
+			return
+		}
+		meta, err := passwordInstance.GetMeta(key)
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-		//初始化加密模块
-		aesInstance := aes.NewAesService(secretKey)
-		//初始化密码保存模块
-		passwordInstance := password.NewPasswordService(aesInstance, db)
-		password, platform, err := passwordInstance.GetPasswordWithKey(key)
+		//密码到期或者即将到期时先给出提示
+		modified, err := passwordInstance.GetModified(key)
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
+		printExpiryWarning(password.PasswordData{ExpiresAt: meta.ExpiresAt, RotationDays: meta.RotationDays, Modified: modified}, dueSoonWindow)
+		//创建、修改和使用情况，在密码之后输出
+		created, err := passwordInstance.GetCreated(key)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		access, err := passwordInstance.GetAccess(key)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		activity := password.PasswordData{Created: created, Modified: modified, LastUsed: access.LastUsed, UseCount: access.Count}
+		kind, err := passwordInstance.GetKind(key)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		isNote := kind == password.TypeNote
+		//带类型的条目按字段输出
+		if env == "" && kind != "" && kind != password.TypeLogin && !isNote {
+			record, err := passwordInstance.GetRecord(key)
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			printRecord(key, platform, record)
+			printActivity(activity)
+			return
+		}
+		//选择环境的密码
+		if env != "" {
+			passwordValue, _, err = passwordInstance.GetPasswordForEnv(key, env)
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			key += " [" + env + "]"
+		}
+		//安全笔记按多行输出
+		if isNote {
+			printNote(key, platform, passwordValue)
+			printActivity(activity)
+			return
+		}
 		fmt.Println()
 		if platform == "" {
-			color.Blue.Println(key + " : " + password)
+			color.Blue.Println(key + " : " + passwordValue)
 		} else {
-			color.Blue.Printf("\n" + key + "(" + platform + ")" + " : " + password + "\n")
+			color.Blue.Printf("\n" + key + "(" + platform + ")" + " : " + passwordValue + "\n")
 		}
+		printActivity(activity)
 		fmt.Println()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(queryCmd)
+	queryCmd.Flags().String("env", "", "show the value of this environment, e.g. dev, staging, prod")
 
 	// Here you will define your flags and configuration settings.
 
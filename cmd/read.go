@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/secretref"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// readCmd represents the read command
+var readCmd = &cobra.Command{
+	Use:   "read <pm://[vault/]key[/field]>",
+	Short: "Print the value of a secret reference",
+	Long: `Print the value of a single secret reference to stdout.
+
+A secret reference has the form pm://[vault/]key[/field]:
+  vault   optional, the only vault is 'default'
+  key     the key of the entry, a '/' inside the key is written as %2F
+  field   password (default), username, url, platform or tags
+
+When the reference has two parts, the second part is a field if it is one of
+the field names above and a key otherwise (pm://default/prod_db). Use all
+three parts to refer to a key named like a field.
+
+Only the value is written to stdout, so it can be used in scripts. Use --env to
+read the password of an environment (see 'pm add --env'). A field that is not
+set is an error, it is never printed as an empty value.
+
+Example:
+  pm read pm://prod_db
+  pm read pm://default/prod_db/username
+  pm read pm://db --env prod
+  export DB_PASS="$(pm read pm://prod_db/password)"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//标准输出只输出引用的值
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		noNewline, err := cmd.Flags().GetBool("no-newline")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		ref, err := secretref.Parse(args[0])
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		value, err := secretref.NewResolver(vaultInstance.srv).WithEnv(env).ResolveRef(ref)
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		if noNewline {
+			fmt.Fprint(stdout, value)
+			return
+		}
+		fmt.Fprintln(stdout, value)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(readCmd)
+	readCmd.Flags().BoolP("no-newline", "n", false, "do not print a trailing newline")
+	readCmd.Flags().String("env", "", "read the password of this environment")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// readCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// readCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
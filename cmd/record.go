@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"password_manager/service/input"
+	"password_manager/service/password"
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+// parseFieldFlags 解析 --field name=value，值以 @ 开头时读取文件内容
+func parseFieldFlags(values []string) (map[string]string, error) {
+	fields := make(map[string]string, len(values))
+	for _, value := range values {
+		name, fieldValue, ok := strings.Cut(value, "=")
+		if !ok || name == "" {
+			return nil, errors.New("invalid field " + value + ", expected name=value")
+		}
+		if strings.HasPrefix(fieldValue, "@") {
+			content, err := os.ReadFile(fieldValue[1:])
+			if err != nil {
+				return nil, err
+			}
+			fieldValue = string(content)
+		}
+		fields[name] = fieldValue
+	}
+	return fields, nil
+}
+
+// promptRecordFields 按照类型的字段定义依次输入，已经通过参数给出的字段不再询问
+func promptRecordFields(kind password.Kind, fields map[string]string) error {
+	for _, field := range kind.Fields {
+		if _, ok := fields[field.Name]; ok {
+			continue
+		}
+		label := "Enter " + strings.ToLower(field.Label[:1]) + field.Label[1:]
+		if !field.Required {
+			label += " (optional, press Enter to skip)"
+		}
+		var value string
+		var err error
+		switch {
+		case field.Multiline:
+			//多行内容从文件读取
+			var path string
+			path, err = input.GetOptionalInput("Enter path of the file containing the " + strings.ToLower(field.Label))
+			if err == nil && path != "" {
+				var content []byte
+				content, err = os.ReadFile(path)
+				value = string(content)
+			}
+		case field.Secret && field.Required:
+			value, err = input.GetPasswordInput(label)
+		case field.Secret:
+			value, err = input.GetOptionalPassword(label)
+		case field.Required:
+			value, err = input.GetInput(label)
+		default:
+			value, err = input.GetOptionalInput(label)
+		}
+		if err != nil {
+			return err
+		}
+		fields[field.Name] = value
+	}
+	return nil
+}
+
+// printRecord 按照类型的字段顺序输出条目，多行的值缩进输出
+func printRecord(key, platform string, record password.Record) {
+	kind, err := password.LookupKind(record.Type)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	fmt.Println()
+	if platform == "" {
+		color.Blue.Print(key)
+	} else {
+		color.Blue.Print(key + "(" + platform + ")")
+	}
+	color.Cyan.Println(" [" + kind.Label + "]")
+	for _, field := range kind.Fields {
+		value, ok := record.Fields[field.Name]
+		if !ok {
+			continue
+		}
+		if field.Multiline {
+			color.Gray.Println("  " + field.Label + ":")
+			for _, line := range strings.Split(strings.TrimRight(value, "\n"), "\n") {
+				fmt.Println("    " + line)
+			}
+			continue
+		}
+		color.Gray.Print("  " + field.Label + ": ")
+		fmt.Println(value)
+	}
+	fmt.Println()
+}
@@ -5,6 +5,8 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -24,6 +26,29 @@ This tool allows you to:
   - Restore credentials from a backup file.
   - Automatically backup all credentials every 500 seconds while the program is running.
   - List passwords associated with a specific platform.
+  - Fuzzy search passwords by key, platform, username, URL or tag.
+  - Browse and edit passwords in a full-screen terminal UI.
+  - Unlock once and run several commands in an interactive shell.
+  - Keep the secret key in a background agent and lock or unlock it on demand.
+  - Serve a local JSON API for other tools.
+  - Manage passwords in the browser with a small web UI.
+  - Fill passwords from a browser extension through a native messaging host.
+  - Act as a git credential helper.
+  - Act as a Docker credential helper.
+  - Run a command with secrets injected into its environment.
+  - Read secret references and render templates containing them.
+  - Import .env files into a group and load a group into the shell.
+  - Keep separate values per environment (dev/staging/prod) in one entry.
+  - Attach encrypted files to entries and keep multi-line secure notes.
+  - Store typed entries such as cards, SSH keys and databases with validated fields.
+  - Serve SSH keys stored in pm through a built-in SSH agent.
+  - Estimate password strength and reject weak passwords by policy.
+  - Audit weak, reused, similar and old passwords.
+  - Check passwords against an offline breached password corpus.
+  - Set expiry dates or rotation intervals and list passwords due for rotation.
+  - Organize entries with tags and folders, and show them as a tree.
+  - Sort, filter, group and page the list, or print only the keys.
+  - Track when entries were created, changed and last used, with usage statistics.
 
 All data is encrypted using AES encryption, ensuring your passwords are safe and protected.
 
@@ -36,6 +61,39 @@ Examples:
   - Backup all passwords:    pm backup
   - Restore from backup:     pm restore
   - List passwords by platform: pm pla
+  - Fuzzy search passwords:  pm search
+  - Open the terminal UI:    pm ui
+  - Start an interactive shell: pm shell
+  - Start the key agent:     pm agent
+  - Lock or unlock the agent: pm lock / pm unlock
+  - Serve the local JSON API: pm serve
+  - Open the web UI:         pm web
+  - Register the browser host: pm native-host install
+  - Use pm as git credential helper: git config --global credential.helper '!pm git-credential'
+  - Use pm as Docker credential helper: link docker-credential-pm to pm, set "credsStore": "pm"
+  - Run a command with secrets: pm run --env DB_PASS=pm://prod_db/password -- ./server
+  - Read a secret reference:  pm read pm://prod_db/password
+  - Render a template:       pm inject -i config.tpl -o config.yaml
+  - Import a .env file:      pm import --format dotenv --group myapp .env
+  - Load a group into bash:  eval "$(pm env myapp)"
+  - Add a value for prod:    pm add db --env prod
+  - Attach a file:           pm attach prod_cluster ~/.kube/config
+  - Extract an attachment:   pm extract prod_cluster config -o ./kubeconfig
+  - Add a secure note:       pm note add github_recovery < codes.txt
+  - Add a credit card:       pm add visa --type card
+  - Import an SSH key:       pm ssh-add ~/.ssh/id_ed25519
+  - Start the SSH agent:     pm ssh-agent
+  - Require strong passwords: pm policy --min-score 3
+  - Audit all passwords:     pm audit --max-age 90d
+  - Check for breached passwords: pm breach-check --corpus ~/pwned/ranges
+  - Rotate every 90 days:    pm update aws_root --expires 90d
+  - List passwords to rotate: pm due --within 14d
+  - Tag an entry:            pm tag add aws_root work cloud
+  - Move into a folder:      pm mv aws_root work/aws/prod
+  - Show the folder tree:    pm list --tree
+  - Filter and page the list: pm list --filter platform=aws --sort updated --limit 20
+  - Show usage statistics:   pm stats
+  - List most used passwords: pm list --sort uses --limit 10
 
 Automatic Backup:
   - While the program is running, a backup of all credentials will be created every 500 seconds.
@@ -47,12 +105,28 @@ For more information on a specific command, use 'pm [command] --help'.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	//通过 docker-credential-pm 或 git-credential-pm 链接启动时直接执行对应的子命令
+	if command, ok := helperCommands[helperName(os.Args[0])]; ok {
+		rootCmd.SetArgs(append([]string{command}, os.Args[1:]...))
+	}
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// helperCommands 可执行文件名与子命令的对应关系
+var helperCommands = map[string]string{
+	"docker-credential-pm": "docker-credential",
+	"git-credential-pm":    "git-credential",
+}
+
+// helperName 返回不带扩展名的可执行文件名
+func helperName(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
 func init() {
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
@@ -0,0 +1,185 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/runner"
+	"password_manager/service/secretref"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run [--env NAME=pm://[vault/]key[/field]]... -- <command> [args...]",
+	Short: "Run a command with secrets from pm in its environment",
+	Long: `Start a command with secret references resolved into its environment.
+
+A secret reference has the form pm://[vault/]key[/field], see 'pm read --help'.
+
+Variables are set with --env NAME=VALUE. A --env value without '=' selects the
+environment of the referenced passwords instead, for example --env prod (see
+'pm add --env'). Variables inherited from the current
+environment whose value is a secret reference are resolved as well, so a
+committed .env file can hold references instead of values.
+
+The database is closed before the command starts. Signals such as Ctrl-C and
+SIGTERM are forwarded to the command, and pm exits with the exit code of the
+command. Resolved passwords are replaced by ***** in the stdout and stderr of
+the command; use --no-mask to connect the command to the terminal directly.
+
+Example:
+  pm run --env DB_PASS=pm://prod_db/password -- ./server
+  pm run -e DB_USER=pm://prod_db/username -e DB_PASS=pm://prod_db -- psql
+  DB_PASS=pm://prod_db pm run -- ./server
+  pm run --env prod -e DB_PASS=pm://db -- ./server`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//解析引用时的输出不能混入子进程的标准输出
+		_, restore := useStdoutForProtocol()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			restore()
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		envFlags, err := cmd.Flags().GetStringArray("env")
+		if err != nil {
+			restore()
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		noMask, err := cmd.Flags().GetBool("no-mask")
+		if err != nil {
+			restore()
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		var variables []string
+		var scope string
+		for _, value := range envFlags {
+			//不带 = 的值选择环境
+			if strings.Contains(value, "=") {
+				variables = append(variables, value)
+				continue
+			}
+			if scope != "" && scope != value {
+				restore()
+				color.Red.Println("only one environment can be selected, got " + scope + " and " + value)
+				os.Exit(1)
+			}
+			scope = value
+		}
+		env, secrets, err := resolveRunEnv(os.Environ(), variables, scope)
+		restore()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(1)
+		}
+		code, err := runner.Run(runner.Options{
+			Command: args,
+			Env:     env,
+			Secrets: secrets,
+			NoMask:  noMask,
+			Stdin:   os.Stdin,
+			Stdout:  os.Stdout,
+			Stderr:  os.Stderr,
+		})
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(127)
+		}
+		os.Exit(code)
+	},
+}
+
+// resolveRunEnv 合并继承的环境变量和 --env 参数，并解析其中的密码引用
+// scope 不为空时解析该环境的密码，返回子进程的环境变量和需要屏蔽的密码，只有存在引用时才打开数据库
+func resolveRunEnv(environ []string, envFlags []string, scope string) ([]string, []string, error) {
+	var names []string
+	values := make(map[string]string)
+	set := func(entry string) error {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return errors.New("invalid environment variable " + entry + ", expected NAME=VALUE")
+		}
+		if _, exists := values[name]; !exists {
+			names = append(names, name)
+		}
+		values[name] = value
+		return nil
+	}
+	for _, entry := range environ {
+		//Windows 的环境变量中可能有 =C:=C:\ 这样的条目，原样跳过
+		if strings.HasPrefix(entry, "=") {
+			continue
+		}
+		if err := set(entry); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, entry := range envFlags {
+		if err := set(entry); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var secrets []string
+	var resolver *secretref.Resolver
+	for _, name := range names {
+		if !secretref.IsRef(values[name]) {
+			continue
+		}
+		if resolver == nil {
+			vaultInstance, err := openVault()
+			if err != nil {
+				return nil, nil, err
+			}
+			defer vaultInstance.kit.Close()
+			resolver = secretref.NewResolver(vaultInstance.srv).WithEnv(scope)
+		}
+		ref, err := secretref.Parse(values[name])
+		if err != nil {
+			return nil, nil, errors.New(name + ": " + err.Error())
+		}
+		value, err := resolver.ResolveRef(ref)
+		if err != nil {
+			return nil, nil, errors.New(name + ": " + err.Error())
+		}
+		values[name] = value
+		//用户名、URL 等不是秘密，只屏蔽密码
+		if ref.Field == secretref.FieldPassword {
+			secrets = append(secrets, value)
+		}
+	}
+
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, name+"="+values[name])
+	}
+	return env, secrets, nil
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringArrayP("env", "e", nil, "set an environment variable, NAME=VALUE or NAME=pm://[vault/]key[/field] (repeatable); a value without '=' selects the environment of the secrets")
+	runCmd.Flags().Bool("no-mask", false, "do not mask secret values in the output of the command")
+	//命令之后的参数都交给子进程
+	runCmd.Flags().SetInterspersed(false)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// runCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// runCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
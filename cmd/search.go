@@ -0,0 +1,168 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	zaplog "password_manager/common/log"
+	"password_manager/service/input"
+	"password_manager/service/password"
+	"password_manager/service/search"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Fuzzy search stored passwords by key, platform, username, URL or tag",
+	Long: `Fuzzy search stored passwords by key, platform, username, URL or tag.
+
+The search is case-insensitive and ignores accents and full-width characters.
+It accepts prefixes, substrings, abbreviations (e.g. "ghb" for "GitHub") and
+small typos (e.g. "githb" for "GitHub"). When several words are given, every
+word has to match. Results are ranked by how well they match.
+
+Chinese text can be searched by full pinyin or initials, e.g. "weixin" or "wx"
+for "微信" and "zfb" for "支付宝". Polyphonic characters match any of their
+readings, so "yinhang" and "xingzou" match "银行" and "行走".
+
+Examples:
+  - Search by any field:
+    pm search github
+
+  - Enter the query interactively:
+    pm search
+    Enter search query: gith
+
+  - Several words must all match:
+    pm search john work
+
+  - Only expired entries, or entries due within 30 days (see 'pm due'):
+    pm search --expired
+    pm search aws --due 30d
+
+  - Only entries with a tag or inside a folder (see 'pm tag' and 'pm mv'):
+    pm search --tag work
+    pm search aws --folder work/cloud`,
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		expired, _ := cmd.Flags().GetBool("expired")
+		dueValue, _ := cmd.Flags().GetString("due")
+		filterDue := expired || dueValue != ""
+		var within time.Duration
+		if dueValue != "" {
+			var err error
+			if within, err = parseDays(dueValue); err != nil {
+				color.Red.Println(err)
+				return
+			}
+		}
+		var query string
+		if len(args) > 0 {
+			query = strings.Join(args, " ")
+		} else if !filterDue && filter.Empty() {
+			query, err = input.GetInput("Enter search query")
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		passwordInstance := vaultInstance.srv
+		//搜索只使用不需要解密的信息，只解密要输出的条目
+		entries, err := passwordInstance.GetAllEntries()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		results := make(map[string]password.PasswordData, len(entries))
+		for _, v := range entries {
+			results[v.Key] = v
+		}
+		results = filter.Apply(results)
+		var keys []string
+		notFound := "No password found for " + query
+		switch {
+		case filterDue && strings.TrimSpace(query) == "":
+			//没有查询词时按轮换时间输出所有到期的条目
+			for _, entry := range password.DueEntries(results, within, time.Now()) {
+				keys = append(keys, entry.Key)
+			}
+			notFound = "No password is due"
+		case strings.TrimSpace(query) == "":
+			//没有查询词时按 key 输出筛选出的所有条目
+			for key := range results {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			notFound = "No password found"
+		default:
+			//只保留到期和即将到期的条目
+			if filterDue {
+				due := password.DueEntries(results, within, time.Now())
+				filtered := make(map[string]password.PasswordData, len(due))
+				for _, entry := range due {
+					filtered[entry.Key] = results[entry.Key]
+				}
+				results = filtered
+			}
+			for _, match := range search.Search(query, toSearchEntries(results)) {
+				keys = append(keys, match.Entry.Key)
+			}
+		}
+		matches, err := passwordInstance.GetPasswordsWithKeys(keys...)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		for _, data := range matches {
+			printPasswordData(data)
+		}
+		fmt.Println()
+		if len(matches) == 0 {
+			if filterDue && strings.TrimSpace(query) == "" {
+				color.Green.Println(notFound)
+			} else {
+				color.Red.Println(notFound)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	addFilterFlags(searchCmd)
+	searchCmd.Flags().Bool("expired", false, "only show entries whose password has expired")
+	searchCmd.Flags().String("due", "", "only show entries expired or due within this time, e.g. 30d")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// searchCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// searchCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	zaplog "password_manager/common/log"
+	"password_manager/service/agent"
+	"password_manager/service/api"
+	"syscall"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultServeAddr = "127.0.0.1:7878"
+	// apiTokenEnv 指定 API 访问令牌的环境变量
+	apiTokenEnv = "PM_API_TOKEN"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local JSON API for integrations",
+	Long: `Serve a versioned JSON API so that other tools can read and change passwords
+without parsing the output of 'pm query'.
+
+The API listens on localhost or on a Unix socket that only the current user can
+access. Every request must carry the access token:
+
+  Authorization: Bearer <token>
+
+The token is read from --token or the PM_API_TOKEN environment variable. If
+neither is set, a random token is generated and printed at start.
+
+Endpoints:
+  GET    /v1/passwords           list entries (without passwords)
+  POST   /v1/passwords           create an entry
+  GET    /v1/passwords/{key}     get an entry with its password
+  PATCH  /v1/passwords/{key}     update an entry
+  DELETE /v1/passwords/{key}     delete an entry
+  GET    /v1/search?q=...        fuzzy search
+  POST   /v1/backup              back up the database
+  GET    /v1/openapi.json        OpenAPI description (no token needed)
+
+Example:
+  pm serve
+  pm serve --addr 127.0.0.1:9000
+  pm serve --socket /run/user/1000/pm.sock
+  curl -H "Authorization: Bearer $PM_API_TOKEN" http://127.0.0.1:7878/v1/passwords`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		addr, _ := cmd.Flags().GetString("addr")
+		socketPath, _ := cmd.Flags().GetString("socket")
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = os.Getenv(apiTokenEnv)
+		}
+		generated := false
+		if token == "" {
+			var err error
+			token, err = api.GenerateToken()
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			generated = true
+		}
+
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+
+		//创建监听
+		var listener net.Listener
+		if socketPath != "" {
+			listener, err = agent.Listen(socketPath)
+		} else {
+			listener, err = listenLoopback(addr)
+		}
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+
+		server := &http.Server{
+			Handler:           api.NewServer(vaultInstance.srv, vaultInstance.kit, token),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		//收到退出信号后关闭服务
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			<-signals
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(ctx)
+		}()
+
+		if socketPath != "" {
+			color.Green.Println("pm API listening on unix:" + socketPath)
+		} else {
+			color.Green.Println("pm API listening on http://" + listener.Addr().String())
+		}
+		if generated {
+			color.Yellow.Println("Access token: " + token)
+		}
+		color.Gray.Println("Press Ctrl-C to stop")
+
+		err = server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			color.Red.Println(err)
+			return
+		}
+		//备份
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Yellow.Println("pm API stopped")
+	},
+}
+
+// listenLoopback 只允许监听本机地址
+func listenLoopback(addr string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, errors.New("refusing to listen on non-loopback address " + addr)
+		}
+	}
+	return net.Listen("tcp", addr)
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("addr", defaultServeAddr, "loopback address to listen on")
+	serveCmd.Flags().String("socket", "", "listen on this Unix socket instead of TCP")
+	serveCmd.Flags().String("token", "", "access token (default $"+apiTokenEnv+" or a random token)")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// serveCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// serveCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
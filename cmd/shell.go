@@ -0,0 +1,313 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	zaplog "password_manager/common/log"
+	"password_manager/service/input"
+	"password_manager/service/shell"
+	"sync"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	shellPrompt          = "pm> "
+	defaultShellIdleTime = 10 * time.Minute
+)
+
+// shellCommands 可以在交互式会话中执行的命令
+var shellCommands = []string{"add", "query", "list", "del", "update", "search", "pla"}
+
+// shellKeyCommands 第一个参数是key的命令，用于补全
+var shellKeyCommands = []string{"query", "del", "update"}
+
+// shellBuiltins 交互式会话的内置命令
+var shellBuiltins = []string{"help", "lock", "unlock", "exit", "quit"}
+
+// shellCmd represents the shell command
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Unlock once and run commands in an interactive session",
+	Long: `Unlock the password database once and run commands in an interactive session.
+
+Every 'pm' command normally loads the secret key and opens the database on its
+own. Inside the shell this happens only once, and the following commands can be
+run without the 'pm' prefix:
+
+  add, query, list, del, update, search, pla
+
+The shell also understands:
+  help      show the available commands
+  lock      lock the session now
+  unlock    unlock a locked session
+  exit      leave the shell (also: quit, Ctrl-D)
+
+Commands and keys can be completed with Tab, and the Up/Down arrows walk through
+the history of the current session. The history is kept in memory only and
+commands run with the terminal in its normal mode, so prompts such as the
+password input of 'add' work as usual.
+
+The session locks itself after a period of inactivity and releases the
+database and the key until it is unlocked again. If a master password is set
+(see 'pm web --set-master-password'), 'unlock' asks for it and the session
+stays locked when it is wrong, the same as 'pm ui'.
+
+Example:
+  pm shell
+  pm> query gith<Tab>
+  pm> search wx
+  pm> exit`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		idleTime, err := cmd.Flags().GetDuration("lock-after")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if err := unlockShellVault(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		var mu sync.Mutex
+		locker := shell.NewLocker(idleTime, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			lockShellVault()
+		})
+		defer func() {
+			locker.Stop()
+			mu.Lock()
+			defer mu.Unlock()
+			lockShellVault()
+		}()
+
+		completer := shell.NewCompleter(append(append([]string(nil), shellCommands...), shellBuiltins...), shellKeyCommands, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			if sharedVault == nil {
+				return nil
+			}
+			keys, err := sharedVault.srv.GetAllKeys()
+			if err != nil {
+				return nil
+			}
+			return keys
+		})
+
+		//保存终端原来的模式，执行命令时恢复，保证命令中的交互输入正常工作
+		originMode, originErr := liner.TerminalMode()
+		state := liner.NewLiner()
+		defer state.Close()
+		linerMode, linerErr := liner.TerminalMode()
+		state.SetCtrlCAborts(true)
+		state.SetTabCompletionStyle(liner.TabPrints)
+		state.SetCompleter(completer.Complete)
+
+		color.Green.Println("Unlocked. Type 'help' for the available commands.")
+		for {
+			line, err := state.Prompt(shellPrompt)
+			if errors.Is(err, io.EOF) {
+				fmt.Println()
+				return
+			}
+			if errors.Is(err, liner.ErrPromptAborted) {
+				continue
+			}
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			words, err := shell.Split(line)
+			if err != nil {
+				color.Red.Println(err)
+				continue
+			}
+			if len(words) == 0 {
+				continue
+			}
+			state.AppendHistory(line)
+			switch words[0] {
+			case "exit", "quit":
+				return
+			case "help":
+				printShellHelp()
+				continue
+			case "lock":
+				locker.Lock()
+				color.Yellow.Println("Session locked")
+				continue
+			case "unlock":
+				if !locker.Locked() {
+					color.Yellow.Println("Session is not locked")
+					continue
+				}
+				//输入主密码时恢复终端原来的模式
+				if originErr == nil {
+					originMode.ApplyMode()
+				}
+				mu.Lock()
+				err := unlockShellSession(locker)
+				mu.Unlock()
+				if linerErr == nil {
+					linerMode.ApplyMode()
+				}
+				if err != nil {
+					color.Red.Println(err)
+					continue
+				}
+				locker.Unlock()
+				color.Green.Println("Session unlocked")
+				continue
+			}
+			if !isShellCommand(words[0]) {
+				color.Red.Println("unknown command: " + words[0] + ", type 'help' for the available commands")
+				continue
+			}
+			if !locker.Begin() {
+				color.Yellow.Println("Session is locked, type 'unlock' to continue")
+				continue
+			}
+			if originErr == nil {
+				originMode.ApplyMode()
+			}
+			runShellCommand(words)
+			if linerErr == nil {
+				linerMode.ApplyMode()
+			}
+			locker.End()
+		}
+	},
+}
+
+// unlockShellVault 打开数据库并保存为交互式会话共享的实例
+func unlockShellVault() error {
+	vaultInstance, err := openVault()
+	if err != nil {
+		return err
+	}
+	sharedVault = vaultInstance
+	return nil
+}
+
+// unlockShellSession 重新打开数据库并解锁会话，设置了主密码时需要先输入主密码
+// 主密码错误时关闭数据库，会话保持锁定
+func unlockShellSession(locker *shell.Locker) error {
+	if err := unlockShellVault(); err != nil {
+		return err
+	}
+	verify, err := masterVerifier(sharedVault)
+	if err != nil {
+		lockShellVault()
+		return err
+	}
+	var masterPassword string
+	if verify != nil {
+		masterPassword, err = input.GetPasswordInput("Enter master password")
+		if err != nil {
+			lockShellVault()
+			return err
+		}
+	}
+	if err := locker.UnlockWithPassword(verify, masterPassword); err != nil {
+		lockShellVault()
+		return err
+	}
+	return nil
+}
+
+// lockShellVault 关闭数据库并丢弃已经加载的密钥
+func lockShellVault() {
+	if sharedVault == nil {
+		return
+	}
+	sharedVault.kit.Close()
+	sharedVault = nil
+}
+
+// isShellCommand 判断是否是可以在交互式会话中执行的命令
+func isShellCommand(name string) bool {
+	for _, command := range shellCommands {
+		if command == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runShellCommand 在交互式会话中执行子命令
+func runShellCommand(args []string) {
+	command, _, err := rootCmd.Find(args)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//上一次执行设置的参数会保留在命令中，执行前先恢复默认值
+	resetFlags(command)
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		color.Red.Println(err)
+	}
+}
+
+// resetFlags 将命令的参数恢复为默认值
+func resetFlags(command *cobra.Command) {
+	command.Flags().VisitAll(func(flag *pflag.Flag) {
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			sliceValue.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	})
+}
+
+// printShellHelp 输出交互式会话中可用的命令
+func printShellHelp() {
+	for _, name := range shellCommands {
+		command, _, err := rootCmd.Find([]string{name})
+		if err != nil {
+			continue
+		}
+		color.Blue.Printf("  %-8s", name)
+		fmt.Println(command.Short)
+	}
+	color.Blue.Printf("  %-8s", "lock")
+	fmt.Println("Lock the session now")
+	color.Blue.Printf("  %-8s", "unlock")
+	fmt.Println("Unlock a locked session")
+	color.Blue.Printf("  %-8s", "exit")
+	fmt.Println("Leave the shell")
+	fmt.Println("Use '<command> --help' for more information about a command.")
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+	shellCmd.Flags().Duration("lock-after", defaultShellIdleTime, "lock the session after this period of inactivity")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// shellCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// shellCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"net"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/input"
+	"password_manager/service/sshagent"
+	"path/filepath"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// sshAddCmd represents the ssh-add command
+var sshAddCmd = &cobra.Command{
+	Use:   "ssh-add <file>",
+	Short: "Import an SSH private key into pm",
+	Long: `Import an SSH private key file into pm as an entry of type ssh_key.
+
+The key is checked before it is stored and its public key is saved with it.
+Encrypted keys ask for the passphrase, which is stored in the entry so that
+'pm ssh-agent' can use the key without asking. The entry is named after the
+file unless --name is given. If 'pm ssh-agent' is running, the key is also
+added to it right away.
+
+After importing, the key file can be deleted from ~/.ssh.
+
+Example:
+  pm ssh-add ~/.ssh/id_ed25519
+  pm ssh-add ~/.ssh/id_rsa --name work_rsa --platform github`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			name = filepath.Base(args[0])
+		}
+		platform, _ := cmd.Flags().GetString("platform")
+		pemData, err := os.ReadFile(args[0])
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//加密的私钥需要口令
+		passphrase := ""
+		if _, err := sshagent.ParsePrivateKey(pemData, ""); errors.Is(err, sshagent.ErrPassphraseRequired) {
+			passphrase, err = input.GetPasswordInput("Enter passphrase for " + args[0])
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		key, err := sshagent.ImportKey(vaultInstance.srv, name, platform, pemData, passphrase)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("ssh key saved as " + name + " and backup successfully!")
+		//pm ssh-agent 正在运行时直接添加
+		conn, err := net.DialTimeout("unix", sshagent.DefaultSocketPath(), time.Second)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if err := agent.NewClient(conn).Add(agent.AddedKey{PrivateKey: key, Comment: name}); err != nil {
+			color.Yellow.Println("failed to add the key to pm ssh-agent: " + err.Error())
+			return
+		}
+		color.Green.Println("key added to pm ssh-agent")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sshAddCmd)
+	sshAddCmd.Flags().String("name", "", "key of the entry, defaults to the file name")
+	sshAddCmd.Flags().String("platform", "", "platform of the entry")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// sshAddCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// sshAddCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	zaplog "password_manager/common/log"
+	"password_manager/service/agent"
+	"password_manager/service/input"
+	"password_manager/service/sshagent"
+	"strings"
+	"syscall"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// sshAgentCmd represents the ssh-agent command
+var sshAgentCmd = &cobra.Command{
+	Use:   "ssh-agent",
+	Short: "Serve SSH keys stored in pm over the OpenSSH agent protocol",
+	Long: `Start an SSH agent that serves the private keys stored in pm.
+
+All entries of type ssh_key (see 'pm add --type ssh_key' and 'pm ssh-add') are
+loaded when the agent starts, so the keys never have to be written to ~/.ssh.
+ed25519, RSA and ECDSA keys are supported; encrypted keys are decrypted with the
+passphrase stored in the entry. The keys are kept in memory only. Send SIGHUP to
+the agent to load the keys again after the vault was changed.
+
+Point ssh at the agent with the SSH_AUTH_SOCK environment variable. The socket
+path is taken from PM_SSH_AUTH_SOCK, otherwise $XDG_RUNTIME_DIR/pm-ssh-agent.sock
+or a private directory in the system temporary directory is used.
+
+With --confirm every signature has to be confirmed in the terminal running the
+agent. Keys added with 'ssh-add -c' always ask for confirmation. 'ssh-add -l',
+'ssh-add -x' and 'ssh-add -X' work as with OpenSSH.
+
+The agent runs in the foreground; stop it with Ctrl-C.
+
+Example:
+  pm ssh-agent --confirm
+  export SSH_AUTH_SOCK=$XDG_RUNTIME_DIR/pm-ssh-agent.sock
+  ssh git@github.com`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		confirm, err := cmd.Flags().GetBool("confirm")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		socketPath := sshagent.DefaultSocketPath()
+		listener, err := agent.Listen(socketPath)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		defer listener.Close()
+
+		sshAgent := sshagent.NewAgent(sshagent.Options{ConfirmAll: confirm, Confirm: confirmSignature})
+		if !loadSSHKeys(sshAgent) {
+			return
+		}
+		defer sshAgent.RemoveAll()
+
+		//收到退出信号后关闭监听，收到 SIGHUP 时重新加载密钥
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(signals)
+		go func() {
+			for sig := range signals {
+				if sig == syscall.SIGHUP {
+					loadSSHKeys(sshAgent)
+					continue
+				}
+				listener.Close()
+				return
+			}
+		}()
+
+		color.Green.Println("pm ssh-agent listening on " + socketPath)
+		color.Gray.Println("export SSH_AUTH_SOCK=" + socketPath)
+		if err := sshAgent.Serve(listener); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Yellow.Println("pm ssh-agent stopped")
+	},
+}
+
+// loadSSHKeys 从密码库加载 ssh_key 条目，数据库只在加载时打开
+func loadSSHKeys(sshAgent *sshagent.Agent) bool {
+	vaultInstance, err := openVault()
+	if err != nil {
+		color.Red.Println(err)
+		return false
+	}
+	names, err := sshAgent.LoadVault(vaultInstance.srv)
+	vaultInstance.kit.Close()
+	if err != nil {
+		color.Yellow.Println("some keys could not be loaded:")
+		color.Yellow.Println(err)
+	}
+	if len(names) == 0 {
+		color.Yellow.Println("no ssh keys found, add one with 'pm ssh-add <file>'")
+		return true
+	}
+	color.Green.Println("loaded ssh keys: " + strings.Join(names, ", "))
+	return true
+}
+
+// confirmSignature 在运行 agent 的终端中询问是否允许签名
+func confirmSignature(req sshagent.ConfirmRequest) (bool, error) {
+	answer, err := input.GetInput("Allow signing with " + req.Comment + " (" + req.Fingerprint + ") (y/n)")
+	if err != nil {
+		return false, err
+	}
+	return answer == "y", nil
+}
+
+func init() {
+	rootCmd.AddCommand(sshAgentCmd)
+	sshAgentCmd.Flags().Bool("confirm", false, "ask for confirmation before every signature")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// sshAgentCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// sshAgentCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	zaplog "password_manager/common/log"
+	"password_manager/service/password"
+	"password_manager/service/stats"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// statsExitError 统计命令出错时的退出码，统计没有"发现问题"的情况，因此与 audit 不同只有这一个
+const statsExitError = 1
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show vault statistics and password usage",
+	Long: `Show how many entries the vault holds per type, how many were created,
+changed and used recently, and which passwords are used the most and which
+have not been used for the longest time.
+
+Every entry records when it was created and when its password last changed.
+Every time a password is handed out, the time is recorded and a use counter is
+incremented: 'pm query', 'pm note', 'pm read', 'pm run', 'pm inject' and
+'pm env', a get through the API, the web UI or the browser extension, a git or
+docker credential lookup and loading a key into 'pm ssh-agent'. Listing,
+searching and reading usernames or URLs do not count as a use. The usage is
+stored apart from the entries, so recording it never rewrites an entry. Entries
+saved by older versions have no creation time and start with no uses.
+
+Usage tracking is on by default. --tracking off stops recording new uses and
+keeps the existing ones, --tracking on turns it back on.
+
+The statistics only read metadata and never decrypt a password. They are
+printed as a table, or as JSON with --format json.
+
+Example:
+  pm stats
+  pm stats --recent 7d --top 10
+  pm stats --folder work --format json
+  pm stats --tracking off`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			os.Exit(statsExitError)
+		}
+		//标准输出只输出结果
+		stdout, restore := useStdoutForProtocol()
+		defer restore()
+		//初始化日志模块，日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			os.Exit(statsExitError)
+		}
+		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "json" {
+			color.Red.Println("unknown format " + format + ", expected table or json")
+			os.Exit(statsExitError)
+		}
+		tracking, _ := cmd.Flags().GetString("tracking")
+		if tracking != "" && tracking != "on" && tracking != "off" {
+			color.Red.Println("unknown tracking value " + tracking + ", expected on or off")
+			os.Exit(statsExitError)
+		}
+		recentValue, _ := cmd.Flags().GetString("recent")
+		recent, err := parseDays(recentValue)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(statsExitError)
+		}
+		top, _ := cmd.Flags().GetInt("top")
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(statsExitError)
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(statsExitError)
+		}
+		passwordInstance := vaultInstance.srv
+		//只修改设置
+		if tracking != "" {
+			err := passwordInstance.SetAccessTrackingEnabled(tracking == "on")
+			vaultInstance.kit.Close()
+			if err != nil {
+				color.Red.Println(err)
+				os.Exit(statsExitError)
+			}
+			color.Green.Println("Usage tracking turned " + tracking)
+			return
+		}
+		enabled, err := passwordInstance.AccessTrackingEnabled()
+		if err != nil {
+			vaultInstance.kit.Close()
+			color.Red.Println(err)
+			os.Exit(statsExitError)
+		}
+		entries, err := passwordInstance.GetAllEntries()
+		vaultInstance.kit.Close()
+		if err != nil {
+			color.Red.Println(err)
+			os.Exit(statsExitError)
+		}
+		var matched []password.PasswordData
+		for _, data := range entries {
+			if filter.Match(data) {
+				matched = append(matched, data)
+			}
+		}
+		report := stats.Run(matched, stats.Options{Recent: recent, Top: top})
+		if format == "json" {
+			encoder := json.NewEncoder(stdout)
+			encoder.SetIndent("", "  ")
+			value := struct {
+				stats.Report
+				Tracking bool `json:"tracking"`
+			}{report, enabled}
+			if err := encoder.Encode(value); err != nil {
+				color.Red.Println(err)
+				os.Exit(statsExitError)
+			}
+			return
+		}
+		printStats(stdout, report, enabled, time.Now())
+	},
+}
+
+// printStats 以表格输出统计结果
+func printStats(out io.Writer, report stats.Report, tracking bool, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Entries\t"+strconv.Itoa(report.Total))
+	for _, count := range report.Types {
+		fmt.Fprintln(w, "  "+count.Type+"\t"+strconv.Itoa(count.Count))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Last "+strconv.Itoa(report.RecentDays)+" days")
+	fmt.Fprintln(w, "  created\t"+strconv.Itoa(report.CreatedRecently))
+	fmt.Fprintln(w, "  changed\t"+strconv.Itoa(report.UpdatedRecently))
+	fmt.Fprintln(w, "  used\t"+strconv.Itoa(report.UsedRecently))
+	fmt.Fprintln(w)
+	state := "on"
+	if !tracking {
+		state = "off"
+	}
+	fmt.Fprintln(w, "Usage tracking\t"+state)
+	fmt.Fprintln(w, "Total uses\t"+strconv.Itoa(report.TotalUses))
+	fmt.Fprintln(w, "Never used\t"+strconv.Itoa(report.NeverUsed))
+	if report.UnknownCreated > 0 {
+		fmt.Fprintln(w, "Unknown creation time\t"+strconv.Itoa(report.UnknownCreated))
+	}
+	for _, section := range []struct {
+		title  string
+		usages []stats.Usage
+	}{
+		{"Most used", report.MostUsed},
+		{"Least recently used", report.LeastRecentlyUsed},
+	} {
+		if len(section.usages) == 0 {
+			continue
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, section.title)
+		for _, usage := range section.usages {
+			uses := strconv.Itoa(usage.UseCount) + " uses"
+			if usage.UseCount == 1 {
+				uses = "1 use"
+			}
+			fmt.Fprintln(w, "  "+usage.Key+"\t"+uses+"\t"+describeLastUsed(usage.LastUsed, now))
+		}
+	}
+	w.Flush()
+}
+
+// describeLastUsed 描述最后一次使用的时间
+func describeLastUsed(lastUsed, now time.Time) string {
+	if lastUsed.IsZero() {
+		return "never used"
+	}
+	switch days := int(now.Sub(lastUsed).Hours() / 24); days {
+	case 0:
+		return "last used today"
+	case 1:
+		return "last used yesterday"
+	default:
+		return "last used " + strconv.Itoa(days) + " days ago"
+	}
+}
+
+// describeActivity 描述条目的创建、修改和使用情况，没有任何记录时返回空字符串
+func describeActivity(data password.PasswordData) string {
+	var parts []string
+	if !data.Created.IsZero() {
+		parts = append(parts, "created: "+data.Created.Local().Format(password.ExpiryDateLayout))
+	}
+	if !data.Modified.IsZero() {
+		parts = append(parts, "changed: "+data.Modified.Local().Format(password.ExpiryDateLayout))
+	}
+	if data.UseCount > 0 {
+		parts = append(parts, "used: "+strconv.Itoa(data.UseCount)+"x, last "+data.LastUsed.Local().Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// printActivity 输出条目的创建、修改和使用情况
+func printActivity(data password.PasswordData) {
+	if activity := describeActivity(data); activity != "" {
+		color.Gray.Println("  " + activity)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	addFilterFlags(statsCmd)
+	statsCmd.Flags().String("format", "table", "output format: table or json")
+	statsCmd.Flags().String("recent", "30d", "time range counted as recent, e.g. 7d")
+	statsCmd.Flags().Int("top", stats.DefaultTop, "number of most used and least recently used entries to show, 0 hides them")
+	statsCmd.Flags().String("tracking", "", "turn usage tracking on or off")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// statsCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// statsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gookit/color"
+)
+
+// useStdoutForProtocol 将标准输出留给通过标准输出通信的命令
+// 其他模块打印到标准输出的内容都改为写到标准错误，返回原来的标准输出和恢复函数
+func useStdoutForProtocol() (*os.File, func()) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	color.SetOutput(os.Stderr)
+	return stdout, func() {
+		os.Stdout = stdout
+		color.ResetOutput()
+	}
+}
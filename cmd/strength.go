@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"password_manager/service/strength"
+	"strconv"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// checkPasswordStrength 显示密码的强度，低于策略要求且没有使用 --force 时返回 false
+// userInputs 为 key、平台和用户名，密码中包含它们时分数更低
+func checkPasswordStrength(cmd *cobra.Command, vaultInstance *vault, value string, userInputs ...string) bool {
+	result, err := vaultInstance.srv.CheckStrength(value, userInputs...)
+	var weakErr *strength.WeakPasswordError
+	if err != nil && !errors.As(err, &weakErr) {
+		color.Red.Println(err)
+		return false
+	}
+	printStrength(result)
+	if weakErr != nil {
+		if force, _ := cmd.Flags().GetBool("force"); force {
+			color.Yellow.Println(err.Error() + ", saved anyway because of --force")
+			return true
+		}
+		color.Red.Println(err)
+		color.Red.Println("use --force to save it anyway, or change the policy with 'pm policy --min-score'")
+		return false
+	}
+	return true
+}
+
+// printStrength 输出分数、破解时间、警告和建议
+func printStrength(result strength.Result) {
+	line := "Strength: " + strconv.Itoa(result.Score) + "/4 (" + strength.Label(result.Score) + "), estimated time to crack: " + result.CrackTime
+	switch {
+	case result.Score >= strength.ScoreStrong:
+		color.Green.Println(line)
+	case result.Score == strength.ScoreFair:
+		color.Yellow.Println(line)
+	default:
+		color.Red.Println(line)
+	}
+	for _, warning := range result.Warnings {
+		color.Yellow.Println("  ! " + warning)
+	}
+	for _, suggestion := range result.Suggestions {
+		color.Gray.Println("  - " + suggestion)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	zaplog "password_manager/common/log"
+	"password_manager/service/password"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "List, add or remove tags of entries",
+	Long: `An entry can carry any number of tags. Tags are used to filter 'pm list',
+'pm search', 'pm audit' and 'pm env' with --tag.
+
+  pm tag                      list all tags and how many entries use them
+  pm tag add <key> <tag>...   add tags to an entry
+  pm tag rm <key> <tag>...    remove tags from an entry
+
+Tags can also be given comma separated, and are set when an entry is created
+with 'pm add --tag'.
+
+Example:
+  pm tag add aws_root work cloud
+  pm tag rm aws_root cloud
+  pm list --tag work`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		entries, err := vaultInstance.srv.GetAllPasswords()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		tags, counts := password.TagCounts(entries)
+		if len(tags) == 0 {
+			color.Yellow.Println("No tags yet, add one with 'pm tag add <key> <tag>'")
+			return
+		}
+		fmt.Println()
+		for _, tag := range tags {
+			color.Blue.Print(tag)
+			color.Gray.Println(" (" + strconv.Itoa(counts[tag]) + ")")
+		}
+		fmt.Println()
+	},
+}
+
+// tagAddCmd represents the tag add command
+var tagAddCmd = &cobra.Command{
+	Use:   "add <key> <tag>...",
+	Short: "Add tags to an entry",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		changeTags(args[0], splitTags(args[1:]), true)
+	},
+}
+
+// tagRmCmd represents the tag rm command
+var tagRmCmd = &cobra.Command{
+	Use:   "rm <key> <tag>...",
+	Short: "Remove tags from an entry",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		changeTags(args[0], splitTags(args[1:]), false)
+	},
+}
+
+// splitTags 拆分以逗号分隔的标签
+func splitTags(args []string) []string {
+	var tags []string
+	for _, arg := range args {
+		tags = append(tags, strings.Split(arg, ",")...)
+	}
+	return tags
+}
+
+// changeTags 给条目添加或者删除标签并备份
+func changeTags(key string, tags []string, add bool) {
+	//初始化日志模块
+	if err := zaplog.LoggerInit(); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//打开数据库
+	vaultInstance, err := openVault()
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	passwordInstance := vaultInstance.srv
+	if add {
+		err = passwordInstance.AddTags(key, tags...)
+	} else {
+		err = passwordInstance.RemoveTags(key, tags...)
+	}
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	meta, err := passwordInstance.GetMeta(key)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	if len(meta.Tags) == 0 {
+		color.Green.Println(key + " has no tags now")
+	} else {
+		color.Green.Println(key + " tags: " + strings.Join(meta.Tags, ", "))
+	}
+	//备份
+	if err := vaultInstance.kit.BackupDB(); err != nil {
+		color.Red.Println(err)
+		return
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRmCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// tagCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// tagCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"password_manager/service/password"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+// folderNode 文件夹树中的一个文件夹
+type folderNode struct {
+	folders map[string]*folderNode
+	entries []password.PasswordData
+}
+
+// buildFolderTree 按文件夹路径把条目组织成树
+func buildFolderTree(entries []password.PasswordData) *folderNode {
+	root := &folderNode{folders: make(map[string]*folderNode)}
+	for _, data := range entries {
+		node := root
+		if data.Folder != "" {
+			for _, name := range strings.Split(data.Folder, password.FolderSeparator) {
+				child, ok := node.folders[name]
+				if !ok {
+					child = &folderNode{folders: make(map[string]*folderNode)}
+					node.folders[name] = child
+				}
+				node = child
+			}
+		}
+		node.entries = append(node.entries, data)
+	}
+	return root
+}
+
+// printFolderTree 输出文件夹树，先输出子文件夹再输出条目，都按名称排序，不输出密码
+func printFolderTree(node *folderNode, prefix string) {
+	names := make([]string, 0, len(node.folders))
+	for name := range node.folders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sort.Slice(node.entries, func(i, j int) bool {
+		return node.entries[i].Key < node.entries[j].Key
+	})
+	total := len(names) + len(node.entries)
+	index := 0
+	branch := func() (string, string) {
+		index++
+		if index == total {
+			return prefix + "└── ", prefix + "    "
+		}
+		return prefix + "├── ", prefix + "│   "
+	}
+	for _, name := range names {
+		line, childPrefix := branch()
+		color.Gray.Print(line)
+		color.Yellow.Println(name + password.FolderSeparator)
+		printFolderTree(node.folders[name], childPrefix)
+	}
+	for _, data := range node.entries {
+		line, _ := branch()
+		color.Gray.Print(line)
+		color.Blue.Print(data.Key)
+		if data.Platform != "" {
+			color.Cyan.Print(" (" + data.Platform + ")")
+		}
+		if len(data.Tags) > 0 {
+			color.Gray.Print("  #" + strings.Join(data.Tags, " #"))
+		}
+		if data.Expired(time.Now()) {
+			color.Red.Print("  (expired)")
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	zaplog "password_manager/common/log"
+	"password_manager/service/clipboard"
+	"password_manager/service/tui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// uiCmd represents the ui command
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse and edit stored passwords in a full-screen terminal UI",
+	Long: `Browse and edit stored passwords in a full-screen terminal UI.
+
+The left pane lists all entries and the right pane shows the details of the
+selected entry. Passwords are masked until they are revealed.
+
+Key bindings:
+  /         filter the list (fuzzy search, pinyin supported)
+  j/k, ↑/↓  move the selection
+  r         reveal or hide the password
+  c         copy the password to the clipboard
+  u         copy the username to the clipboard
+  a         add an entry
+  e         edit the selected entry
+  d         delete the selected entry
+  L         lock now
+  q, Esc    quit
+
+The UI locks itself after a period of inactivity and clears the decrypted
+passwords from memory. If a master password is set (see 'pm web
+--set-master-password'), it must be entered to unlock. Without one, locking
+only hides the screen and Enter shows it again.
+
+Example:
+  pm ui
+  pm ui --lock-after 2m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块，全屏界面下日志不输出到终端
+		if err := zaplog.LoggerInitFileOnly(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		lockAfter, err := cmd.Flags().GetDuration("lock-after")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		passwordInstance := vaultInstance.srv
+		//设置了主密码时解锁需要输入主密码
+		verify, err := masterVerifier(vaultInstance)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+
+		//初始化终端界面
+		screen, err := tcell.NewScreen()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		app := tui.NewApp(screen, passwordInstance, tui.Options{
+			LockAfter: lockAfter,
+			Clipboard: clipboard.Copy,
+			Verify:    verify,
+		})
+		if err := app.Run(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//备份
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().Duration("lock-after", tui.DefaultLockAfter, "lock the UI after this period of inactivity")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// uiCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// uiCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
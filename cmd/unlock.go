@@ -0,0 +1,58 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	zaplog "password_manager/common/log"
+	"password_manager/service/agent"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+// unlockCmd represents the unlock command
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Load the secret key into pm agent again",
+	Long: `Ask the running pm agent to load the secret key again.
+
+The key is kept for the ttl the agent was started with. No credential is
+needed: the agent reads the secret key file, which the current user can read
+anyway, so locking does not protect against other processes of the same user.
+
+Example:
+  pm unlock`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		client := agent.NewClient(agent.DefaultSocketPath())
+		status, err := client.Unlock()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Green.Println("pm agent unlocked until " + status.ExpiresAt.Format("2006-01-02 15:04:05"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// unlockCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// unlockCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
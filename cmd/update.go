@@ -5,11 +5,8 @@ package cmd
 
 import (
 	zaplog "password_manager/common/log"
-	"password_manager/service/aes"
-	dbfilekit "password_manager/service/dbfile_Kit"
 	"password_manager/service/input"
 	"password_manager/service/password"
-	secretkey "password_manager/service/secret_key"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -31,7 +28,29 @@ For example:
   Enter new platform (optional): GitHub
 
 If the key exists, the corresponding password or platform will be updated. 
-If no new password or platform is provided, the existing values will remain unchanged.`,
+If no new password or platform is provided, the existing values will remain unchanged.
+
+The username, URL and tags can be changed with flags. An empty value clears the field:
+
+  pm update github_john.doe --username john --tag work,code
+
+With --env only the value of that environment is changed, the environment must
+already exist (use 'pm add <key> --env <env>' to add one):
+
+  pm update prod_db --env prod
+
+Fields of a typed entry (see 'pm add --type') are changed with --field, an
+empty value removes an optional field. The entry is validated again:
+
+  pm update visa --field expiry=09/31 --field pin=
+
+New passwords are checked against the strength policy (see 'pm policy'), use
+--force to save a weaker password anyway.
+
+--expires sets an expiry date or a rotation interval, 'never' removes it:
+
+  pm update aws_root --expires 90d
+  pm update vpn --expires never`,
 	Run: func(cmd *cobra.Command, args []string) {
 		//初始化日志模块
 		if err := zaplog.LoggerInit(); err != nil {
@@ -54,6 +73,22 @@ If no new password or platform is provided, the existing values will remain unch
 			color.Red.Println("Key or account cannot be empty")
 			return
 		}
+		if !checkExpiryFlag(cmd) {
+			return
+		}
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if env != "" {
+			updateEnvPassword(cmd, key, env)
+			return
+		}
+		if cmd.Flags().Changed("field") {
+			updateRecordFields(cmd, key)
+			return
+		}
 		//获取新密码
 		newKey, err := input.GetOptionalInput("Enter new Key or account(optional, press Enter to skip)")
 		if err != nil {
@@ -72,44 +107,57 @@ If no new password or platform is provided, the existing values will remain unch
 			color.Red.Println(err)
 			return
 		}
-		if newPlatform == "" && newPassword == "" && newKey == "" {
+		metaChanged := cmd.Flags().Changed("username") || cmd.Flags().Changed("url") || cmd.Flags().Changed("tag") || cmd.Flags().Changed("expires")
+		if newPlatform == "" && newPassword == "" && newKey == "" && !metaChanged {
 			color.Red.Println("New password , new platform and newKey cannot be empty at the same time")
 			return
 		}
 		//获取新用户名
-		//初始化密钥模块
-		secretKeyInstance := secretkey.NewSecretKey()
-
-		//初始化数据库模块
-		kitInstance := dbfilekit.NewDBKit(secretKeyInstance)
-		if err := kitInstance.Init(); err != nil {
-			color.Red.Println(err)
-			return
-		}
-
-		//获取数据库
-		db, err := kitInstance.GetDB()
+		//打开数据库
+		vaultInstance, err := openVault()
 		if err != nil {
 			color.Red.Println(err)
 			return
 		}
-
-		//获取密钥
-		secretKey, err := secretKeyInstance.GetSecretKey()
-		if err != nil {
-			color.Red.Println(err)
+		passwordInstance := vaultInstance.srv
+		if newPassword != "" && !checkPasswordStrength(cmd, vaultInstance, newPassword, key, newKey, newPlatform) {
 			return
 		}
-		//初始化加密模块
-		aesInstance := aes.NewAesService(secretKey)
-		//初始化密码保存模块
-		passwordInstance := password.NewPasswordService(aesInstance, db)
-		err = passwordInstance.UpdatePassword(key, newPassword, newPlatform, newKey)
-		if err != nil {
-			color.Red.Println(err)
-			return
+		if newPlatform != "" || newPassword != "" || newKey != "" {
+			result, err := passwordInstance.UpdatePassword(key, newPassword, newPlatform, newKey)
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			printUpdateResult(key, newPlatform, result)
+			key = result.Key
+		}
+		//更新附加信息
+		if metaChanged {
+			meta, err := passwordInstance.GetMeta(key)
+			if err != nil {
+				color.Red.Println(err)
+				return
+			}
+			if cmd.Flags().Changed("username") {
+				meta.Username, _ = cmd.Flags().GetString("username")
+			}
+			if cmd.Flags().Changed("url") {
+				meta.URL, _ = cmd.Flags().GetString("url")
+			}
+			if cmd.Flags().Changed("tag") {
+				meta.Tags, _ = cmd.Flags().GetStringSlice("tag")
+			}
+			if err := passwordInstance.SetMeta(key, meta); err != nil {
+				color.Red.Println(err)
+				return
+			}
+			if !applyExpiry(cmd, passwordInstance, key) {
+				return
+			}
+			color.Green.Println("details updated successfully")
 		}
-		err = kitInstance.BackupDB()
+		err = vaultInstance.kit.BackupDB()
 		if err != nil {
 			color.Red.Println(err)
 			return
@@ -118,8 +166,97 @@ If no new password or platform is provided, the existing values will remain unch
 	},
 }
 
+// printUpdateResult 输出 key、密码和平台的更新结果，不输出密码
+func printUpdateResult(key, newPlatform string, result password.UpdateResult) {
+	if result.Key != key {
+		color.Green.Println("key updated successfully:" + key + " -> " + result.Key)
+	}
+	if result.PasswordChanged {
+		color.Green.Println("password updated successfully")
+	}
+	if newPlatform != "" {
+		color.Green.Println("platform updated successfully:" + result.OldPlatform + " -> " + newPlatform)
+	}
+}
+
+// updateRecordFields 修改带类型条目的字段
+func updateRecordFields(cmd *cobra.Command, key string) {
+	fieldFlags, _ := cmd.Flags().GetStringArray("field")
+	changes, err := parseFieldFlags(fieldFlags)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//打开数据库
+	vaultInstance, err := openVault()
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	if value := changes["password"]; value != "" && !checkPasswordStrength(cmd, vaultInstance, value, key) {
+		return
+	}
+	if err := vaultInstance.srv.UpdateRecord(key, changes); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	if !applyExpiry(cmd, vaultInstance.srv, key) {
+		return
+	}
+	color.Green.Println("fields updated successfully")
+	if err := vaultInstance.kit.BackupDB(); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	color.Green.Println("backup successfully")
+}
+
+// updateEnvPassword 更新 key 在指定环境下的密码
+func updateEnvPassword(cmd *cobra.Command, key, env string) {
+	//打开数据库
+	vaultInstance, err := openVault()
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	passwordInstance := vaultInstance.srv
+	if _, err := passwordInstance.GetEnvPassword(key, env); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	//获取新密码
+	newPassword, err := input.GetPasswordInput("Enter new password for " + env)
+	if err != nil {
+		color.Red.Println(err)
+		return
+	}
+	if !checkPasswordStrength(cmd, vaultInstance, newPassword, key, env) {
+		return
+	}
+	if err := passwordInstance.SetEnvPassword(key, env, newPassword); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	if !applyExpiry(cmd, passwordInstance, key) {
+		return
+	}
+	color.Green.Println("password for " + env + " updated successfully")
+	if err := vaultInstance.kit.BackupDB(); err != nil {
+		color.Red.Println(err)
+		return
+	}
+	color.Green.Println("backup successfully")
+}
+
 func init() {
 	rootCmd.AddCommand(updateCmd)
+	updateCmd.Flags().String("username", "", "new username of the account")
+	updateCmd.Flags().String("url", "", "new URL of the login page")
+	updateCmd.Flags().StringSlice("tag", nil, "new tags of the entry, can be repeated or comma separated")
+	updateCmd.Flags().String("env", "", "update only the value of this environment")
+	updateCmd.Flags().Bool("force", false, "save the new password even if it does not meet the strength policy")
+	updateCmd.Flags().StringArray("field", nil, "new value of a field of a typed entry as name=value, @file reads the value from a file")
+	updateCmd.Flags().String("expires", "", "new expiry date such as 2026-12-31, rotation interval such as 90d, or never")
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"password_manager/service/aes"
+	"password_manager/service/agent"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/master"
+	"password_manager/service/password"
+	secretkey "password_manager/service/secret_key"
+)
+
+// vault 已经解锁的数据库和密码服务
+type vault struct {
+	kit *dbfilekit.DBKitImpl
+	srv *password.PasswordService
+}
+
+// sharedVault 交互式会话中已经解锁的数据库，为空时每个命令自己打开
+var sharedVault *vault
+
+// openVault 初始化密钥、数据库和密码服务，交互式会话中直接复用已经解锁的数据库
+func openVault() (*vault, error) {
+	if sharedVault != nil {
+		return sharedVault, nil
+	}
+	//初始化密钥模块
+	secretKeyInstance := newSecretKeySource()
+
+	//初始化数据库模块
+	kitInstance := dbfilekit.NewDBKit(secretKeyInstance)
+	if err := kitInstance.Init(); err != nil {
+		return nil, err
+	}
+
+	//获取数据库
+	db, err := kitInstance.GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	//获取密钥
+	secretKey, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		kitInstance.Close()
+		return nil, err
+	}
+	//初始化加密模块
+	aesInstance := aes.NewAesService(secretKey)
+	//初始化密码保存模块
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	return &vault{kit: kitInstance, srv: passwordInstance}, nil
+}
+
+// masterVerifier 返回验证主密码的函数，没有设置主密码时返回 nil
+// pm ui 和 pm shell 解锁时都通过它验证主密码
+func masterVerifier(vaultInstance *vault) (func(string) (bool, error), error) {
+	db, err := vaultInstance.kit.GetDB()
+	if err != nil {
+		return nil, err
+	}
+	masterInstance := master.NewMasterService(db)
+	hasMaster, err := masterInstance.IsSet()
+	if err != nil || !hasMaster {
+		return nil, err
+	}
+	return masterInstance.Verify, nil
+}
+
+// newSecretKeySource pm agent 正在运行时从 agent 获取密钥，否则读取密钥文件
+func newSecretKeySource() secretkey.SecretKeyInterface {
+	client := agent.NewClient(agent.DefaultSocketPath())
+	if client.Running() {
+		return client
+	}
+	return secretkey.NewSecretKey()
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	zaplog "password_manager/common/log"
+	"password_manager/service/input"
+	"password_manager/service/master"
+	"password_manager/service/web"
+	"syscall"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+const defaultWebAddr = "127.0.0.1:7879"
+
+// webCmd represents the web command
+var webCmd = &cobra.Command{
+	Use:   "web",
+	Short: "Serve a small web UI on localhost",
+	Long: `Serve a small web UI on localhost for searching, viewing, copying, adding,
+editing and deleting passwords in the browser.
+
+The page is unlocked with the master password. The first time 'pm web' is
+started it asks you to choose one; use --set-master-password to change it
+later. The master password only protects the web session, the stored
+passwords are still encrypted with the secret key.
+
+The session locks itself after a period of inactivity. Requests that change
+data must carry the CSRF token of the session, and only requests addressed to
+localhost are accepted.
+
+Example:
+  pm web
+  pm web --addr 127.0.0.1:9000 --lock-after 2m
+  pm web --set-master-password`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			color.Red.Println("invalid input")
+			return
+		}
+		//初始化日志模块
+		if err := zaplog.LoggerInit(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		addr, _ := cmd.Flags().GetString("addr")
+		lockAfter, err := cmd.Flags().GetDuration("lock-after")
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		setMaster, _ := cmd.Flags().GetBool("set-master-password")
+
+		//打开数据库
+		vaultInstance, err := openVault()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		db, err := vaultInstance.kit.GetDB()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		//初始化主密码模块，没有主密码时先设置
+		masterInstance := master.NewMasterService(db)
+		isSet, err := masterInstance.IsSet()
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		if !isSet || setMaster {
+			if err := promptMasterPassword(masterInstance); err != nil {
+				color.Red.Println(err)
+				return
+			}
+			color.Green.Println("master password saved")
+		}
+
+		listener, err := listenLoopback(addr)
+		if err != nil {
+			color.Red.Println(err)
+			return
+		}
+		server := &http.Server{
+			Handler: web.NewServer(vaultInstance.srv, vaultInstance.kit, masterInstance, web.Options{
+				LockAfter: lockAfter,
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		//收到退出信号后关闭服务
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			<-signals
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(ctx)
+		}()
+
+		color.Green.Println("pm web UI: http://" + listener.Addr().String())
+		color.Gray.Println("Press Ctrl-C to stop")
+
+		err = server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			color.Red.Println(err)
+			return
+		}
+		//备份
+		if err := vaultInstance.kit.BackupDB(); err != nil {
+			color.Red.Println(err)
+			return
+		}
+		color.Yellow.Println("pm web UI stopped")
+	},
+}
+
+// promptMasterPassword 在终端中输入两次新的主密码并保存
+func promptMasterPassword(masterInstance *master.MasterService) error {
+	for {
+		password, err := input.GetPasswordInput("Enter new master password")
+		if err != nil {
+			return err
+		}
+		if len([]rune(password)) < master.MinLength {
+			color.Yellow.Println("The master password must be at least 8 characters long.")
+			continue
+		}
+		confirm, err := input.GetPasswordInput("Enter the master password again")
+		if err != nil {
+			return err
+		}
+		if password != confirm {
+			color.Yellow.Println("The passwords do not match, please try again.")
+			continue
+		}
+		return masterInstance.SetMasterPassword(password)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(webCmd)
+	webCmd.Flags().String("addr", defaultWebAddr, "loopback address to listen on")
+	webCmd.Flags().Duration("lock-after", web.DefaultLockAfter, "lock the web session after this period of inactivity")
+	webCmd.Flags().Bool("set-master-password", false, "change the master password before starting")
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// webCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// webCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -15,7 +15,17 @@ import (
 // 	logFile = "log.txt"
 // )
 
+// LoggerInit 初始化日志，错误日志同时输出到终端
 func LoggerInit() error {
+	return loggerInit(true)
+}
+
+// LoggerInitFileOnly 初始化只写入文件的日志，用于全屏界面以及通过标准输出通信的命令
+func LoggerInitFileOnly() error {
+	return loggerInit(false)
+}
+
+func loggerInit(console bool) error {
 
 	execPath, _ := os.Executable()
 	logDir := filepath.Join(filepath.Dir(execPath), "logs")
@@ -49,10 +59,11 @@ func LoggerInit() error {
 	//设置日志编码器
 	EncoderConfig := zap.NewDevelopmentEncoderConfig()
 	encoder := zapcore.NewJSONEncoder(EncoderConfig)
-	core := zapcore.NewTee(
-		zapcore.NewCore(encoder, FileWriteSyncer, zap.InfoLevel),
-		zapcore.NewCore(encoder, stdioWriteSyncer, zap.ErrorLevel),
-	)
+	cores := []zapcore.Core{zapcore.NewCore(encoder, FileWriteSyncer, zap.InfoLevel)}
+	if console {
+		cores = append(cores, zapcore.NewCore(encoder, stdioWriteSyncer, zap.ErrorLevel))
+	}
+	core := zapcore.NewTee(cores...)
 	//初始化实例
 	logger := zap.New(core, zap.AddCaller())
 
@@ -15,7 +15,8 @@ var _ AesInterface = (*AesService)(nil)
 var AesSrv *AesService
 
 type AesService struct {
-	block  cipher.Block
+	// gcm 只创建一次，Seal 和 Open 不修改状态，可以并发使用
+	gcm    cipher.AEAD
 	logger *zap.Logger
 }
 
@@ -24,8 +25,12 @@ func NewAesService(secretKey string) *AesService {
 	if err != nil {
 		panic(err)
 	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
 	return &AesService{
-		block:  block,
+		gcm:    gcm,
 		logger: zap.L(),
 	}
 }
@@ -36,17 +41,13 @@ func (srv *AesService) Encrypt(plainText string) ([]byte, []byte, error) {
 		srv.logger.Error("plainText is empty")
 		return nil, nil, errors.New("plainText is empty")
 	}
-	gcm, err := cipher.NewGCM(srv.block)
-	if err != nil {
-		return nil, nil, err
-	}
 	//创建随机的nonce
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+	nonce := make([]byte, srv.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, nil, err
 	}
 	// 加密并附加认证标签
-	cipherData := gcm.Seal(nil, nonce, []byte(plainText), nil)
+	cipherData := srv.gcm.Seal(nil, nonce, []byte(plainText), nil)
 	return cipherData, nonce, nil
 }
 
@@ -61,13 +62,8 @@ func (srv *AesService) Decrypt(cipherData, nonce []byte) ([]byte, error) {
 		srv.logger.Error("nonceHex is nil")
 		return nil, errors.New("nonceHex is nil")
 	}
-	gcm, err := cipher.NewGCM(srv.block)
-	if err != nil {
-		return nil, err
-	}
-
 	// 解密并验证
-	plainData, err := gcm.Open(nil, nonce, cipherData, nil)
+	plainData, err := srv.gcm.Open(nil, nonce, cipherData, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,164 @@
+package agent_test
+
+import (
+	"net"
+	"os"
+	"password_manager/service/agent"
+	secretkey "password_manager/service/secret_key"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSocketPath 返回临时目录中的 socket 路径
+func newSocketPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "agent.sock")
+}
+
+// startAgent 在临时目录中启动一个 agent，返回服务、密钥和 socket 路径
+func startAgent(t *testing.T, ttl time.Duration) (*agent.Server, string, string) {
+	dir := t.TempDir()
+	keyInstance := secretkey.NewSecretKeyWithFilePath(filepath.Join(dir, "test.gob"))
+	if err := keyInstance.SetSecretKey(); err != nil {
+		t.Fatal(err)
+	}
+	key, err := keyInstance.GetSecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	socketPath := filepath.Join(dir, "agent.sock")
+	listener, err := agent.Listen(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := agent.NewServer(keyInstance, ttl)
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	return server, key, socketPath
+}
+
+func TestAgent(t *testing.T) {
+	assert := assert.New(t)
+	_, key, socketPath := startAgent(t, time.Minute)
+	client := agent.NewClient(socketPath)
+	assert.True(client.Running())
+
+	// socket 只允许当前用户访问
+	info, err := os.Stat(socketPath)
+	if assert.NoError(err) {
+		assert.Equal(os.FileMode(0600), info.Mode().Perm())
+	}
+
+	// 初始状态为锁定
+	_, err = client.GetSecretKey()
+	assert.ErrorIs(err, agent.ErrLocked)
+
+	status, err := client.Unlock()
+	assert.NoError(err)
+	assert.False(status.Locked)
+	assert.WithinDuration(time.Now().Add(time.Minute), status.ExpiresAt, 5*time.Second)
+
+	got, err := client.GetSecretKey()
+	assert.NoError(err)
+	assert.Equal(key, got)
+
+	assert.ErrorIs(client.SetSecretKey(), agent.ErrSetKeyUnsupported)
+
+	assert.NoError(client.Lock())
+	status, err = client.Status()
+	assert.NoError(err)
+	assert.True(status.Locked)
+	_, err = client.GetSecretKey()
+	assert.ErrorIs(err, agent.ErrLocked)
+
+	// 同一路径上不能启动第二个 agent
+	_, err = agent.Listen(socketPath)
+	assert.Error(err)
+}
+
+func TestAgentTTL(t *testing.T) {
+	assert := assert.New(t)
+	server, _, socketPath := startAgent(t, 100*time.Millisecond)
+	client := agent.NewClient(socketPath)
+
+	assert.NoError(server.Unlock())
+	_, err := client.GetSecretKey()
+	assert.NoError(err)
+
+	// 超过缓存时间后密钥被清除
+	assert.Eventually(func() bool {
+		return server.Status().Locked
+	}, 2*time.Second, 20*time.Millisecond)
+	_, err = client.GetSecretKey()
+	assert.ErrorIs(err, agent.ErrLocked)
+}
+
+func TestClientNotRunning(t *testing.T) {
+	assert := assert.New(t)
+	client := agent.NewClient(newSocketPath(t))
+	assert.False(client.Running())
+	_, err := client.GetSecretKey()
+	assert.Error(err)
+}
+
+func TestListenRemovesStaleSocket(t *testing.T) {
+	assert := assert.New(t)
+	socketPath := newSocketPath(t)
+	//关闭后不删除 socket 文件，模拟异常退出后残留的 socket
+	listener, err := net.Listen("unix", socketPath)
+	if !assert.NoError(err) {
+		return
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	listener, err = agent.Listen(socketPath)
+	if assert.NoError(err) {
+		listener.Close()
+	}
+	_, err = os.Stat(socketPath)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestListenKeepsOtherFiles(t *testing.T) {
+	assert := assert.New(t)
+	socketPath := newSocketPath(t)
+	if !assert.NoError(os.WriteFile(socketPath, []byte("data"), 0600)) {
+		return
+	}
+	_, err := agent.Listen(socketPath)
+	assert.Error(err)
+	content, err := os.ReadFile(socketPath)
+	assert.NoError(err)
+	assert.Equal("data", string(content))
+}
+
+func TestListenChecksPrivateDir(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv(agent.SocketEnv, "")
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", t.TempDir())
+	path := agent.DefaultSocketPath()
+	dir := filepath.Dir(path)
+	//其他用户可以访问的目录不能使用
+	if !assert.NoError(os.Mkdir(dir, 0755)) {
+		return
+	}
+	_, err := agent.Listen(path)
+	assert.Error(err)
+
+	assert.NoError(os.Chmod(dir, 0700))
+	listener, err := agent.Listen(path)
+	if assert.NoError(err) {
+		listener.Close()
+	}
+
+	//符号链接也不能使用
+	assert.NoError(os.RemoveAll(dir))
+	assert.NoError(os.Symlink(t.TempDir(), dir))
+	_, err = agent.Listen(path)
+	assert.Error(err)
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	secretkey "password_manager/service/secret_key"
+	"time"
+)
+
+var (
+	// ErrLocked agent 已经锁定
+	ErrLocked = errors.New("pm agent is locked, run 'pm unlock' first")
+	// ErrSetKeyUnsupported 无法通过 agent 重新生成密钥
+	ErrSetKeyUnsupported = errors.New("the secret key can't be reset through pm agent")
+)
+
+var _ secretkey.SecretKeyInterface = (*Client)(nil)
+
+// Client 通过 Unix socket 从 agent 获取密钥
+type Client struct {
+	socketPath string
+	timeout    time.Duration
+}
+
+// NewClient 创建连接到 socketPath 的客户端
+func NewClient(socketPath string) *Client {
+	return &Client{
+		socketPath: socketPath,
+		timeout:    connTimeout,
+	}
+}
+
+// Running agent 是否正在运行
+func (c *Client) Running() bool {
+	conn, err := net.DialTimeout("unix", c.socketPath, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// GetSecretKey 从 agent 获取密钥，agent 锁定时返回 ErrLocked
+func (c *Client) GetSecretKey() (string, error) {
+	resp, err := c.call(opGet)
+	if err != nil {
+		return "", err
+	}
+	if resp.Locked {
+		return "", ErrLocked
+	}
+	return resp.Key, nil
+}
+
+// SetSecretKey 密钥只能由 agent 从密钥文件中读取，这里直接返回错误
+func (c *Client) SetSecretKey() error {
+	return ErrSetKeyUnsupported
+}
+
+// Lock 让 agent 清除缓存的密钥
+func (c *Client) Lock() error {
+	_, err := c.call(opLock)
+	return err
+}
+
+// Unlock 让 agent 重新读取密钥
+// 密钥文件本来就可以被当前用户读取，因此解锁不需要凭据，锁定只是为了方便暂时清除内存中的密钥，不能防止同一用户的其他进程
+func (c *Client) Unlock() (Status, error) {
+	return c.status(opUnlock)
+}
+
+// Status 获取 agent 当前的状态
+func (c *Client) Status() (Status, error) {
+	return c.status(opStatus)
+}
+
+// status 发送请求并返回 agent 的状态
+func (c *Client) status(op string) (Status, error) {
+	resp, err := c.call(op)
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{Locked: resp.Locked, ExpiresAt: resp.ExpiresAt}, nil
+}
+
+// call 发送一个请求并读取结果
+func (c *Client) call(op string) (response, error) {
+	conn, err := net.DialTimeout("unix", c.socketPath, c.timeout)
+	if err != nil {
+		return response{}, errors.New("can't connect to pm agent: " + err.Error())
+	}
+	defer conn.Close()
+	if err := c.checkPeer(conn); err != nil {
+		return response{}, err
+	}
+	conn.SetDeadline(time.Now().Add(c.timeout))
+	if err := json.NewEncoder(conn).Encode(request{Op: op}); err != nil {
+		return response{}, err
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return response{}, err
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// checkPeer 确认 socket 另一端的 agent 属于当前用户，防止其他用户冒充 agent 返回密钥
+func (c *Client) checkPeer(conn net.Conn) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil
+	}
+	uid, err := peerUID(unixConn)
+	if errors.Is(err, errPeerCredUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return errors.New("can't verify pm agent: " + err.Error())
+	}
+	if uid != os.Getuid() {
+		return errors.New("pm agent on " + c.socketPath + " belongs to another user")
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package agent
+
+import "os"
+
+// fileOwner 当前平台无法获取文件所属的用户，只依靠文件的权限限制访问
+func fileOwner(info os.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package agent
+
+import "net"
+
+// peerUID 当前平台不支持获取对端身份，只依靠 socket 文件的权限限制访问
+func peerUID(conn *net.UnixConn) (int, error) {
+	return 0, errPeerCredUnsupported
+}
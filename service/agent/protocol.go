@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// SocketEnv 指定 agent socket 路径的环境变量
+	SocketEnv = "PM_AGENT_SOCK"
+
+	opGet    = "get"
+	opLock   = "lock"
+	opUnlock = "unlock"
+	opStatus = "status"
+)
+
+// request 客户端发送给 agent 的请求，每个连接只处理一个请求
+type request struct {
+	Op string `json:"op"`
+}
+
+// response agent 返回的结果
+type response struct {
+	Key       string    `json:"key,omitempty"`
+	Locked    bool      `json:"locked"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Status agent 当前的状态
+type Status struct {
+	// Locked 是否已经锁定
+	Locked bool
+	// ExpiresAt 密钥被清除的时间，锁定时为零值
+	ExpiresAt time.Time
+}
+
+// DefaultSocketPath 返回 agent socket 的默认路径
+// 优先使用环境变量 PM_AGENT_SOCK，其次是 XDG_RUNTIME_DIR，最后是临时目录下只属于当前用户的目录
+func DefaultSocketPath() string {
+	if path := os.Getenv(SocketEnv); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "pm-agent.sock")
+	}
+	return filepath.Join(privateDir(), "agent.sock")
+}
+
+// privateDir 临时目录下只属于当前用户的目录，其他用户可能提前创建同名的目录，使用前需要用 checkPrivateDir 检查
+func privateDir() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("pm-agent-%d", os.Getuid()))
+}
+
+// checkPrivateDir 检查目录是否只有当前用户可以访问，符号链接、属于其他用户或者权限不是 0700 时返回错误
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return errors.New(dir + " is a symlink")
+	}
+	if !info.IsDir() {
+		return errors.New(dir + " is not a directory")
+	}
+	if uid, ok := fileOwner(info); ok && uid != os.Getuid() {
+		return errors.New(dir + " belongs to another user")
+	}
+	if info.Mode().Perm() != 0700 {
+		return errors.New(dir + " must only be accessible by the current user (mode 0700)")
+	}
+	return nil
+}
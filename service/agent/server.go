@@ -0,0 +1,221 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	secretkey "password_manager/service/secret_key"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTTL 默认的密钥缓存时间
+	DefaultTTL = 15 * time.Minute
+	// connTimeout 单个连接的读写超时时间
+	connTimeout = 5 * time.Second
+)
+
+// errPeerCredUnsupported 当前平台无法获取对端进程的身份
+var errPeerCredUnsupported = errors.New("peer credentials are not supported on this platform")
+
+// Server 在内存中缓存密钥，并通过 Unix socket 响应同一用户的请求
+type Server struct {
+	logger *zap.Logger
+	// source 解锁时读取密钥的来源
+	source secretkey.SecretKeyInterface
+	// ttl 解锁后密钥保留的时间
+	ttl time.Duration
+	// uid 允许连接的用户id
+	uid int
+
+	mu        sync.Mutex
+	key       string
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// NewServer 创建 agent 服务，初始状态为锁定
+func NewServer(source secretkey.SecretKeyInterface, ttl time.Duration) *Server {
+	return &Server{
+		logger: zap.L(),
+		source: source,
+		ttl:    ttl,
+		uid:    os.Getuid(),
+	}
+}
+
+// Listen 在 path 上创建只有当前用户可以访问的 Unix socket
+// 如果已经有服务在该路径上运行则返回错误，只删除当前用户残留的 socket 文件，其他文件不会被删除
+func Listen(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if dir == privateDir() {
+		if err := checkPrivateDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// removeStaleSocket 删除 path 上残留的 socket 文件
+// path 不是 socket、属于其他用户或者仍然可以连接时返回错误
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return errors.New(path + " already exists and is not a socket")
+	}
+	if uid, ok := fileOwner(info); ok && uid != os.Getuid() {
+		return errors.New("socket " + path + " belongs to another user")
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return errors.New("another process is already listening on " + path)
+	}
+	return os.Remove(path)
+}
+
+// Unlock 从密钥来源读取密钥并缓存 ttl 时间
+func (srv *Server) Unlock() error {
+	key, err := srv.source.GetSecretKey()
+	if err != nil {
+		return err
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	expiresAt := time.Now().Add(srv.ttl)
+	srv.key = key
+	srv.expiresAt = expiresAt
+	if srv.timer != nil {
+		srv.timer.Stop()
+	}
+	srv.timer = time.AfterFunc(srv.ttl, func() {
+		srv.expire(expiresAt)
+	})
+	srv.logger.Info("agent unlocked", zap.Duration("ttl", srv.ttl))
+	return nil
+}
+
+// Lock 清除缓存的密钥
+func (srv *Server) Lock() {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.lockLocked()
+}
+
+// lockLocked 在持有锁的情况下清除密钥
+func (srv *Server) lockLocked() {
+	if srv.timer != nil {
+		srv.timer.Stop()
+		srv.timer = nil
+	}
+	if srv.key != "" {
+		srv.logger.Info("agent locked")
+	}
+	srv.key = ""
+	srv.expiresAt = time.Time{}
+}
+
+// expire 缓存时间到达后清除密钥，重新解锁后之前的计时不再生效
+func (srv *Server) expire(expiresAt time.Time) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.expiresAt.Equal(expiresAt) {
+		srv.lockLocked()
+	}
+}
+
+// Status 返回当前的状态
+func (srv *Server) Status() Status {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return Status{Locked: srv.key == "", ExpiresAt: srv.expiresAt}
+}
+
+// Serve 接受连接并处理请求，监听关闭后返回nil
+func (srv *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go srv.handle(conn)
+	}
+}
+
+// handle 处理单个连接
+func (srv *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connTimeout))
+	//检查对端进程是否属于当前用户
+	if unixConn, ok := conn.(*net.UnixConn); ok {
+		uid, err := peerUID(unixConn)
+		if err != nil && !errors.Is(err, errPeerCredUnsupported) {
+			srv.logger.Warn("failed to get peer credentials", zap.Error(err))
+			return
+		}
+		if err == nil && uid != srv.uid {
+			srv.logger.Warn("rejected connection from another user", zap.Int("uid", uid))
+			return
+		}
+	}
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		srv.logger.Warn("failed to decode request", zap.Error(err))
+		return
+	}
+	resp := srv.process(req)
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		srv.logger.Warn("failed to send response", zap.Error(err))
+	}
+}
+
+// process 根据请求的操作生成结果
+func (srv *Server) process(req request) response {
+	switch req.Op {
+	case opGet:
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if srv.key == "" {
+			return response{Locked: true}
+		}
+		return response{Key: srv.key, ExpiresAt: srv.expiresAt}
+	case opLock:
+		srv.Lock()
+	case opUnlock:
+		if err := srv.Unlock(); err != nil {
+			return response{Locked: true, Error: err.Error()}
+		}
+	case opStatus:
+	default:
+		return response{Error: "unknown operation: " + req.Op}
+	}
+	status := srv.Status()
+	return response{Locked: status.Locked, ExpiresAt: status.ExpiresAt}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/password"
+	"password_manager/service/strength"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Version 当前 API 的版本，所有路径都以 /v1 开头
+	Version = "v1"
+	// tokenLength 随机生成的访问令牌字节数
+	tokenLength = 32
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// Server 基于 PasswordService 和 DBKitImpl 的 HTTP API
+type Server struct {
+	logger *zap.Logger
+	srv    *password.PasswordService
+	kit    *dbfilekit.DBKitImpl
+	token  string
+	mux    *http.ServeMux
+	// writeMu 写操作和备份互斥，避免备份时复制到写了一半的数据库文件
+	writeMu sync.Mutex
+}
+
+// NewServer 创建 API 服务，所有接口都需要携带 token 作为 Bearer 令牌
+func NewServer(srv *password.PasswordService, kit *dbfilekit.DBKitImpl, token string) *Server {
+	server := newServer(srv, kit)
+	server.token = token
+	server.routes(server.auth)
+	return server
+}
+
+// NewHandler 创建不检查令牌的 API，由调用方自己完成身份验证
+func NewHandler(srv *password.PasswordService, kit *dbfilekit.DBKitImpl) *Server {
+	server := newServer(srv, kit)
+	server.routes(func(next http.HandlerFunc) http.Handler {
+		return next
+	})
+	return server
+}
+
+// newServer 创建还没有注册接口的服务
+func newServer(srv *password.PasswordService, kit *dbfilekit.DBKitImpl) *Server {
+	return &Server{
+		logger: zap.L(),
+		srv:    srv,
+		kit:    kit,
+		mux:    http.NewServeMux(),
+	}
+}
+
+// GenerateToken 生成随机的访问令牌
+func GenerateToken() (string, error) {
+	token := make([]byte, tokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// routes 注册所有接口，auth 用于包装需要验证身份的接口
+func (s *Server) routes(auth func(http.HandlerFunc) http.Handler) {
+	s.mux.HandleFunc("GET /v1/openapi.json", s.handleOpenAPI)
+	s.mux.Handle("GET /v1/passwords", auth(s.handleList))
+	s.mux.Handle("POST /v1/passwords", auth(s.handleCreate))
+	s.mux.Handle("GET /v1/passwords/{key}", auth(s.handleGet))
+	s.mux.Handle("PATCH /v1/passwords/{key}", auth(s.handleUpdate))
+	s.mux.Handle("DELETE /v1/passwords/{key}", auth(s.handleDelete))
+	s.mux.Handle("GET /v1/search", auth(s.handleSearch))
+	s.mux.Handle("POST /v1/backup", auth(s.handleBackup))
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	s.mux.ServeHTTP(w, r)
+}
+
+// auth 检查请求中的 Bearer 令牌
+func (s *Server) auth(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="pm"`)
+			writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// handleOpenAPI 返回 OpenAPI 描述，不需要令牌
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// writeJSON 以 JSON 格式返回结果
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError 返回错误信息
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// errorStatus 根据密码服务返回的错误选择状态码
+func errorStatus(err error) int {
+	var weakErr *strength.WeakPasswordError
+	if errors.As(err, &weakErr) {
+		return http.StatusUnprocessableEntity
+	}
+	switch {
+	case errors.Is(err, password.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, password.ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, password.ErrEmpty):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeServiceError 返回密码服务的错误
+func (s *Server) writeServiceError(w http.ResponseWriter, err error) {
+	status := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		s.logger.Error("api request failed", zap.Error(err))
+	}
+	writeError(w, status, err.Error())
+}
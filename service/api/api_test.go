@@ -0,0 +1,205 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"password_manager/service/api"
+	"password_manager/service/strength"
+	"password_manager/service/testvault"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testToken = "test-token"
+
+// newTestServer 使用临时密码库创建 API 服务
+func newTestServer(t *testing.T) (*httptest.Server, *testvault.Vault) {
+	vault := testvault.New(t)
+	server := httptest.NewServer(api.NewServer(vault.Srv, vault.Kit, testToken))
+	t.Cleanup(server.Close)
+	return server, vault
+}
+
+// do 发送带令牌的请求
+func do(t *testing.T, server *httptest.Server, method, path, body string) (*http.Response, []byte) {
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, data
+}
+
+func TestAuth(t *testing.T) {
+	assert := assert.New(t)
+	server, _ := newTestServer(t)
+
+	resp, err := server.Client().Get(server.URL + "/v1/passwords")
+	if assert.NoError(err) {
+		resp.Body.Close()
+		assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/passwords", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err = server.Client().Do(req)
+	if assert.NoError(err) {
+		resp.Body.Close()
+		assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	// OpenAPI 描述不需要令牌
+	resp, err = server.Client().Get(server.URL + "/v1/openapi.json")
+	if assert.NoError(err) {
+		var spec map[string]any
+		assert.NoError(json.NewDecoder(resp.Body).Decode(&spec))
+		resp.Body.Close()
+		assert.Equal(http.StatusOK, resp.StatusCode)
+		assert.Equal("3.0.3", spec["openapi"])
+	}
+}
+
+func TestPasswords(t *testing.T) {
+	assert := assert.New(t)
+	server, vault := newTestServer(t)
+
+	// 创建
+	resp, body := do(t, server, http.MethodPost, "/v1/passwords",
+		`{"key":"github","password":"tangerine-Orbit-42","platform":"github.com","username":"john","tags":["dev"]}`)
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+	assert.Equal("/v1/passwords/github", resp.Header.Get("Location"))
+	var entry api.Entry
+	assert.NoError(json.Unmarshal(body, &entry))
+	assert.Equal(api.Entry{Key: "github", Password: "tangerine-Orbit-42", Platform: "github.com", Username: "john", Tags: []string{"dev"}}, entry)
+
+	resp, _ = do(t, server, http.MethodPost, "/v1/passwords", `{"key":"mail","password":"velvet-Harbor-917","platform":"gmail"}`)
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+
+	// 重复的 key
+	resp, _ = do(t, server, http.MethodPost, "/v1/passwords", `{"key":"mail","password":"quartz-Meadow-26"}`)
+	assert.Equal(http.StatusConflict, resp.StatusCode)
+	// 缺少密码
+	resp, _ = do(t, server, http.MethodPost, "/v1/passwords", `{"key":"empty"}`)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+	// 未知字段
+	resp, _ = do(t, server, http.MethodPost, "/v1/passwords", `{"key":"x","password":"x","unknown":1}`)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	// 列表不包含密码
+	resp, body = do(t, server, http.MethodGet, "/v1/passwords", "")
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	var entries []api.Entry
+	assert.NoError(json.Unmarshal(body, &entries))
+	if assert.Len(entries, 2) {
+		assert.Equal("github", entries[0].Key)
+		assert.Equal("mail", entries[1].Key)
+		assert.Empty(entries[0].Password)
+	}
+
+	// 获取
+	resp, body = do(t, server, http.MethodGet, "/v1/passwords/mail", "")
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.NoError(json.Unmarshal(body, &entry))
+	assert.Equal("velvet-Harbor-917", entry.Password)
+	// 只有获取记录时记录使用，创建时返回的密码不算
+	access, err := vault.Srv.GetAccess("mail")
+	assert.NoError(err)
+	assert.Equal(1, access.Count)
+	resp, _ = do(t, server, http.MethodGet, "/v1/passwords/missing", "")
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+
+	// 更新附加信息
+	resp, body = do(t, server, http.MethodPatch, "/v1/passwords/mail", `{"url":"https://mail.google.com"}`)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	entry = api.Entry{}
+	assert.NoError(json.Unmarshal(body, &entry))
+	assert.Equal(api.Entry{Key: "mail", Password: "velvet-Harbor-917", Platform: "gmail", URL: "https://mail.google.com"}, entry)
+
+	// 更新密码并重命名
+	resp, body = do(t, server, http.MethodPatch, "/v1/passwords/mail", `{"key":"gmail","password":"copper-Lantern-58"}`)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	entry = api.Entry{}
+	assert.NoError(json.Unmarshal(body, &entry))
+	assert.Equal(api.Entry{Key: "gmail", Password: "copper-Lantern-58", Platform: "gmail", URL: "https://mail.google.com"}, entry)
+	resp, _ = do(t, server, http.MethodGet, "/v1/passwords/mail", "")
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(t, server, http.MethodPatch, "/v1/passwords/missing", `{"username":"x"}`)
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+	// 改名到已有的 key 时什么都不修改
+	resp, _ = do(t, server, http.MethodPatch, "/v1/passwords/gmail", `{"key":"github","username":"x"}`)
+	assert.Equal(http.StatusConflict, resp.StatusCode)
+	resp, body = do(t, server, http.MethodGet, "/v1/passwords/gmail", "")
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	entry = api.Entry{}
+	assert.NoError(json.Unmarshal(body, &entry))
+	assert.Empty(entry.Username)
+
+	// 搜索
+	resp, body = do(t, server, http.MethodGet, "/v1/search?q=joh", "")
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	var results []api.SearchResult
+	assert.NoError(json.Unmarshal(body, &results))
+	if assert.Len(results, 1) {
+		assert.Equal("github", results[0].Key)
+		assert.Equal("username", results[0].Field)
+		assert.Empty(results[0].Password)
+	}
+	resp, _ = do(t, server, http.MethodGet, "/v1/search", "")
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	// 备份
+	resp, _ = do(t, server, http.MethodPost, "/v1/backup", "")
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+	_, err = os.Stat(filepath.Join(vault.Dir, "data.backup.db"))
+	assert.NoError(err)
+
+	// 删除
+	resp, _ = do(t, server, http.MethodDelete, "/v1/passwords/github", "")
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+	resp, _ = do(t, server, http.MethodDelete, "/v1/passwords/github", "")
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	assert := assert.New(t)
+	server, vault := newTestServer(t)
+
+	// 默认不限制密码强度
+	resp, _ := do(t, server, http.MethodPost, "/v1/passwords", `{"key":"pin","password":"1234"}`)
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+	assert.NoError(strength.NewPolicyService(vault.DB).SetPolicy(strength.Policy{MinScore: strength.ScoreFair}))
+
+	// 不满足强度策略的密码
+	resp, body := do(t, server, http.MethodPost, "/v1/passwords", `{"key":"mail","password":"123456"}`)
+	assert.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Contains(string(body), "too weak")
+	resp, _ = do(t, server, http.MethodGet, "/v1/passwords/mail", "")
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+
+	// force 时仍然保存
+	resp, _ = do(t, server, http.MethodPost, "/v1/passwords", `{"key":"mail","password":"123456","force":true}`)
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+
+	resp, _ = do(t, server, http.MethodPatch, "/v1/passwords/mail", `{"password":"password"}`)
+	assert.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+	resp, _ = do(t, server, http.MethodPatch, "/v1/passwords/mail", `{"password":"password","force":true}`)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	// 不修改密码时不检查
+	resp, _ = do(t, server, http.MethodPatch, "/v1/passwords/mail", `{"username":"john"}`)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"password_manager/service/password"
+	"password_manager/service/search"
+)
+
+// maxBodySize 请求体的最大字节数
+const maxBodySize = 1 << 20
+
+// Entry 接口中的一条密码记录
+type Entry struct {
+	Key      string   `json:"key"`
+	Password string   `json:"password,omitempty"`
+	Platform string   `json:"platform"`
+	Username string   `json:"username,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// CreateRequest 创建请求，Force 为 true 时保存不满足强度策略的密码
+type CreateRequest struct {
+	Entry
+	Force bool `json:"force,omitempty"`
+}
+
+// UpdateRequest 更新请求，为空的字段保持不变，Force 为 true 时保存不满足强度策略的新密码
+type UpdateRequest struct {
+	Key      *string   `json:"key,omitempty"`
+	Password *string   `json:"password,omitempty"`
+	Platform *string   `json:"platform,omitempty"`
+	Username *string   `json:"username,omitempty"`
+	URL      *string   `json:"url,omitempty"`
+	Tags     *[]string `json:"tags,omitempty"`
+	Force    bool      `json:"force,omitempty"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Entry
+	Score int    `json:"score"`
+	Field string `json:"field"`
+}
+
+// errorResponse 错误信息
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// handleList 返回所有记录，不包含密码，也不解密任何密码
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	all, err := s.srv.GetAllEntries()
+	if err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	entries := make([]Entry, 0, len(all))
+	for _, data := range all {
+		entries = append(entries, toEntry(data.Key, data))
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleGet 返回指定 key 的记录，包含密码
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.getEntry(r.PathValue("key"))
+	if err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	s.srv.RecordAccess(entry.Key)
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handleCreate 创建新的记录
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	entry := req.Entry
+	if !req.Force && entry.Password != "" {
+		if _, err := s.srv.CheckStrength(entry.Password, entry.Key, entry.Platform, entry.Username); err != nil {
+			s.writeServiceError(w, err)
+			return
+		}
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	meta := password.Meta{Username: entry.Username, URL: entry.URL, Tags: entry.Tags}
+	if err := s.srv.SaveEntry(entry.Key, entry.Password, entry.Platform, meta); err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	created, err := s.getEntry(entry.Key)
+	if err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/"+Version+"/passwords/"+entry.Key)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// handleUpdate 更新记录的密码、平台、key 或附加信息
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	var req UpdateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	meta, err := s.srv.GetMeta(key)
+	if err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	update := password.EntryUpdate{Username: req.Username, URL: req.URL, Tags: req.Tags}
+	if req.Password != nil {
+		update.Password = *req.Password
+	}
+	if req.Platform != nil {
+		update.Platform = *req.Platform
+	}
+	if req.Key != nil && *req.Key != key {
+		update.Key = *req.Key
+	}
+	//先检查强度，密码和附加信息在同一个事务中修改，失败时都不修改
+	if !req.Force && update.Password != "" {
+		username := meta.Username
+		if update.Username != nil {
+			username = *update.Username
+		}
+		if _, err := s.srv.CheckStrength(update.Password, key, update.Key, update.Platform, username); err != nil {
+			s.writeServiceError(w, err)
+			return
+		}
+	}
+	result, err := s.srv.UpdateEntry(key, update)
+	if err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	updated, err := s.getEntry(result.Key)
+	if err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// handleDelete 删除记录
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.srv.DeletePassword(r.PathValue("key")); err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSearch 模糊搜索，结果不包含密码，也不解密任何密码
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "query parameter q is required")
+		return
+	}
+	all, err := s.srv.GetAllEntries()
+	if err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	byKey := make(map[string]password.PasswordData, len(all))
+	entries := make([]search.Entry, 0, len(all))
+	for _, data := range all {
+		byKey[data.Key] = data
+		entries = append(entries, search.Entry{
+			Key:      data.Key,
+			Platform: data.Platform,
+			Username: data.Username,
+			URL:      data.URL,
+			Tags:     data.Tags,
+		})
+	}
+	matches := search.Search(query, entries)
+	results := make([]SearchResult, 0, len(matches))
+	for _, match := range matches {
+		entry := toEntry(match.Entry.Key, byKey[match.Entry.Key])
+		results = append(results, SearchResult{Entry: entry, Score: match.Score, Field: match.Field})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleBackup 备份数据库
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.kit.BackupDB(); err != nil {
+		s.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getEntry 读取一条完整的记录
+func (s *Server) getEntry(key string) (Entry, error) {
+	pwd, platform, err := s.srv.GetPasswordWithKey(key)
+	if err != nil {
+		return Entry{}, err
+	}
+	meta, err := s.srv.GetMeta(key)
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{
+		Key:      key,
+		Password: pwd,
+		Platform: platform,
+		Username: meta.Username,
+		URL:      meta.URL,
+		Tags:     meta.Tags,
+	}, nil
+}
+
+// toEntry 将密码服务的数据转换为接口记录
+func toEntry(key string, data password.PasswordData) Entry {
+	return Entry{
+		Key:      key,
+		Password: data.Password,
+		Platform: data.Platform,
+		Username: data.Username,
+		URL:      data.URL,
+		Tags:     data.Tags,
+	}
+}
+
+// decodeBody 解析 JSON 请求体，失败时直接返回 400
+func decodeBody(w http.ResponseWriter, r *http.Request, value any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
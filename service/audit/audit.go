@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"password_manager/service/password"
+	"password_manager/service/search"
+	"password_manager/service/strength"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultMaxAge 超过这个时间没有修改的密码被认为过旧
+	DefaultMaxAge = 365 * 24 * time.Hour
+	// DefaultMaxDistance 编辑距离不超过这个值的两个密码被认为相似
+	DefaultMaxDistance = 2
+	// minSimilarLength 太短的密码不比较相似度，避免大量误报
+	minSimilarLength = 6
+)
+
+// Options 审计的配置
+type Options struct {
+	// MinScore 低于这个分数的密码被认为太弱
+	MinScore int
+	// MaxAge 超过这个时间没有修改的密码被认为过旧，0 表示不检查
+	MaxAge time.Duration
+	// MaxDistance 相似密码的最大编辑距离，0 表示不检查
+	MaxDistance int
+	// Now 当前时间，为零值时使用 time.Now
+	Now time.Time
+}
+
+// WeakEntry 强度不足的密码
+type WeakEntry struct {
+	Key      string   `json:"key"`
+	Score    int      `json:"score"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ReusedGroup 使用同一个密码的条目
+type ReusedGroup struct {
+	Keys []string `json:"keys"`
+}
+
+// SimilarPair 密码相似的两个条目
+type SimilarPair struct {
+	Key      string `json:"key"`
+	Other    string `json:"other"`
+	Distance int    `json:"distance"`
+}
+
+// OldEntry 很久没有修改的密码
+type OldEntry struct {
+	Key      string    `json:"key"`
+	Modified time.Time `json:"modified"`
+	AgeDays  int       `json:"age_days"`
+}
+
+// Report 审计结果，每一类都按 key 排序
+type Report struct {
+	Total           int           `json:"total"`
+	Weak            []WeakEntry   `json:"weak"`
+	Reused          []ReusedGroup `json:"reused"`
+	Similar         []SimilarPair `json:"similar"`
+	Old             []OldEntry    `json:"old"`
+	MissingPlatform []string      `json:"missing_platform"`
+	// UnknownAge 旧版本保存的条目没有修改时间，无法检查是否过旧
+	UnknownAge []string `json:"unknown_age"`
+}
+
+// Issues 发现的问题数量，UnknownAge 不算问题
+func (r Report) Issues() int {
+	return len(r.Weak) + len(r.Reused) + len(r.Similar) + len(r.Old) + len(r.MissingPlatform)
+}
+
+// secret 参与强度、重复和相似检查的一个密码
+type secret struct {
+	// name 报告中显示的名称，环境的密码为 EnvName 的结果
+	name     string
+	password string
+}
+
+// EnvName 报告中环境密码的名称，例如 db (env: prod)
+func EnvName(key, env string) string {
+	return key + " (env: " + env + ")"
+}
+
+// Run 对所有条目进行审计
+// 强度、重复和相似只检查保存密码的类型（普通密码和数据库）以及各个环境的密码，卡号、私钥和笔记不参与
+func Run(entries map[string]password.PasswordData, opts Options) Report {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	report := Report{
+		Total:           len(entries),
+		Weak:            []WeakEntry{},
+		Reused:          []ReusedGroup{},
+		Similar:         []SimilarPair{},
+		Old:             []OldEntry{},
+		MissingPlatform: []string{},
+		UnknownAge:      []string{},
+	}
+	var secrets []secret
+	byPassword := make(map[string][]string)
+	check := func(name, value string, userInputs ...string) {
+		secrets = append(secrets, secret{name: name, password: value})
+		byPassword[value] = append(byPassword[value], name)
+		result := strength.Estimate(value, userInputs...)
+		if result.Score < opts.MinScore {
+			report.Weak = append(report.Weak, WeakEntry{Key: name, Score: result.Score, Warnings: result.Warnings})
+		}
+	}
+	for _, key := range keys {
+		data := entries[key]
+		if data.Platform == "" {
+			report.MissingPlatform = append(report.MissingPlatform, key)
+		}
+		if opts.MaxAge > 0 {
+			switch {
+			case data.Modified.IsZero():
+				report.UnknownAge = append(report.UnknownAge, key)
+			case now.Sub(data.Modified) > opts.MaxAge:
+				report.Old = append(report.Old, OldEntry{
+					Key:      key,
+					Modified: data.Modified,
+					AgeDays:  int(now.Sub(data.Modified).Hours() / 24),
+				})
+			}
+		}
+		if isPassword(data) {
+			check(key, data.Password, key, data.Platform, data.Username)
+		}
+		envs := make([]string, 0, len(data.Envs))
+		for env := range data.Envs {
+			envs = append(envs, env)
+		}
+		sort.Strings(envs)
+		for _, env := range envs {
+			check(EnvName(key, env), data.Envs[env], key, env, data.Platform, data.Username)
+		}
+	}
+	for _, item := range secrets {
+		if group := byPassword[item.password]; len(group) > 1 && group[0] == item.name {
+			report.Reused = append(report.Reused, ReusedGroup{Keys: group})
+		}
+	}
+	if opts.MaxDistance > 0 {
+		report.Similar = similarPairs(secrets, opts.MaxDistance)
+	}
+	return report
+}
+
+// isPassword 条目的主字段是否是密码
+func isPassword(data password.PasswordData) bool {
+	kind, err := password.LookupKind(data.Type)
+	if err != nil {
+		return false
+	}
+	return kind.Primary == "password"
+}
+
+// similarPairs 找出编辑距离在 1 到 maxDistance 之间的密码，相同的密码已经算作重复
+func similarPairs(secrets []secret, maxDistance int) []SimilarPair {
+	pairs := []SimilarPair{}
+	for i, item := range secrets {
+		length := len([]rune(item.password))
+		if length < minSimilarLength {
+			continue
+		}
+		for _, other := range secrets[i+1:] {
+			otherLength := len([]rune(other.password))
+			if otherLength < minSimilarLength || abs(length-otherLength) > maxDistance {
+				continue
+			}
+			if distance := search.EditDistance(item.password, other.password); distance > 0 && distance <= maxDistance {
+				pairs = append(pairs, SimilarPair{Key: item.name, Other: other.name, Distance: distance})
+			}
+		}
+	}
+	return pairs
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package audit_test
+
+import (
+	"password_manager/service/audit"
+	"password_manager/service/password"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	strong := "kX9#mQ2$vLp-Rw7"
+	entries := map[string]password.PasswordData{
+		"github":    {Password: strong, Platform: "github", Modified: now.AddDate(0, 0, -10)},
+		"gitlab":    {Password: strong, Platform: "gitlab", Modified: now.AddDate(0, 0, -400)},
+		"mail":      {Password: "password", Modified: now},
+		"bank":      {Password: "Vt8&qPz!4mWx#2", Platform: "bank", Modified: now},
+		"bank_old":  {Password: "Vt8&qPz!4mWx#3", Platform: "bank", Modified: now},
+		"legacy":    {Password: "zR5%uLk@9sQe!7", Platform: "old"},
+		"visa":      {Password: "4111111111111111", Platform: "bank", Type: password.TypeCard, Modified: now},
+		"recovery":  {Password: "password", Platform: "github", Type: password.TypeNote, Modified: now},
+		"db_prod":   {Password: "postgres", Platform: "aws", Type: password.TypeDatabase, Modified: now},
+		"db_prod_2": {Password: "postgres", Platform: "aws", Type: password.TypeDatabase, Modified: now},
+	}
+	report := audit.Run(entries, audit.Options{MinScore: 3, MaxAge: 90 * 24 * time.Hour, MaxDistance: 2, Now: now})
+	assert.Equal(10, report.Total)
+	var weak []string
+	for _, entry := range report.Weak {
+		weak = append(weak, entry.Key)
+	}
+	assert.Equal([]string{"db_prod", "db_prod_2", "mail"}, weak)
+	assert.NotEmpty(report.Weak[2].Warnings)
+	assert.Equal([]audit.ReusedGroup{{Keys: []string{"db_prod", "db_prod_2"}}, {Keys: []string{"github", "gitlab"}}}, report.Reused)
+	assert.Equal([]audit.SimilarPair{{Key: "bank", Other: "bank_old", Distance: 1}}, report.Similar)
+	assert.Equal([]audit.OldEntry{{Key: "gitlab", Modified: now.AddDate(0, 0, -400), AgeDays: 400}}, report.Old)
+	assert.Equal([]string{"mail"}, report.MissingPlatform)
+	assert.Equal([]string{"legacy"}, report.UnknownAge)
+	assert.Equal(8, report.Issues())
+
+	//关闭过旧和相似的检查
+	report = audit.Run(entries, audit.Options{})
+	assert.Empty(report.Weak)
+	assert.Empty(report.Old)
+	assert.Empty(report.Similar)
+	assert.Empty(report.UnknownAge)
+	assert.Equal(3, report.Issues())
+}
+
+func TestRunEnvs(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	strong := "kX9#mQ2$vLp-Rw7"
+	entries := map[string]password.PasswordData{
+		"db": {Password: "Vt8&qPz!4mWx#2", Platform: "aws", Modified: now, Envs: map[string]string{
+			"dev":     "password",
+			"prod":    strong,
+			"staging": "Vt8&qPz!4mWx#3",
+		}},
+		"github": {Password: strong, Platform: "github", Modified: now},
+	}
+	report := audit.Run(entries, audit.Options{MinScore: 3, MaxDistance: 2, Now: now})
+	assert.Equal(2, report.Total)
+	if assert.Len(report.Weak, 1) {
+		assert.Equal(audit.EnvName("db", "dev"), report.Weak[0].Key)
+	}
+	assert.Equal([]audit.ReusedGroup{{Keys: []string{"db (env: prod)", "github"}}}, report.Reused)
+	assert.Equal([]audit.SimilarPair{{Key: "db", Other: "db (env: staging)", Distance: 1}}, report.Similar)
+}
@@ -0,0 +1,118 @@
+package breach
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RecordSize 二进制哈希库中每条记录的长度：20 字节 SHA-1 和 4 字节大端序的出现次数
+const RecordSize = sha1Size + 4
+
+const sha1Size = 20
+
+// BinarySource 按哈希排序的二进制文件，使用 ReadAt 二分查找，不需要把文件读入内存
+type BinarySource struct {
+	file    *os.File
+	records int64
+}
+
+// OpenBinarySource 打开二进制哈希库
+func OpenBinarySource(path string) (*BinarySource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size()%RecordSize != 0 {
+		file.Close()
+		return nil, errors.New(path + " is not a binary hash file, its size must be a multiple of " + strconv.Itoa(RecordSize))
+	}
+	return &BinarySource{file: file, records: info.Size() / RecordSize}, nil
+}
+
+// Close 关闭文件
+func (src *BinarySource) Close() error {
+	return src.file.Close()
+}
+
+// Lookup 二分查找哈希
+func (src *BinarySource) Lookup(hash string) (int, error) {
+	hash = strings.ToUpper(hash)
+	if err := validHash(hash); err != nil {
+		return 0, err
+	}
+	target, _ := hex.DecodeString(hash)
+	record := make([]byte, RecordSize)
+	var readErr error
+	i := sort.Search(int(src.records), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+		if _, err := src.file.ReadAt(record, int64(i)*RecordSize); err != nil {
+			readErr = err
+			return true
+		}
+		return bytes.Compare(record[:sha1Size], target) >= 0
+	})
+	if readErr != nil {
+		return 0, readErr
+	}
+	if int64(i) == src.records {
+		return 0, nil
+	}
+	if _, err := src.file.ReadAt(record, int64(i)*RecordSize); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(record[:sha1Size], target) {
+		return 0, nil
+	}
+	return int(binary.BigEndian.Uint32(record[sha1Size:])), nil
+}
+
+// WriteBinary 把 HASH:COUNT 格式的文本转换为二进制哈希库，输入必须已经按哈希排序
+func WriteBinary(w io.Writer, r io.Reader) (int, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	record := make([]byte, RecordSize)
+	var previous []byte
+	written := 0
+	for _, line := range splitLines(string(content)) {
+		hashText, countText, _ := strings.Cut(line, ":")
+		hashText = strings.ToUpper(strings.TrimSpace(hashText))
+		if err := validHash(hashText); err != nil {
+			return written, err
+		}
+		count := 1
+		if countText != "" {
+			count, err = strconv.Atoi(strings.TrimSpace(countText))
+			if err != nil {
+				return written, errors.New("invalid line: " + line)
+			}
+		}
+		hash, _ := hex.DecodeString(hashText)
+		if previous != nil && bytes.Compare(previous, hash) >= 0 {
+			return written, errors.New("hashes are not sorted at " + hashText)
+		}
+		previous = hash
+		copy(record, hash)
+		binary.BigEndian.PutUint32(record[sha1Size:], uint32(count))
+		if _, err := w.Write(record); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
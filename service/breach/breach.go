@@ -0,0 +1,135 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"password_manager/service/password"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PrefixLength 范围文件使用的哈希前缀长度，与 Pwned Passwords 相同
+	PrefixLength = 5
+	// hashLength SHA-1 十六进制的长度
+	hashLength = 40
+)
+
+// Source 泄露密码的哈希库，返回哈希出现的次数，没有出现时为 0
+type Source interface {
+	Lookup(hash string) (int, error)
+}
+
+// Result 泄露的条目
+type Result struct {
+	Key string `json:"key"`
+	// Env 泄露的是某个环境的密码，默认密码为空
+	Env string `json:"env,omitempty"`
+	// Count 在泄露数据中出现的次数
+	Count int `json:"count"`
+}
+
+// Hash 返回密码 SHA-1 的大写十六进制
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Open 根据位置打开哈希库：http(s) 地址使用范围接口，目录使用前缀范围文件，普通文件作为排好序的二进制文件
+func Open(location string) (Source, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return NewHTTPSource(location, nil), nil
+	}
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return NewDirSource(location), nil
+	}
+	return OpenBinarySource(location)
+}
+
+// Check 检查所有条目的密码和各个环境的密码，结果按 key 排序
+// 只检查保存密码的类型，卡号、私钥和笔记不参与
+func Check(source Source, entries map[string]password.PasswordData) ([]Result, error) {
+	results := []Result{}
+	for key, data := range entries {
+		kind, err := password.LookupKind(data.Type)
+		if err != nil || kind.Primary != "password" {
+			continue
+		}
+		values := map[string]string{"": data.Password}
+		for env, value := range data.Envs {
+			values[env] = value
+		}
+		for env, value := range values {
+			count, err := source.Lookup(Hash(value))
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				results = append(results, Result{Key: key, Env: env, Count: count})
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Key != results[j].Key {
+			return results[i].Key < results[j].Key
+		}
+		return results[i].Env < results[j].Env
+	})
+	return results, nil
+}
+
+// validHash 检查哈希格式
+func validHash(hash string) error {
+	if len(hash) != hashLength {
+		return errors.New("invalid SHA-1 hash " + hash)
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return errors.New("invalid SHA-1 hash " + hash)
+	}
+	return nil
+}
+
+// searchRange 在排好序的 SUFFIX:COUNT 行中二分查找后缀
+func searchRange(lines []string, suffix string) (int, error) {
+	i := sort.Search(len(lines), func(i int) bool {
+		return strings.ToUpper(lineSuffix(lines[i])) >= suffix
+	})
+	if i == len(lines) || strings.ToUpper(lineSuffix(lines[i])) != suffix {
+		return 0, nil
+	}
+	_, countText, ok := strings.Cut(lines[i], ":")
+	if !ok {
+		return 1, nil
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countText))
+	if err != nil {
+		return 0, errors.New("invalid line in range file: " + lines[i])
+	}
+	return count, nil
+}
+
+// lineSuffix 范围文件一行中的哈希后缀
+func lineSuffix(line string) string {
+	suffix, _, _ := strings.Cut(line, ":")
+	return strings.TrimSpace(suffix)
+}
+
+// splitLines 拆分范围文件，忽略空行和用于填充的 0 次记录
+func splitLines(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, ":0") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,192 @@
+package breach_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"password_manager/service/breach"
+	"password_manager/service/password"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// corpus 测试用的泄露密码和出现次数
+var corpus = map[string]int{
+	"password":  9659365,
+	"123456":    37359195,
+	"hunter2":   17043,
+	"qwerty123": 621679,
+}
+
+// rangeFiles 按前缀分组生成范围文件的内容，每个文件都加入一行用于填充的 0 次记录
+func rangeFiles() map[string]string {
+	groups := make(map[string][]string)
+	for pwd, count := range corpus {
+		hash := breach.Hash(pwd)
+		groups[hash[:5]] = append(groups[hash[:5]], hash[5:]+":"+strconv.Itoa(count))
+	}
+	files := make(map[string]string)
+	for prefix, lines := range groups {
+		lines = append(lines, "0000000000000000000000000000000000A:0", "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:3")
+		sort.Strings(lines)
+		files[prefix] = strings.Join(lines, "\r\n")
+	}
+	return files
+}
+
+// entries 测试用的条目
+func entries() map[string]password.PasswordData {
+	return map[string]password.PasswordData{
+		"github":  {Password: "password"},
+		"gitlab":  {Password: "kX9#mQ2$vLp-Rw7"},
+		"db":      {Password: "Vt8&qPz!4mWx#2", Type: password.TypeDatabase, Envs: map[string]string{"dev": "hunter2", "prod": "zR5%uLk@9sQe!7"}},
+		"notes":   {Password: "123456", Type: password.TypeNote},
+		"twitter": {Password: "qwerty123", Platform: "twitter"},
+	}
+}
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", breach.Hash("password"))
+}
+
+func TestDirSource(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for prefix, content := range rangeFiles() {
+		assert.NoError(os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(content), 0600))
+	}
+	source, err := breach.Open(dir)
+	assert.NoError(err)
+	count, err := source.Lookup(breach.Hash("password"))
+	assert.NoError(err)
+	assert.Equal(9659365, count)
+	count, err = source.Lookup(strings.ToLower(breach.Hash("hunter2")))
+	assert.NoError(err)
+	assert.Equal(17043, count)
+	//同一个前缀下不存在的后缀
+	count, err = source.Lookup(breach.Hash("password")[:5] + "0000000000000000000000000000000000A")
+	assert.NoError(err)
+	assert.Equal(0, count)
+	//范围文件缺失时报错
+	_, err = source.Lookup(breach.Hash("kX9#mQ2$vLp-Rw7"))
+	assert.Error(err)
+	_, err = source.Lookup("not a hash")
+	assert.Error(err)
+
+	//补全所有条目需要的范围文件后检查
+	for _, data := range entries() {
+		for _, value := range append([]string{data.Password}, "zR5%uLk@9sQe!7", "Vt8&qPz!4mWx#2") {
+			path := filepath.Join(dir, breach.Hash(value)[:5]+".txt")
+			if _, err := os.Stat(path); err != nil {
+				assert.NoError(os.WriteFile(path, []byte("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:1\n"), 0600))
+			}
+		}
+	}
+	results, err := breach.Check(source, entries())
+	assert.NoError(err)
+	assert.Equal([]breach.Result{
+		{Key: "db", Env: "dev", Count: 17043},
+		{Key: "github", Count: 9659365},
+		{Key: "twitter", Count: 621679},
+	}, results)
+}
+
+func TestBinarySource(t *testing.T) {
+	assert := assert.New(t)
+	var lines []string
+	for pwd, count := range corpus {
+		lines = append(lines, breach.Hash(pwd)+":"+strconv.Itoa(count))
+	}
+	sort.Strings(lines)
+	var buf bytes.Buffer
+	written, err := breach.WriteBinary(&buf, strings.NewReader(strings.Join(lines, "\n")))
+	assert.NoError(err)
+	assert.Equal(len(corpus), written)
+	path := filepath.Join(t.TempDir(), "pwned.bin")
+	assert.NoError(os.WriteFile(path, buf.Bytes(), 0600))
+
+	source, err := breach.Open(path)
+	assert.NoError(err)
+	defer source.(*breach.BinarySource).Close()
+	for pwd, count := range corpus {
+		found, err := source.Lookup(breach.Hash(pwd))
+		assert.NoError(err)
+		assert.Equal(count, found, pwd)
+	}
+	//比所有记录都小和都大的哈希
+	for _, hash := range []string{strings.Repeat("0", 40), strings.Repeat("F", 40), breach.Hash("kX9#mQ2$vLp-Rw7")} {
+		found, err := source.Lookup(hash)
+		assert.NoError(err)
+		assert.Equal(0, found)
+	}
+	results, err := breach.Check(source, entries())
+	assert.NoError(err)
+	assert.Len(results, 3)
+
+	//没有排序的输入和长度不对的文件
+	_, err = breach.WriteBinary(&bytes.Buffer{}, strings.NewReader(lines[1]+"\n"+lines[0]))
+	assert.Error(err)
+	badPath := filepath.Join(t.TempDir(), "bad.bin")
+	assert.NoError(os.WriteFile(badPath, []byte("short"), 0600))
+	_, err = breach.Open(badPath)
+	assert.Error(err)
+}
+
+func TestHTTPSource(t *testing.T) {
+	assert := assert.New(t)
+	files := rangeFiles()
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix, ok := strings.CutPrefix(r.URL.Path, "/range/")
+		if !ok || len(prefix) != 5 {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		requested = append(requested, prefix)
+		mu.Unlock()
+		assert.Equal("true", r.Header.Get("Add-Padding"))
+		content, ok := files[prefix]
+		if !ok {
+			content = "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0"
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	source, err := breach.Open(server.URL + "/")
+	assert.NoError(err)
+	results, err := breach.Check(source, entries())
+	assert.NoError(err)
+	assert.Equal([]breach.Result{
+		{Key: "db", Env: "dev", Count: 17043},
+		{Key: "github", Count: 9659365},
+		{Key: "twitter", Count: 621679},
+	}, results)
+	//只发送前缀，同一个前缀只请求一次
+	seen := map[string]bool{}
+	for _, prefix := range requested {
+		assert.False(seen[prefix], prefix)
+		seen[prefix] = true
+	}
+	count, err := source.Lookup(breach.Hash("password"))
+	assert.NoError(err)
+	assert.Equal(9659365, count)
+	assert.Len(requested, len(seen))
+
+	//服务端出错时返回错误
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	_, err = breach.NewHTTPSource(failing.URL, nil).Lookup(breach.Hash("password"))
+	assert.Error(err)
+}
@@ -0,0 +1,38 @@
+package breach
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirSource 保存前缀范围文件的目录，每个文件以哈希的前 5 位命名（可以带 .txt），内容为按后缀排序的 SUFFIX:COUNT
+type DirSource struct {
+	dir string
+}
+
+// NewDirSource 创建目录哈希库
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// Lookup 读取前缀对应的范围文件并二分查找后缀，范围文件不存在时返回错误，避免把不完整的库当作没有泄露
+func (src *DirSource) Lookup(hash string) (int, error) {
+	hash = strings.ToUpper(hash)
+	if err := validHash(hash); err != nil {
+		return 0, err
+	}
+	prefix := hash[:PrefixLength]
+	for _, name := range []string{prefix, prefix + ".txt", strings.ToLower(prefix), strings.ToLower(prefix) + ".txt"} {
+		content, err := os.ReadFile(filepath.Join(src.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return searchRange(splitLines(string(content)), hash[PrefixLength:])
+	}
+	return 0, errors.New("range file " + prefix + " not found in " + src.dir)
+}
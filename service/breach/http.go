@@ -0,0 +1,84 @@
+package breach
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRangeSize 单个范围响应的最大字节数
+const maxRangeSize = 4 << 20
+
+// HTTPSource Pwned Passwords 格式的范围接口，只发送哈希的前 5 位（k-anonymity）
+// 请求地址为 <base>/range/<PREFIX>，返回按后缀排序的 SUFFIX:COUNT
+type HTTPSource struct {
+	baseURL string
+	client  *http.Client
+
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+// NewHTTPSource 创建范围接口哈希库，client 为空时使用 10 秒超时的默认客户端
+func NewHTTPSource(baseURL string, client *http.Client) *HTTPSource {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  client,
+		cache:   make(map[string][]string),
+	}
+}
+
+// Lookup 请求前缀对应的范围并二分查找后缀，同一个前缀只请求一次
+func (src *HTTPSource) Lookup(hash string) (int, error) {
+	hash = strings.ToUpper(hash)
+	if err := validHash(hash); err != nil {
+		return 0, err
+	}
+	prefix := hash[:PrefixLength]
+	src.mu.Lock()
+	lines, ok := src.cache[prefix]
+	src.mu.Unlock()
+	if !ok {
+		var err error
+		lines, err = src.fetch(prefix)
+		if err != nil {
+			return 0, err
+		}
+		src.mu.Lock()
+		src.cache[prefix] = lines
+		src.mu.Unlock()
+	}
+	return searchRange(lines, hash[PrefixLength:])
+}
+
+// fetch 请求一个前缀的范围，要求填充响应以隐藏结果的数量
+func (src *HTTPSource) fetch(prefix string) ([]string, error) {
+	req, err := http.NewRequest(http.MethodGet, src.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "pm-breach-check")
+	resp, err := src.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("range request for " + prefix + " failed: " + resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRangeSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxRangeSize {
+		return nil, errors.New("range response for " + prefix + " is too large")
+	}
+	return splitLines(string(body)), nil
+}
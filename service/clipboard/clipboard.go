@@ -0,0 +1,34 @@
+package clipboard
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands 各平台可用的剪贴板命令，按顺序尝试
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// Copy 调用系统的剪贴板命令复制文本
+func Copy(text string) error {
+	for _, command := range clipboardCommands {
+		if runtime.GOOS != "darwin" && command[0] == "pbcopy" {
+			continue
+		}
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errors.New("no clipboard command found")
+}
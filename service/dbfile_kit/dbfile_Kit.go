@@ -21,6 +21,16 @@ import (
 const (
 	PasswordBucketName    = "passwords"
 	PlatformLenBucketName = "platformsLen"
+	MetaBucketName        = "meta"
+	MasterBucketName      = "master"
+	EnvBucketName         = "envs"
+	AttachmentBucketName  = "attachments"
+	FieldBucketName       = "fields"
+	KindBucketName        = "kinds"
+	SettingsBucketName    = "settings"
+	ModifiedBucketName    = "modified"
+	CreatedBucketName     = "created"
+	AccessBucketName      = "access"
 	FileDBName            = "data.db"
 	BackupDBName          = "data.backup.db"
 	timeStampKey          = "time-stamp"
@@ -205,6 +215,16 @@ func (srv *DBKitImpl) InitFromBackupFile() error {
 		srv.logger.Error("create platformLen bucket fail:", zap.Error(err))
 		return err
 	}
+	// 确保数据库打开后创建bucket
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(MetaBucketName))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		srv.logger.Error("create meta bucket fail:", zap.Error(err))
+		return err
+	}
 	srv.db = db
 	return nil
 
@@ -248,6 +268,16 @@ func (srv *DBKitImpl) initDB(dbFile string) (*bbolt.DB, error) {
 		srv.logger.Error("create platformLen bucket fail:", zap.Error(err))
 		return nil, err
 	}
+	// 确保数据库打开后创建bucket
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(MetaBucketName))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		srv.logger.Error("create meta bucket fail:", zap.Error(err))
+		return nil, err
+	}
 	return db, nil
 }
 
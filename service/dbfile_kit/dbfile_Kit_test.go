@@ -66,7 +66,7 @@ func TestBackup(t *testing.T) {
 		num1 := strconv.Itoa(randObj.Intn(1000))
 		num2 := strconv.Itoa(randObj.Intn(1000))
 
-		if err := passwordInstance.SavePassword("test"+num1, "test"+num2); err != nil {
+		if err := passwordInstance.SavePassword("test"+num1, "test"+num2, "google"); err != nil {
 			t.Log(err.Error())
 			return
 		}
@@ -77,7 +77,7 @@ func TestBackup(t *testing.T) {
 		t.Log(err.Error())
 		return
 	}
-	t.Logf("main db:%s", values)
+	t.Logf("main db:%v", values)
 
 	//备份
 	if err := dbfileKitInstance.BackupDB(); err != nil {
@@ -103,7 +103,7 @@ func TestBackup(t *testing.T) {
 		t.Log(err.Error())
 		return
 	}
-	t.Logf("backup db:%s", backupValues)
+	t.Logf("backup db:%v", backupValues)
 	if !assert.Equal(backupValues, values) {
 		t.Error("backup db is not equal to main db")
 		return
@@ -0,0 +1,257 @@
+package dockercredential
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"password_manager/service/password"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Platform 保存镜像仓库凭据时使用的平台
+	Platform = "docker-registry"
+	// Tag 镜像仓库凭据的标签
+	Tag = "docker"
+
+	ActionStore   = "store"
+	ActionGet     = "get"
+	ActionErase   = "erase"
+	ActionList    = "list"
+	ActionVersion = "version"
+
+	// Version 输出给 docker 的版本信息
+	Version = "pm docker credential helper 1.0"
+)
+
+var (
+	// ErrCredentialsNotFound docker 通过这段文字判断凭据不存在
+	ErrCredentialsNotFound = errors.New("credentials not found in native keychain")
+	// ErrMissingServerURL 缺少镜像仓库地址
+	ErrMissingServerURL = errors.New("no credentials server URL")
+	// ErrMissingUsername 缺少用户名
+	ErrMissingUsername = errors.New("no credentials username")
+)
+
+// Credentials docker 传入和读取的凭据
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// Helper 将镜像仓库的凭据保存为平台为 docker-registry 的记录，仓库地址保存在 URL 中
+type Helper struct {
+	logger *zap.Logger
+	srv    *password.PasswordService
+}
+
+// NewHelper 创建 docker credential helper
+func NewHelper(srv *password.PasswordService) *Helper {
+	return &Helper{
+		logger: zap.L(),
+		srv:    srv,
+	}
+}
+
+// Serve 按 docker-credential-helpers 协议执行 action
+// 出错时返回的错误需要由调用方输出到标准输出并以状态码 1 退出
+func (h *Helper) Serve(action string, in io.Reader, out io.Writer) error {
+	switch action {
+	case ActionStore:
+		var c Credentials
+		if err := json.NewDecoder(in).Decode(&c); err != nil {
+			return err
+		}
+		return h.Add(c)
+	case ActionGet:
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		username, secret, err := h.Get(serverURL)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(out).Encode(Credentials{ServerURL: serverURL, Username: username, Secret: secret})
+	case ActionErase:
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		return h.Delete(serverURL)
+	case ActionList:
+		list, err := h.List()
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(out).Encode(list)
+	case ActionVersion:
+		_, err := fmt.Fprintln(out, Version)
+		return err
+	default:
+		return errors.New("unknown credential action `" + action + "`")
+	}
+}
+
+// Add 保存凭据，仓库已经有凭据时更新用户名和密码
+func (h *Helper) Add(c Credentials) error {
+	if strings.TrimSpace(c.ServerURL) == "" {
+		return ErrMissingServerURL
+	}
+	if strings.TrimSpace(c.Username) == "" {
+		return ErrMissingUsername
+	}
+	key, data, ok, err := h.find(c.ServerURL)
+	if err != nil {
+		return err
+	}
+	//新的密码与手动保存的密码一样需要满足强度策略
+	if !ok || data.Password != c.Secret {
+		if _, err := h.srv.CheckStrength(c.Secret, c.ServerURL, c.Username); err != nil {
+			return err
+		}
+	}
+	if ok {
+		if data.Password != c.Secret {
+			if _, err := h.srv.UpdatePassword(key, c.Secret, "", ""); err != nil {
+				return err
+			}
+		}
+		meta, err := h.srv.GetMeta(key)
+		if err != nil {
+			return err
+		}
+		meta.Username = c.Username
+		return h.srv.SetMeta(key, meta)
+	}
+	key, err = h.newKey(c.ServerURL)
+	if err != nil {
+		return err
+	}
+	if err := h.srv.SavePassword(key, c.Secret, Platform); err != nil {
+		return err
+	}
+	h.logger.Info("docker credentials stored", zap.String("server", c.ServerURL))
+	return h.srv.SetMeta(key, password.Meta{Username: c.Username, URL: c.ServerURL, Tags: []string{Tag}})
+}
+
+// Get 返回仓库的用户名和密码
+func (h *Helper) Get(serverURL string) (string, string, error) {
+	if serverURL == "" {
+		return "", "", ErrMissingServerURL
+	}
+	key, data, ok, err := h.find(serverURL)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return "", "", ErrCredentialsNotFound
+	}
+	h.srv.RecordAccess(key)
+	return data.Username, data.Password, nil
+}
+
+// Delete 删除仓库的凭据
+func (h *Helper) Delete(serverURL string) error {
+	if serverURL == "" {
+		return ErrMissingServerURL
+	}
+	key, _, ok, err := h.find(serverURL)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCredentialsNotFound
+	}
+	h.logger.Info("docker credentials erased", zap.String("server", serverURL))
+	return h.srv.DeletePassword(key)
+}
+
+// List 返回所有仓库地址和对应的用户名
+func (h *Helper) List() (map[string]string, error) {
+	all, err := h.srv.GetAllPasswords()
+	if err != nil {
+		return nil, err
+	}
+	list := make(map[string]string)
+	for _, data := range all {
+		if isRegistryEntry(data) {
+			list[data.URL] = data.Username
+		}
+	}
+	return list, nil
+}
+
+// find 查找仓库地址对应的记录，多条匹配时按 key 排序取第一条
+func (h *Helper) find(serverURL string) (string, password.PasswordData, bool, error) {
+	all, err := h.srv.GetAllPasswords()
+	if err != nil {
+		return "", password.PasswordData{}, false, err
+	}
+	var keys []string
+	for key, data := range all {
+		if isRegistryEntry(data) && data.URL == serverURL {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return "", password.PasswordData{}, false, nil
+	}
+	sort.Strings(keys)
+	return keys[0], all[keys[0]], true, nil
+}
+
+// newKey 为新的凭据生成不重复的 key，格式为 docker:仓库地址
+func (h *Helper) newKey(serverURL string) (string, error) {
+	keys, err := h.srv.GetAllKeys()
+	if err != nil {
+		return "", err
+	}
+	existing := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		existing[key] = true
+	}
+	base := "docker:" + serverURL
+	key := base
+	for i := 2; existing[key]; i++ {
+		key = base + "-" + strconv.Itoa(i)
+	}
+	return key, nil
+}
+
+// isRegistryEntry 是否是镜像仓库的凭据：平台为 docker-registry 或带有 docker 标签，并且保存了仓库地址
+func isRegistryEntry(data password.PasswordData) bool {
+	if data.URL == "" {
+		return false
+	}
+	if data.Platform == Platform {
+		return true
+	}
+	for _, tag := range data.Tags {
+		if tag == Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// readServerURL 读取 get 和 erase 传入的仓库地址
+func readServerURL(in io.Reader) (string, error) {
+	reader := bufio.NewReader(in)
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	serverURL := strings.TrimSpace(line)
+	if serverURL == "" {
+		return "", ErrMissingServerURL
+	}
+	return serverURL, nil
+}
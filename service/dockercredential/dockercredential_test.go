@@ -0,0 +1,173 @@
+package dockercredential_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"password_manager/service/dockercredential"
+	"password_manager/service/password"
+	"password_manager/service/strength"
+	"password_manager/service/testvault"
+	"strings"
+	"testing"
+
+	"github.com/gookit/color"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain 设置了 PM_TEST_DOCKER_HELPER 时像 docker-credential-pm 一样运行：
+// 错误输出到标准输出并以状态码 1 退出
+func TestMain(m *testing.M) {
+	if dir := os.Getenv("PM_TEST_DOCKER_HELPER"); dir != "" {
+		color.SetOutput(os.Stderr)
+		vault, err := testvault.Open(dir)
+		if err == nil {
+			err = dockercredential.NewHelper(vault.Srv).Serve(os.Args[len(os.Args)-1], os.Stdin, os.Stdout)
+			vault.Close()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stdout, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// program 与 docker 客户端一样执行 helper：action 作为参数，数据写入标准输入
+type program struct {
+	t   *testing.T
+	dir string
+}
+
+func (p program) run(action, input string) (string, error) {
+	stdout, _, err := p.runWithStderr(action, input)
+	return stdout, err
+}
+
+// runWithStderr 与 run 相同，同时返回 helper 的标准错误
+func (p program) runWithStderr(action, input string) (string, string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	cmd := exec.Command(exe, action)
+	cmd.Env = append(os.Environ(), "PM_TEST_DOCKER_HELPER="+p.dir)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestProtocol(t *testing.T) {
+	assert := assert.New(t)
+	// 先创建数据库和密钥，关闭后 helper 进程才能打开
+	vault := testvault.New(t)
+	vault.Close()
+	p := program{t: t, dir: vault.Dir}
+
+	// 不存在的凭据
+	out, err := p.run("get", "https://index.docker.io/v1/\n")
+	assert.Error(err)
+	assert.Equal(dockercredential.ErrCredentialsNotFound.Error()+"\n", out)
+
+	// 保存和读取
+	out, err = p.run("store", `{"ServerURL":"https://index.docker.io/v1/","Username":"john","Secret":"token-1"}`)
+	assert.NoError(err)
+	assert.Empty(out)
+	out, err = p.run("store", `{"ServerURL":"registry.example.com","Username":"<token>","Secret":"token-2"}`)
+	assert.NoError(err)
+
+	out, err = p.run("get", "https://index.docker.io/v1/\n")
+	assert.NoError(err)
+	var c dockercredential.Credentials
+	assert.NoError(json.Unmarshal([]byte(out), &c))
+	assert.Equal(dockercredential.Credentials{ServerURL: "https://index.docker.io/v1/", Username: "john", Secret: "token-1"}, c)
+
+	// 再次保存时覆盖，docker login 会显示 helper 的标准错误，不能包含新旧密码
+	_, stderr, err := p.runWithStderr("store", `{"ServerURL":"https://index.docker.io/v1/","Username":"jane","Secret":"token-3"}`)
+	assert.NoError(err)
+	assert.NotContains(stderr, "token-")
+	out, _ = p.run("get", "https://index.docker.io/v1/")
+	assert.NoError(json.Unmarshal([]byte(out), &c))
+	assert.Equal("jane", c.Username)
+	assert.Equal("token-3", c.Secret)
+
+	// 列表
+	out, err = p.run("list", "")
+	assert.NoError(err)
+	var list map[string]string
+	assert.NoError(json.Unmarshal([]byte(out), &list))
+	assert.Equal(map[string]string{"https://index.docker.io/v1/": "jane", "registry.example.com": "<token>"}, list)
+
+	// 删除
+	_, err = p.run("erase", "registry.example.com\n")
+	assert.NoError(err)
+	out, err = p.run("erase", "registry.example.com\n")
+	assert.Error(err)
+	assert.Equal(dockercredential.ErrCredentialsNotFound.Error()+"\n", out)
+
+	// 参数错误
+	out, err = p.run("get", "\n")
+	assert.Error(err)
+	assert.Equal(dockercredential.ErrMissingServerURL.Error()+"\n", out)
+	out, err = p.run("store", `{"ServerURL":"a.example.com","Secret":"x"}`)
+	assert.Error(err)
+	assert.Equal(dockercredential.ErrMissingUsername.Error()+"\n", out)
+	out, err = p.run("store", `not json`)
+	assert.Error(err)
+	out, err = p.run("unknown", "")
+	assert.Error(err)
+	assert.Contains(out, "unknown credential action")
+	out, err = p.run("version", "")
+	assert.NoError(err)
+	assert.Equal(dockercredential.Version+"\n", out)
+}
+
+func TestRegistryEntries(t *testing.T) {
+	assert := assert.New(t)
+	vault := testvault.New(t)
+	srv := vault.Srv
+	// 手动保存的带 docker 标签的记录
+	assert.NoError(srv.SavePassword("ghcr", "token-1", "github"))
+	assert.NoError(srv.SetMeta("ghcr", password.Meta{Username: "john", URL: "ghcr.io", Tags: []string{"docker"}}))
+	// 其他记录即使 URL 相同也不会被使用
+	assert.NoError(srv.SavePassword("github", "web-password", "github"))
+	assert.NoError(srv.SetMeta("github", password.Meta{Username: "john", URL: "ghcr.io"}))
+
+	helper := dockercredential.NewHelper(srv)
+	username, secret, err := helper.Get("ghcr.io")
+	assert.NoError(err)
+	assert.Equal("john", username)
+	assert.Equal("token-1", secret)
+
+	list, err := helper.List()
+	assert.NoError(err)
+	assert.Equal(map[string]string{"ghcr.io": "john"}, list)
+
+	// 新的凭据使用 docker-registry 平台
+	assert.NoError(helper.Add(dockercredential.Credentials{ServerURL: "quay.io", Username: "bot", Secret: "token-2"}))
+	pwd, platform, err := srv.GetPasswordWithKey("docker:quay.io")
+	assert.NoError(err)
+	assert.Equal("token-2", pwd)
+	assert.Equal(dockercredential.Platform, platform)
+
+	assert.NoError(helper.Delete("ghcr.io"))
+	_, _, err = srv.GetPasswordWithKey("github")
+	assert.NoError(err)
+
+	// 设置了强度策略时不满足的密码不保存，已有的凭据也不会被更新
+	assert.NoError(strength.NewPolicyService(vault.DB).SetPolicy(strength.Policy{MinScore: strength.ScoreFair}))
+	var weak *strength.WeakPasswordError
+	assert.ErrorAs(helper.Add(dockercredential.Credentials{ServerURL: "gcr.io", Username: "bot", Secret: "123456"}), &weak)
+	_, _, err = helper.Get("gcr.io")
+	assert.ErrorIs(err, dockercredential.ErrCredentialsNotFound)
+	assert.ErrorAs(helper.Add(dockercredential.Credentials{ServerURL: "quay.io", Username: "bot", Secret: "password"}), &weak)
+	_, secret, err = helper.Get("quay.io")
+	assert.NoError(err)
+	assert.Equal("token-2", secret)
+}
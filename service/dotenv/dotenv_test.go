@@ -0,0 +1,135 @@
+package dotenv_test
+
+import (
+	"password_manager/service/dotenv"
+	"password_manager/service/password"
+	"password_manager/service/testvault"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		src       string
+		expected  []dotenv.Variable
+		hasErr    bool
+	}{
+		{"empty", "\n# comment\n\n", nil, false},
+		{"simple", "A=1\nB = two \n", []dotenv.Variable{{"A", "1"}, {"B", "two"}}, false},
+		{"export", "export DB_PASS=s3cret\n", []dotenv.Variable{{"DB_PASS", "s3cret"}}, false},
+		{"inline_comment", "A=value # comment\nB=va#lue\n", []dotenv.Variable{{"A", "value"}, {"B", "va#lue"}}, false},
+		{"single_quote", `A='it''s $HOME \n'`, nil, true},
+		{"single_quote_literal", `A='$HOME \n' # c`, []dotenv.Variable{{"A", `$HOME \n`}}, false},
+		{"double_quote", `A="line\nnext \"q\" \$HOME \\ \x"`, []dotenv.Variable{{"A", "line\nnext \"q\" $HOME \\ \\x"}}, false},
+		{"multiline", "KEY=\"-----BEGIN-----\nabc\n-----END-----\"\nB=2\n", []dotenv.Variable{{"KEY", "-----BEGIN-----\nabc\n-----END-----"}, {"B", "2"}}, false},
+		{"quote_with_trailing_space", "A=  \"x y\"  \nB=1", []dotenv.Variable{{"A", "x y"}, {"B", "1"}}, false},
+		{"crlf", "A=1\r\nB=\"2\"\r\n", []dotenv.Variable{{"A", "1"}, {"B", "2"}}, false},
+		{"duplicate", "A=1\nB=2\nA=3\n", []dotenv.Variable{{"A", "3"}, {"B", "2"}}, false},
+		{"empty_value", "A=\n", []dotenv.Variable{{"A", ""}}, false},
+		{"no_equals", "A\n", nil, true},
+		{"bad_name", "1A=x\n", nil, true},
+		{"unterminated", "A=\"abc\nB=1\n", nil, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			variables, err := dotenv.Parse(strings.NewReader(testCase.src))
+			if testCase.hasErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(testCase.expected, variables)
+		})
+	}
+	_, err := dotenv.Parse(strings.NewReader("A=1\n\nB=\"x\ny\" z\n"))
+	assert.EqualError(err, "line 4: unexpected characters after quoted value")
+}
+
+func TestFormat(t *testing.T) {
+	assert := assert.New(t)
+	variables := []dotenv.Variable{{"A", "it's $HOME"}, {"B", "a\\b\nc\"d"}}
+	var testCases = []struct {
+		shell    string
+		expected string
+	}{
+		{"bash", "export A='it'\\''s $HOME'\nexport B='a\\b\nc\"d'\n"},
+		{"zsh", "export A='it'\\''s $HOME'\nexport B='a\\b\nc\"d'\n"},
+		{"fish", "set -gx A 'it\\'s $HOME';\nset -gx B 'a\\\\b\nc\"d';\n"},
+		{"dotenv", "A=\"it's \\$HOME\"\nB=\"a\\\\b\\nc\\\"d\"\n"},
+	}
+	for _, testCase := range testCases {
+		out, err := dotenv.Format(variables, testCase.shell)
+		assert.NoError(err, testCase.shell)
+		assert.Equal(testCase.expected, out, testCase.shell)
+	}
+	_, err := dotenv.Format(variables, "cmd")
+	assert.Error(err)
+
+	// dotenv 格式可以原样解析回来
+	out, _ := dotenv.Format(variables, "dotenv")
+	parsed, err := dotenv.Parse(strings.NewReader(out))
+	assert.NoError(err)
+	assert.Equal(variables, parsed)
+}
+
+func TestVariableName(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("DB_PASS", dotenv.VariableName("myapp", "myapp.DB_PASS"))
+	assert.Equal("github_token", dotenv.VariableName("myapp", "github_token"))
+	assert.Equal("api_key_v2", dotenv.VariableName("myapp", "api-key.v2"))
+	assert.Equal("_1PASS", dotenv.VariableName("myapp", "1PASS"))
+}
+
+func TestImportExport(t *testing.T) {
+	assert := assert.New(t)
+	srv := testvault.New(t).Srv
+
+	variables, err := dotenv.Parse(strings.NewReader("DB_PASS=s3cret\nAPI_KEY='k e y'\nEMPTY=\n"))
+	assert.NoError(err)
+	result, err := dotenv.Import(srv, "myapp", variables, false)
+	assert.NoError(err)
+	assert.Equal([]string{"myapp.DB_PASS", "myapp.API_KEY"}, result.Added)
+	assert.Equal([]string{"myapp.EMPTY"}, result.Skipped)
+	meta, _ := srv.GetMeta("myapp.DB_PASS")
+	assert.Equal([]string{"myapp"}, meta.Tags)
+	_, platform, _ := srv.GetPasswordWithKey("myapp.DB_PASS")
+	assert.Equal("myapp", platform)
+
+	// 已经存在的 key 默认跳过
+	variables = []dotenv.Variable{{"DB_PASS", "changed"}}
+	result, err = dotenv.Import(srv, "myapp", variables, false)
+	assert.NoError(err)
+	assert.Equal([]string{"myapp.DB_PASS"}, result.Skipped)
+	result, err = dotenv.Import(srv, "myapp", variables, true)
+	assert.NoError(err)
+	assert.Equal([]string{"myapp.DB_PASS"}, result.Updated)
+
+	// 手动添加到分组的条目
+	assert.NoError(srv.SavePassword("github-token", "ghp", "github.com"))
+	assert.NoError(srv.SetMeta("github-token", password.Meta{Tags: []string{"myapp"}}))
+	assert.NoError(srv.SavePassword("other", "x", "other"))
+
+	exported, err := dotenv.Export(srv, "myapp")
+	assert.NoError(err)
+	assert.Equal([]dotenv.Variable{{"API_KEY", "k e y"}, {"DB_PASS", "changed"}, {"github_token", "ghp"}}, exported)
+
+	_, err = dotenv.Export(srv, "missing")
+	assert.Error(err)
+
+	// 按文件夹和标签筛选
+	assert.NoError(srv.MoveToFolder("myapp.DB_PASS", "myapp/prod"))
+	exported, err = dotenv.ExportFiltered(srv, "myapp", password.Filter{Folder: "myapp"})
+	assert.NoError(err)
+	assert.Equal([]dotenv.Variable{{"DB_PASS", "changed"}}, exported)
+	_, err = dotenv.ExportFiltered(srv, "myapp", password.Filter{Tags: []string{"missing"}})
+	assert.Error(err)
+
+	// 变量名冲突
+	assert.NoError(srv.SavePassword("github_token", "dup", "myapp"))
+	_, err = dotenv.Export(srv, "myapp")
+	assert.EqualError(err, "keys github-token and github_token both map to variable github_token")
+}
@@ -0,0 +1,61 @@
+package dotenv
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	ShellBash   = "bash"
+	ShellZsh    = "zsh"
+	ShellFish   = "fish"
+	ShellDotenv = "dotenv"
+)
+
+// Shells 支持输出的格式
+var Shells = []string{ShellBash, ShellZsh, ShellFish, ShellDotenv}
+
+// Format 按照 shell 的语法输出变量，结果可以直接 eval 或保存为 .env 文件
+func Format(variables []Variable, shell string) (string, error) {
+	var quote func(string) string
+	var line func(name, value string) string
+	switch shell {
+	case ShellBash, ShellZsh:
+		line = func(name, value string) string { return "export " + name + "=" + value }
+		quote = quotePOSIX
+	case ShellFish:
+		line = func(name, value string) string { return "set -gx " + name + " " + value + ";" }
+		quote = quoteFish
+	case ShellDotenv:
+		line = func(name, value string) string { return name + "=" + value }
+		quote = quoteDotenv
+	default:
+		return "", errors.New("unknown shell " + shell + ", expected one of " + strings.Join(Shells, ", "))
+	}
+	var out strings.Builder
+	for _, variable := range variables {
+		if !IsValidName(variable.Name) {
+			return "", errors.New("invalid variable name " + variable.Name)
+		}
+		out.WriteString(line(variable.Name, quote(variable.Value)))
+		out.WriteByte('\n')
+	}
+	return out.String(), nil
+}
+
+// quotePOSIX 单引号中的内容不做任何展开，单引号本身需要先结束引号再转义
+func quotePOSIX(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// quoteFish fish 的单引号中只有 \' 和 \\ 是转义
+func quoteFish(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+// quoteDotenv 使用双引号，与 Parse 支持的转义一致
+func quoteDotenv(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
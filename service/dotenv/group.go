@@ -0,0 +1,145 @@
+package dotenv
+
+import (
+	"errors"
+	"password_manager/service/password"
+	"sort"
+	"strings"
+)
+
+// KeySeparator 分组名和变量名之间的分隔符，变量名中不会出现
+const KeySeparator = "."
+
+// Key 返回变量导入到分组后的 key，例如 myapp.DB_PASS
+func Key(group, name string) string {
+	return group + KeySeparator + name
+}
+
+// ImportResult 导入的结果
+type ImportResult struct {
+	Added   []string
+	Updated []string
+	Skipped []string
+}
+
+// Import 把变量保存到分组中，平台和标签都是分组名
+// 已经存在的 key 在 overwrite 为false时跳过，否则更新密码
+func Import(srv *password.PasswordService, group string, variables []Variable, overwrite bool) (ImportResult, error) {
+	var result ImportResult
+	if group == "" {
+		return result, errors.New("group is empty")
+	}
+	for _, variable := range variables {
+		if variable.Value == "" {
+			//空值无法保存为密码
+			result.Skipped = append(result.Skipped, Key(group, variable.Name))
+			continue
+		}
+		key := Key(group, variable.Name)
+		_, _, err := srv.GetPasswordWithKey(key)
+		exists := err == nil
+		switch {
+		case exists && !overwrite:
+			result.Skipped = append(result.Skipped, key)
+			continue
+		case exists:
+			if _, err := srv.UpdatePassword(key, variable.Value, group, ""); err != nil {
+				return result, err
+			}
+			result.Updated = append(result.Updated, key)
+		default:
+			if err := srv.SavePassword(key, variable.Value, group); err != nil {
+				return result, err
+			}
+			result.Added = append(result.Added, key)
+		}
+		meta, err := srv.GetMeta(key)
+		if err != nil {
+			return result, err
+		}
+		if !hasTag(meta.Tags, group) {
+			meta.Tags = append(meta.Tags, group)
+			if err := srv.SetMeta(key, meta); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Export 返回分组中的所有变量，按照变量名排序
+// 分组包括平台是 group 或者带有 group 标签的条目，变量名是 key 去掉 "group." 前缀，
+// 其他字符不合法时替换为 _
+func Export(srv *password.PasswordService, group string) ([]Variable, error) {
+	return ExportFiltered(srv, group, password.Filter{})
+}
+
+// ExportFiltered 与 Export 相同，只导出分组中满足 filter 的条目，导出的每个条目记录一次使用
+func ExportFiltered(srv *password.PasswordService, group string, filter password.Filter) ([]Variable, error) {
+	if group == "" {
+		return nil, errors.New("group is empty")
+	}
+	passwords, err := srv.GetAllPasswords()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]string)
+	var variables []Variable
+	for key, data := range passwords {
+		if data.Platform != group && !hasTag(data.Tags, group) || !filter.Match(data) {
+			continue
+		}
+		name := VariableName(group, key)
+		if other, exists := keys[name]; exists {
+			if other > key {
+				other, key = key, other
+			}
+			return nil, errors.New("keys " + other + " and " + key + " both map to variable " + name)
+		}
+		keys[name] = key
+		variables = append(variables, Variable{Name: name, Value: data.Password})
+	}
+	if len(variables) == 0 && !filter.Empty() {
+		return nil, errors.New("no entry of group " + group + " matches the tag and folder filter")
+	}
+	if len(variables) == 0 {
+		return nil, errors.New("group " + group + " not found")
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	for _, key := range keys {
+		srv.RecordAccess(key)
+	}
+	return variables, nil
+}
+
+// VariableName 根据 key 生成变量名
+func VariableName(group, key string) string {
+	name := strings.TrimPrefix(key, group+KeySeparator)
+	var out strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+			out.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				out.WriteByte('_')
+			}
+			out.WriteRune(r)
+		default:
+			out.WriteByte('_')
+		}
+	}
+	return out.String()
+}
+
+// hasTag 判断是否有指定的标签
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
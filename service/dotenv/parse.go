@@ -0,0 +1,183 @@
+package dotenv
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Variable 一个环境变量
+type Variable struct {
+	Name  string
+	Value string
+}
+
+// IsValidName 判断是否是合法的环境变量名
+func IsValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Parse 解析 .env 文件
+// 支持注释、export 前缀、单引号（原样）和双引号（支持 \n \t \" \\ \$ 转义，可以跨行），不展开 ${VAR}
+// 同名变量以最后一次为准，顺序按照第一次出现的位置
+func Parse(r io.Reader) ([]Variable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+	var variables []Variable
+	index := make(map[string]int)
+	for {
+		variable, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return variables, nil
+		}
+		if i, exists := index[variable.Name]; exists {
+			variables[i] = variable
+			continue
+		}
+		index[variable.Name] = len(variables)
+		variables = append(variables, variable)
+	}
+}
+
+// parser .env 文件的解析状态
+type parser struct {
+	src  string
+	pos  int
+	line int
+}
+
+// errorf 返回带行号的错误
+func (p *parser) errorf(line int, msg string) error {
+	return errors.New("line " + strconv.Itoa(line) + ": " + msg)
+}
+
+// next 解析下一个变量，文件结束时返回false
+func (p *parser) next() (Variable, bool, error) {
+	for p.pos < len(p.src) {
+		lineStart := p.pos
+		line := p.readLine()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			p.line++
+			continue
+		}
+		start := p.line
+		if rest, ok := strings.CutPrefix(trimmed, "export "); ok {
+			trimmed = strings.TrimSpace(rest)
+		}
+		name, value, ok := strings.Cut(trimmed, "=")
+		name = strings.TrimSpace(name)
+		if !ok {
+			return Variable{}, false, p.errorf(start, "expected NAME=VALUE")
+		}
+		if !IsValidName(name) {
+			return Variable{}, false, p.errorf(start, "invalid variable name "+strconv.Quote(name))
+		}
+		value = strings.TrimLeft(value, " \t")
+		var err error
+		if strings.HasPrefix(value, "'") || strings.HasPrefix(value, `"`) {
+			//引号中的内容可以跨行，从引号的位置重新解析
+			eq := strings.IndexByte(line, '=') + 1
+			p.pos = lineStart + eq + len(line[eq:]) - len(strings.TrimLeft(line[eq:], " \t"))
+			value, err = p.readQuoted(start)
+		} else {
+			value = unquoted(value)
+			p.line++
+		}
+		if err != nil {
+			return Variable{}, false, err
+		}
+		return Variable{Name: name, Value: value}, true, nil
+	}
+	return Variable{}, false, nil
+}
+
+// readLine 读取到行尾，并移动到下一行的开头
+func (p *parser) readLine() string {
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		line := p.src[p.pos:]
+		p.pos = len(p.src)
+		return line
+	}
+	line := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return line
+}
+
+// readQuoted 从 p.pos 读取引号中的值，引号之后只能有空白或注释
+func (p *parser) readQuoted(start int) (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var value strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorf(start, "unterminated quoted value")
+		}
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			rest := p.readLine()
+			p.line++
+			if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", p.errorf(p.line-1, "unexpected characters after quoted value")
+			}
+			return value.String(), nil
+		case c == '\\' && quote == '"' && p.pos < len(p.src):
+			escaped := p.src[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\', '$':
+				value.WriteByte(escaped)
+			case '\n':
+				p.line++
+				value.WriteByte('\\')
+				value.WriteByte(escaped)
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(escaped)
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			value.WriteByte(c)
+		}
+	}
+}
+
+// unquoted 处理没有引号的值，去掉 " #" 之后的注释和结尾的空白
+func unquoted(value string) string {
+	for i := 0; i < len(value); i++ {
+		if value[i] == '#' && i > 0 && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimRight(value, " \t")
+}
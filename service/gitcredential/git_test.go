@@ -0,0 +1,148 @@
+package gitcredential_test
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"password_manager/service/password"
+	"password_manager/service/testvault"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gitEnv 运行 git 的环境，使用测试程序作为 credential helper，并且禁止交互输入
+func gitEnv(t *testing.T, dbDir string) []string {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := t.TempDir()
+	return append(os.Environ(),
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+home,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=",
+		"SSH_ASKPASS=",
+		"GIT_AUTHOR_NAME=pm",
+		"GIT_AUTHOR_EMAIL=pm@example.com",
+		"GIT_COMMITTER_NAME=pm",
+		"GIT_COMMITTER_EMAIL=pm@example.com",
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=credential.helper",
+		"GIT_CONFIG_VALUE_0=!PM_TEST_GIT_HELPER='"+dbDir+"' '"+exe+"'",
+	)
+}
+
+// git 执行 git 命令
+func git(t *testing.T, env []string, dir, stdin string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// newGitServer 通过 git http-backend 提供一个需要 Basic 验证的裸仓库
+func newGitServer(t *testing.T, username, token string) *httptest.Server {
+	root := t.TempDir()
+	if out, err := exec.Command("git", "init", "--bare", filepath.Join(root, "repo.git")).CombinedOutput(); err != nil {
+		t.Fatal(string(out))
+	}
+	if out, err := exec.Command("git", "-C", filepath.Join(root, "repo.git"), "config", "http.receivepack", "true").CombinedOutput(); err != nil {
+		t.Fatal(string(out))
+	}
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1", "REMOTE_USER=" + username},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != username || pass != token {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitOverHTTP(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	assert := assert.New(t)
+	vault := testvault.New(t)
+	server := newGitServer(t, "john", "token-123")
+	serverURL, _ := url.Parse(server.URL)
+	host := serverURL.Host
+
+	// 保存 git 使用的令牌，平台为 host，关闭数据库后 git 启动的 helper 才能打开
+	assert.NoError(vault.Srv.SavePassword("local_git", "token-123", host))
+	assert.NoError(vault.Srv.SetMeta("local_git", password.Meta{Username: "john"}))
+	vault.Close()
+
+	env := gitEnv(t, vault.Dir)
+	work := t.TempDir()
+	out, err := git(t, env, work, "", "clone", server.URL+"/repo.git", "clone")
+	if !assert.NoError(err, out) {
+		return
+	}
+	clone := filepath.Join(work, "clone")
+	assert.NoError(os.WriteFile(filepath.Join(clone, "README"), []byte("hello\n"), 0600))
+	_, err = git(t, env, clone, "", "add", "README")
+	assert.NoError(err)
+	out, err = git(t, env, clone, "", "commit", "-m", "init")
+	assert.NoError(err, out)
+	out, err = git(t, env, clone, "", "push", "origin", "HEAD")
+	assert.NoError(err, out)
+
+	// 错误的令牌会被 git 拒绝，pm 中没有匹配的记录时 git 无法登录
+	out, err = git(t, env, work, "", "ls-remote", strings.Replace(server.URL, "://", "://alice@", 1)+"/repo.git")
+	assert.Error(err, out)
+
+	// 通过 git credential 命令驱动 store/get/erase
+	input := "protocol=https\nhost=git.example.com\nusername=alice\npassword=glpat-7Hq2Xv9LmR4t\n\n"
+	_, err = git(t, env, work, input, "credential", "approve")
+	assert.NoError(err)
+	out, err = git(t, env, work, "protocol=https\nhost=git.example.com\n\n", "credential", "fill")
+	assert.NoError(err, out)
+	assert.Contains(out, "username=alice\n")
+	assert.Contains(out, "password=glpat-7Hq2Xv9LmR4t\n")
+	// 更新已有的凭据时 helper 的标准错误会显示在终端，不能包含新旧令牌
+	input = strings.Replace(input, "glpat-7Hq2Xv9LmR4t", "glpat-Kd83PzW1nQ6y", 1)
+	out, err = git(t, env, work, input, "credential", "approve")
+	assert.NoError(err, out)
+	assert.NotContains(out, "glpat-")
+	out, err = git(t, env, work, "protocol=https\nhost=git.example.com\n\n", "credential", "fill")
+	assert.NoError(err, out)
+	assert.Contains(out, "password=glpat-Kd83PzW1nQ6y\n")
+	_, err = git(t, env, work, input, "credential", "reject")
+	assert.NoError(err)
+	out, err = git(t, env, work, "protocol=https\nhost=git.example.com\n\n", "credential", "fill")
+	assert.Error(err, out)
+
+	vault, err = testvault.Open(vault.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vault.Close()
+	_, _, err = vault.Srv.GetPasswordWithKey("alice@git.example.com")
+	assert.Error(err)
+	_, _, err = vault.Srv.GetPasswordWithKey("local_git")
+	assert.NoError(err)
+}
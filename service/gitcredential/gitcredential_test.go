@@ -0,0 +1,145 @@
+package gitcredential_test
+
+import (
+	"bytes"
+	"os"
+	"password_manager/service/gitcredential"
+	"password_manager/service/password"
+	"password_manager/service/strength"
+	"password_manager/service/testvault"
+	"strings"
+	"testing"
+
+	"github.com/gookit/color"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain 设置了 PM_TEST_GIT_HELPER 时作为 git 调用的 credential helper 运行
+func TestMain(m *testing.M) {
+	if dir := os.Getenv("PM_TEST_GIT_HELPER"); dir != "" {
+		color.SetOutput(os.Stderr)
+		vault, err := testvault.Open(dir)
+		if err != nil {
+			os.Stderr.WriteString(err.Error() + "\n")
+			os.Exit(1)
+		}
+		err = gitcredential.NewHelper(vault.Srv).Run(os.Args[len(os.Args)-1], os.Stdin, os.Stdout)
+		vault.Close()
+		if err != nil {
+			os.Stderr.WriteString(err.Error() + "\n")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		input     string
+		expected  gitcredential.Credential
+		hasErr    bool
+	}{
+		{"fields", "protocol=https\nhost=github.com\nusername=john\n\n", gitcredential.Credential{Protocol: "https", Host: "github.com", Username: "john"}, false},
+		{"stop_at_blank_line", "host=a.com\n\nhost=b.com\n", gitcredential.Credential{Host: "a.com"}, false},
+		{"crlf_and_unknown", "host=a.com\r\ncapability[]=authtype\r\n", gitcredential.Credential{Host: "a.com"}, false},
+		{"value_with_equals", "password=a=b\n", gitcredential.Credential{Password: "a=b"}, false},
+		{"url", "url=https://john:pw@example.com:8443/org/repo.git\n", gitcredential.Credential{Protocol: "https", Host: "example.com:8443", Path: "org/repo.git", Username: "john", Password: "pw"}, false},
+		{"invalid", "host\n", gitcredential.Credential{}, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			c, err := gitcredential.Parse(strings.NewReader(testCase.input))
+			if testCase.hasErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(testCase.expected, c)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(gitcredential.Write(&buf, gitcredential.Credential{Protocol: "https", Host: "github.com", Username: "john", Password: "pw"}))
+	assert.Equal("protocol=https\nhost=github.com\nusername=john\npassword=pw\n", buf.String())
+	assert.Error(gitcredential.Write(&buf, gitcredential.Credential{Password: "a\nb"}))
+}
+
+func TestHelper(t *testing.T) {
+	assert := assert.New(t)
+	vault := testvault.New(t)
+	srv := vault.Srv
+	// 手动保存的记录
+	assert.NoError(srv.SavePassword("github_john", "token-1", "github.com"))
+	assert.NoError(srv.SetMeta("github_john", password.Meta{Username: "john", URL: "https://github.com"}))
+	assert.NoError(srv.SavePassword("github_work", "token-2", "github.com"))
+	assert.NoError(srv.SetMeta("github_work", password.Meta{Username: "work"}))
+	helper := gitcredential.NewHelper(srv)
+
+	run := func(operation, input string) string {
+		var out bytes.Buffer
+		assert.NoError(helper.Run(operation, strings.NewReader(input), &out))
+		return out.String()
+	}
+
+	// 按用户名匹配
+	assert.Equal("protocol=https\nhost=github.com\nusername=work\npassword=token-2\n",
+		run("get", "protocol=https\nhost=github.com\nusername=work\n"))
+	// 没有用户名时按 key 排序取第一条
+	assert.Equal("protocol=https\nhost=github.com\nusername=john\npassword=token-1\n",
+		run("get", "protocol=https\nhost=github.com\n"))
+	// 协议不同的记录不匹配
+	assert.Equal("protocol=http\nhost=github.com\nusername=work\npassword=token-2\n",
+		run("get", "protocol=http\nhost=github.com\n"))
+	// 没有匹配的记录时不输出
+	assert.Equal("", run("get", "protocol=https\nhost=gitlab.com\n"))
+	// 不认识的操作
+	assert.Equal("", run("unknown", "host=github.com\n"))
+
+	// 保存新的凭据
+	run("store", "protocol=https\nhost=gitlab.com\nusername=alice\npassword=glpat-7Hq2Xv9LmR4t\n")
+	pwd, platform, err := srv.GetPasswordWithKey("alice@gitlab.com")
+	assert.NoError(err)
+	assert.Equal("glpat-7Hq2Xv9LmR4t", pwd)
+	assert.Equal("gitlab.com", platform)
+	meta, err := srv.GetMeta("alice@gitlab.com")
+	assert.NoError(err)
+	assert.Equal(password.Meta{Username: "alice", URL: "https://gitlab.com", Tags: []string{"git"}}, meta)
+
+	// 保存已有的凭据时更新密码
+	run("store", "protocol=https\nhost=gitlab.com\nusername=alice\npassword=glpat-Kd83PzW1nQ6y\n")
+	pwd, _, _ = srv.GetPasswordWithKey("alice@gitlab.com")
+	assert.Equal("glpat-Kd83PzW1nQ6y", pwd)
+
+	// 密码不同时不删除
+	run("erase", "protocol=https\nhost=gitlab.com\nusername=alice\npassword=other\n")
+	_, _, err = srv.GetPasswordWithKey("alice@gitlab.com")
+	assert.NoError(err)
+	run("erase", "protocol=https\nhost=gitlab.com\nusername=alice\npassword=glpat-Kd83PzW1nQ6y\n")
+	_, _, err = srv.GetPasswordWithKey("alice@gitlab.com")
+	assert.Error(err)
+
+	// 没有密码时只给出 host 不删除，用户名也相同时才删除
+	run("erase", "protocol=https\nhost=github.com\n")
+	_, _, err = srv.GetPasswordWithKey("github_john")
+	assert.NoError(err)
+	run("erase", "protocol=https\nhost=github.com\nusername=nobody\n")
+	_, _, err = srv.GetPasswordWithKey("github_john")
+	assert.NoError(err)
+	run("erase", "protocol=https\nhost=github.com\nusername=john\n")
+	_, _, err = srv.GetPasswordWithKey("github_john")
+	assert.Error(err)
+	_, _, err = srv.GetPasswordWithKey("github_work")
+	assert.NoError(err)
+
+	// 设置了强度策略时不满足的密码不保存
+	assert.NoError(strength.NewPolicyService(vault.DB).SetPolicy(strength.Policy{MinScore: strength.ScoreFair}))
+	assert.Error(helper.Run("store", strings.NewReader("protocol=https\nhost=gitlab.com\nusername=bob\npassword=123456\n"), &bytes.Buffer{}))
+	_, _, err = srv.GetPasswordWithKey("bob@gitlab.com")
+	assert.Error(err)
+}
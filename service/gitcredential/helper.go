@@ -0,0 +1,169 @@
+package gitcredential
+
+import (
+	"errors"
+	"io"
+	"password_manager/service/password"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	OperationGet   = "get"
+	OperationStore = "store"
+	OperationErase = "erase"
+
+	// gitTag git 保存的记录会带上这个标签
+	gitTag = "git"
+)
+
+// Helper 将 git 的凭据映射到 pm 的记录：平台为 host，用户名为附加信息中的用户名
+type Helper struct {
+	logger *zap.Logger
+	srv    *password.PasswordService
+}
+
+// NewHelper 创建 git credential helper
+func NewHelper(srv *password.PasswordService) *Helper {
+	return &Helper{
+		logger: zap.L(),
+		srv:    srv,
+	}
+}
+
+// Run 从 r 读取属性并执行 operation，get 的结果写入 w
+// 不认识的操作按协议要求直接忽略
+func (h *Helper) Run(operation string, r io.Reader, w io.Writer) error {
+	c, err := Parse(r)
+	if err != nil {
+		return err
+	}
+	switch operation {
+	case OperationGet:
+		found, ok, err := h.Get(c)
+		if err != nil || !ok {
+			return err
+		}
+		return Write(w, found)
+	case OperationStore:
+		return h.Store(c)
+	case OperationErase:
+		return h.Erase(c)
+	default:
+		return nil
+	}
+}
+
+// Get 查找匹配的记录，找不到时返回 false
+func (h *Helper) Get(c Credential) (Credential, bool, error) {
+	key, data, ok, err := h.find(c)
+	if err != nil || !ok {
+		return Credential{}, false, err
+	}
+	h.logger.Info("git credential found", zap.String("key", key), zap.String("host", c.Host))
+	h.srv.RecordAccess(key)
+	result := c
+	if data.Username != "" {
+		result.Username = data.Username
+	}
+	result.Password = data.Password
+	return result, true, nil
+}
+
+// Store 保存 git 验证成功的凭据，已有匹配的记录时更新密码
+func (h *Helper) Store(c Credential) error {
+	if c.Host == "" || c.Username == "" || c.Password == "" {
+		return nil
+	}
+	key, data, ok, err := h.find(c)
+	if err != nil {
+		return err
+	}
+	if ok && data.Password == c.Password {
+		return nil
+	}
+	//与手动保存的密码一样需要满足强度策略
+	if _, err := h.srv.CheckStrength(c.Password, c.Host, c.Username); err != nil {
+		return err
+	}
+	if ok {
+		_, err := h.srv.UpdatePassword(key, c.Password, "", "")
+		return err
+	}
+	key = h.newKey(c)
+	if err := h.srv.SavePassword(key, c.Password, c.Host); err != nil {
+		return err
+	}
+	meta := password.Meta{Username: c.Username, Tags: []string{gitTag}}
+	if c.Protocol != "" {
+		meta.URL = c.Protocol + "://" + c.Host
+	}
+	return h.srv.SetMeta(key, meta)
+}
+
+// Erase 删除 git 拒绝的凭据，避免误删手动保存的记录：
+// 给出密码时只有密码相同才删除，没有密码时 host 和用户名都要相同，只给出 host 时不删除
+func (h *Helper) Erase(c Credential) error {
+	if c.Host == "" {
+		return nil
+	}
+	if c.Password == "" && c.Username == "" {
+		return nil
+	}
+	key, data, ok, err := h.find(c)
+	if err != nil || !ok {
+		return err
+	}
+	if c.Password != "" && c.Password != data.Password {
+		return nil
+	}
+	h.logger.Info("git credential erased", zap.String("key", key), zap.String("host", c.Host))
+	return h.srv.DeletePassword(key)
+}
+
+// find 查找平台为 host 的记录
+// 指定了用户名时只匹配该用户名，记录有 URL 时协议也要相同，多条匹配时按 key 排序取第一条
+func (h *Helper) find(c Credential) (string, password.PasswordData, bool, error) {
+	if c.Host == "" {
+		return "", password.PasswordData{}, false, errors.New("host is empty")
+	}
+	all, err := h.srv.GetAllPasswords()
+	if err != nil {
+		return "", password.PasswordData{}, false, err
+	}
+	var keys []string
+	for key, data := range all {
+		if !strings.EqualFold(data.Platform, c.Host) {
+			continue
+		}
+		if c.Username != "" && data.Username != c.Username {
+			continue
+		}
+		if c.Protocol != "" && data.URL != "" {
+			if scheme, _, ok := strings.Cut(data.URL, "://"); ok && !strings.EqualFold(scheme, c.Protocol) {
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return "", password.PasswordData{}, false, nil
+	}
+	sort.Strings(keys)
+	return keys[0], all[keys[0]], true, nil
+}
+
+// newKey 为新的凭据生成不重复的 key，格式为 username@host，重复时加上序号
+func (h *Helper) newKey(c Credential) string {
+	base := c.Username + "@" + c.Host
+	key := base
+	for i := 2; ; i++ {
+		if _, _, err := h.srv.GetPasswordWithKey(key); err != nil {
+			return key
+		}
+		key = base + "-" + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,99 @@
+package gitcredential
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Credential git credential 协议中的一组属性
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// Parse 读取 key=value 格式的属性，遇到空行或输入结束时停止
+// 不认识的属性会被忽略，url 属性会被拆分为 protocol、host、path、username 和 password
+func Parse(r io.Reader) (Credential, error) {
+	var c Credential
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return c, errors.New("invalid credential line: " + line)
+		}
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "url":
+			if err := c.setURL(value); err != nil {
+				return c, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// setURL 拆分 url 属性
+func (c *Credential) setURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	c.Protocol = u.Scheme
+	c.Host = u.Host
+	c.Path = strings.TrimPrefix(u.Path, "/")
+	if u.User != nil {
+		c.Username = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			c.Password = password
+		}
+	}
+	return nil
+}
+
+// Write 以 key=value 格式输出非空的属性
+func Write(w io.Writer, c Credential) error {
+	attributes := []struct {
+		key   string
+		value string
+	}{
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+		{"username", c.Username},
+		{"password", c.Password},
+	}
+	for _, attribute := range attributes {
+		if attribute.value == "" {
+			continue
+		}
+		if strings.ContainsAny(attribute.value, "\n\x00") {
+			return errors.New("credential " + attribute.key + " contains a newline")
+		}
+		if _, err := io.WriteString(w, attribute.key+"="+attribute.value+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package listing
+
+import (
+	"errors"
+	"password_manager/service/password"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// 筛选表达式支持的字段
+const (
+	FieldKey      = "key"
+	FieldPlatform = "platform"
+	FieldTag      = "tag"
+	FieldFolder   = "folder"
+	FieldType     = "type"
+	FieldUsername = "username"
+	FieldURL      = "url"
+)
+
+// FilterFields 筛选表达式支持的字段
+var FilterFields = []string{FieldKey, FieldPlatform, FieldTag, FieldFolder, FieldType, FieldUsername, FieldURL}
+
+// globSeparators path.Match 中 * 和 ? 不匹配 /，匹配前把 / 换成不会出现在值中的字符，
+// 这样 folder=work* 也能匹配 work/aws 这样的子文件夹
+var globSeparators = strings.NewReplacer("/", "\x00")
+
+// Predicate 条目的筛选条件
+type Predicate func(data password.PasswordData) bool
+
+// ParseFilter 解析筛选表达式，格式为 <field><op><pattern>：
+// = 和 != 使用不区分大小写的通配符（* ? [...]，* 和 ? 也匹配 /），~ 和 !~ 使用正则表达式
+// 标签有多个值，任意一个匹配即可
+func ParseFilter(expr string) (Predicate, error) {
+	index := strings.IndexAny(expr, "=~")
+	if index <= 0 {
+		return nil, errors.New("invalid filter " + expr + ", expected e.g. platform=github, key~^aws_ or tag!=old")
+	}
+	field, pattern := expr[:index], expr[index+1:]
+	negate := strings.HasSuffix(field, "!")
+	field = strings.TrimSpace(strings.TrimSuffix(field, "!"))
+	values, err := fieldValues(field)
+	if err != nil {
+		return nil, err
+	}
+	var match func(value string) bool
+	if expr[index] == '~' {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.New("invalid regular expression in filter " + expr + ": " + err.Error())
+		}
+		match = re.MatchString
+	} else {
+		pattern = globSeparators.Replace(strings.ToLower(pattern))
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.New("invalid pattern in filter " + expr)
+		}
+		match = func(value string) bool {
+			matched, _ := path.Match(pattern, globSeparators.Replace(strings.ToLower(value)))
+			return matched
+		}
+	}
+	return func(data password.PasswordData) bool {
+		found := false
+		for _, value := range values(data) {
+			if match(value) {
+				found = true
+				break
+			}
+		}
+		return found != negate
+	}, nil
+}
+
+// fieldValues 返回读取字段值的函数
+func fieldValues(field string) (func(data password.PasswordData) []string, error) {
+	switch field {
+	case FieldKey:
+		return func(data password.PasswordData) []string { return []string{data.Key} }, nil
+	case FieldPlatform:
+		return func(data password.PasswordData) []string { return []string{data.Platform} }, nil
+	case FieldTag:
+		return func(data password.PasswordData) []string { return data.Tags }, nil
+	case FieldFolder:
+		return func(data password.PasswordData) []string { return []string{data.Folder} }, nil
+	case FieldType:
+		return func(data password.PasswordData) []string {
+			if data.Type == "" {
+				return []string{password.TypeLogin}
+			}
+			return []string{data.Type}
+		}, nil
+	case FieldUsername:
+		return func(data password.PasswordData) []string { return []string{data.Username} }, nil
+	case FieldURL:
+		return func(data password.PasswordData) []string { return []string{data.URL} }, nil
+	}
+	return nil, errors.New("unknown filter field " + field + ", expected one of " + strings.Join(FilterFields, ", "))
+}
@@ -0,0 +1,207 @@
+package listing
+
+import (
+	"errors"
+	"password_manager/service/password"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 排序字段
+const (
+	SortKey      = "key"
+	SortPlatform = "platform"
+	// SortUpdated 按密码最后一次修改的时间，最近修改的在前
+	SortUpdated = "updated"
+	// SortCreated 按创建时间，最近创建的在前
+	SortCreated = "created"
+	// SortLastUsed 按最后一次读取的时间，最近使用的在前
+	SortLastUsed = "last-used"
+	// SortUses 按读取次数，使用最多的在前
+	SortUses = "uses"
+)
+
+// SortFields 支持的排序字段
+var SortFields = []string{SortKey, SortPlatform, SortUpdated, SortCreated, SortLastUsed, SortUses}
+
+// GroupFields 支持的分组字段
+var GroupFields = []string{FieldPlatform, FieldFolder, FieldType}
+
+// Options 列表的筛选、排序、分组和分页
+type Options struct {
+	Filters []Predicate
+	// Sort 排序字段，为空时按 key 排序
+	Sort    string
+	Reverse bool
+	// GroupBy 分组字段，为空时不分组，分组时先按分组排序再按 Sort 排序
+	GroupBy string
+	// Offset 跳过的条目数量，Limit 最多返回的条目数量，为 0 时不限制
+	Offset int
+	Limit  int
+}
+
+// Page 一页结果
+type Page struct {
+	Entries []password.PasswordData
+	// Total 筛选后分页前的条目数量
+	Total int
+}
+
+// Group 一个分组中连续的条目
+type Group struct {
+	Name    string
+	Entries []password.PasswordData
+}
+
+// Apply 依次筛选、排序并分页，相同排序值的条目按 key 排序，结果是确定的
+func Apply(entries []password.PasswordData, opts Options) (Page, error) {
+	value, err := sortValue(opts.Sort)
+	if err != nil {
+		return Page{}, err
+	}
+	group, err := groupName(opts.GroupBy)
+	if err != nil {
+		return Page{}, err
+	}
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return Page{}, errors.New("offset and limit cannot be negative")
+	}
+	//先取出排序值再排序，避免在大型密码库中反复访问和移动整个条目
+	var items []sortItem
+	for i := range entries {
+		data := &entries[i]
+		if !matchAll(opts.Filters, *data) {
+			continue
+		}
+		item := sortItem{index: i, key: data.Key}
+		item.group = group(data)
+		item.groupFold = strings.ToLower(item.group)
+		value(data, &item)
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := &items[i], &items[j]
+		if c := compareFold(a.groupFold, b.groupFold, a.group, b.group); c != 0 {
+			return c < 0
+		}
+		c := compareFold(a.textFold, b.textFold, a.text, b.text)
+		if c == 0 {
+			//时间越新、次数越多越靠前
+			c = b.at.Compare(a.at)
+		}
+		if c == 0 {
+			c = b.count - a.count
+		}
+		if opts.Reverse {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+		return a.key < b.key
+	})
+	page := Page{Total: len(items)}
+	start := min(opts.Offset, len(items))
+	end := len(items)
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, end)
+	}
+	page.Entries = make([]password.PasswordData, 0, end-start)
+	for _, item := range items[start:end] {
+		page.Entries = append(page.Entries, entries[item.index])
+	}
+	return page, nil
+}
+
+// Groups 把排好序的条目按分组字段切分成连续的分组
+func Groups(entries []password.PasswordData, field string) ([]Group, error) {
+	group, err := groupName(field)
+	if err != nil {
+		return nil, err
+	}
+	var groups []Group
+	for _, data := range entries {
+		name := group(&data)
+		if len(groups) == 0 || groups[len(groups)-1].Name != name {
+			groups = append(groups, Group{Name: name})
+		}
+		last := &groups[len(groups)-1]
+		last.Entries = append(last.Entries, data)
+	}
+	return groups, nil
+}
+
+// matchAll 条目是否满足所有筛选条件
+func matchAll(filters []Predicate, data password.PasswordData) bool {
+	for _, filter := range filters {
+		if !filter(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortItem 排序时使用的条目下标和排序值
+type sortItem struct {
+	index int
+	key   string
+	// group 和 text 是分组和排序的字符串值，Fold 是它们的小写形式
+	group, groupFold string
+	text, textFold   string
+	// at 和 count 是排序的时间值和次数
+	at    time.Time
+	count int
+}
+
+// compareFold 先按小写形式比较，只有大小写不同时再区分大小写
+func compareFold(foldA, foldB, a, b string) int {
+	if c := strings.Compare(foldA, foldB); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// sortValue 返回把排序字段的取值写入 sortItem 的函数
+func sortValue(field string) (func(data *password.PasswordData, item *sortItem), error) {
+	switch field {
+	case "", SortKey:
+		return func(data *password.PasswordData, item *sortItem) {
+			//key 区分大小写排序
+			item.text, item.textFold = data.Key, data.Key
+		}, nil
+	case SortPlatform:
+		return func(data *password.PasswordData, item *sortItem) {
+			item.text, item.textFold = data.Platform, strings.ToLower(data.Platform)
+		}, nil
+	case SortUpdated:
+		return func(data *password.PasswordData, item *sortItem) { item.at = data.Modified }, nil
+	case SortCreated:
+		return func(data *password.PasswordData, item *sortItem) { item.at = data.Created }, nil
+	case SortLastUsed:
+		return func(data *password.PasswordData, item *sortItem) { item.at = data.LastUsed }, nil
+	case SortUses:
+		return func(data *password.PasswordData, item *sortItem) { item.count = data.UseCount }, nil
+	}
+	return nil, errors.New("unknown sort field " + field + ", expected one of " + strings.Join(SortFields, ", "))
+}
+
+// groupName 返回分组字段的取值函数
+func groupName(field string) (func(data *password.PasswordData) string, error) {
+	switch field {
+	case "":
+		return func(*password.PasswordData) string { return "" }, nil
+	case FieldPlatform:
+		return func(data *password.PasswordData) string { return data.Platform }, nil
+	case FieldFolder:
+		return func(data *password.PasswordData) string { return data.Folder }, nil
+	case FieldType:
+		return func(data *password.PasswordData) string {
+			if data.Type == "" {
+				return password.TypeLogin
+			}
+			return data.Type
+		}, nil
+	}
+	return nil, errors.New("unknown group field " + field + ", expected one of " + strings.Join(GroupFields, ", "))
+}
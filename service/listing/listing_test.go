@@ -0,0 +1,128 @@
+package listing_test
+
+import (
+	"password_manager/service/listing"
+	"password_manager/service/password"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// entries 测试用的条目
+func entries() []password.PasswordData {
+	day := func(n int) time.Time { return time.Date(2026, 1, n, 0, 0, 0, 0, time.UTC) }
+	return []password.PasswordData{
+		{Key: "github_work", Platform: "GitHub", Tags: []string{"work", "code"}, Folder: "work", Modified: day(3), Created: day(1), LastUsed: day(9), UseCount: 5},
+		{Key: "aws_prod", Platform: "aws", Tags: []string{"work", "cloud"}, Folder: "work/aws", Modified: day(5), Created: day(2)},
+		{Key: "aws_dev", Platform: "aws", Tags: []string{"cloud"}, Folder: "work/aws", Modified: day(1), Created: day(1), LastUsed: day(6), UseCount: 2},
+		{Key: "bank", Platform: "", Type: password.TypeCard, Modified: day(2), LastUsed: day(8), UseCount: 2},
+		{Key: "github_home", Platform: "GitHub", Username: "john", Modified: day(4), Created: day(4), LastUsed: day(7), UseCount: 1},
+	}
+}
+
+// keys 返回条目的 key
+func keys(entries []password.PasswordData) []string {
+	result := []string{}
+	for _, data := range entries {
+		result = append(result, data.Key)
+	}
+	return result
+}
+
+func TestParseFilter(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		expr string
+		keys []string
+	}{
+		{"platform=github", []string{"github_work", "github_home"}},
+		{"platform=", []string{"bank"}},
+		{"key=aws_*", []string{"aws_prod", "aws_dev"}},
+		{"key!=aws_*", []string{"github_work", "bank", "github_home"}},
+		{"key~_(prod|home)$", []string{"aws_prod", "github_home"}},
+		{"key!~^github", []string{"aws_prod", "aws_dev", "bank"}},
+		{"tag=work", []string{"github_work", "aws_prod"}},
+		{"tag=c*", []string{"github_work", "aws_prod", "aws_dev"}},
+		{"folder=work/*", []string{"aws_prod", "aws_dev"}},
+		{"folder=work*", []string{"github_work", "aws_prod", "aws_dev"}},
+		{"folder=*/aws", []string{"aws_prod", "aws_dev"}},
+		{"folder!=work*", []string{"bank", "github_home"}},
+		{"type=card", []string{"bank"}},
+		{"type=login", []string{"github_work", "aws_prod", "aws_dev", "github_home"}},
+		{"username=john", []string{"github_home"}},
+	}
+	for _, tc := range testCases {
+		filter, err := listing.ParseFilter(tc.expr)
+		if !assert.NoError(err, tc.expr) {
+			continue
+		}
+		matched := []string{}
+		for _, data := range entries() {
+			if filter(data) {
+				matched = append(matched, data.Key)
+			}
+		}
+		assert.Equal(tc.keys, matched, tc.expr)
+	}
+	for _, expr := range []string{"github", "=github", "color=red", "key~(", "key=[", "key!"} {
+		_, err := listing.ParseFilter(expr)
+		assert.Error(err, expr)
+	}
+}
+
+func TestApply(t *testing.T) {
+	assert := assert.New(t)
+	awsOnly, err := listing.ParseFilter("platform=aws")
+	assert.NoError(err)
+	testCases := []struct {
+		name  string
+		opts  listing.Options
+		keys  []string
+		total int
+	}{
+		{"default sort by key", listing.Options{}, []string{"aws_dev", "aws_prod", "bank", "github_home", "github_work"}, 5},
+		{"platform then key", listing.Options{Sort: listing.SortPlatform}, []string{"bank", "aws_dev", "aws_prod", "github_home", "github_work"}, 5},
+		{"recently updated first", listing.Options{Sort: listing.SortUpdated}, []string{"aws_prod", "github_home", "github_work", "bank", "aws_dev"}, 5},
+		{"recently created first", listing.Options{Sort: listing.SortCreated}, []string{"github_home", "aws_prod", "aws_dev", "github_work", "bank"}, 5},
+		{"recently used first", listing.Options{Sort: listing.SortLastUsed}, []string{"github_work", "bank", "github_home", "aws_dev", "aws_prod"}, 5},
+		{"most used first", listing.Options{Sort: listing.SortUses}, []string{"github_work", "aws_dev", "bank", "github_home", "aws_prod"}, 5},
+		{"least used first", listing.Options{Sort: listing.SortUses, Reverse: true}, []string{"aws_prod", "github_home", "aws_dev", "bank", "github_work"}, 5},
+		{"reverse", listing.Options{Sort: listing.SortKey, Reverse: true}, []string{"github_work", "github_home", "bank", "aws_prod", "aws_dev"}, 5},
+		{"reverse keeps key order for ties", listing.Options{Sort: listing.SortPlatform, Reverse: true}, []string{"github_home", "github_work", "aws_dev", "aws_prod", "bank"}, 5},
+		{"filter", listing.Options{Filters: []listing.Predicate{awsOnly}}, []string{"aws_dev", "aws_prod"}, 2},
+		{"page", listing.Options{Offset: 1, Limit: 2}, []string{"aws_prod", "bank"}, 5},
+		{"last page", listing.Options{Offset: 4, Limit: 2}, []string{"github_work"}, 5},
+		{"offset past the end", listing.Options{Offset: 10}, []string{}, 5},
+		{"group by platform", listing.Options{GroupBy: listing.FieldPlatform, Sort: listing.SortUpdated}, []string{"bank", "aws_prod", "aws_dev", "github_home", "github_work"}, 5},
+	}
+	for _, tc := range testCases {
+		page, err := listing.Apply(entries(), tc.opts)
+		if !assert.NoError(err, tc.name) {
+			continue
+		}
+		assert.Equal(tc.keys, keys(page.Entries), tc.name)
+		assert.Equal(tc.total, page.Total, tc.name)
+	}
+	for _, opts := range []listing.Options{{Sort: "size"}, {GroupBy: "tag"}, {Offset: -1}, {Limit: -1}} {
+		_, err := listing.Apply(entries(), opts)
+		assert.Error(err)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	assert := assert.New(t)
+	page, err := listing.Apply(entries(), listing.Options{GroupBy: listing.FieldPlatform})
+	assert.NoError(err)
+	groups, err := listing.Groups(page.Entries, listing.FieldPlatform)
+	assert.NoError(err)
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	//分组不区分大小写排序
+	assert.Equal([]string{"", "aws", "GitHub"}, names)
+	assert.Equal([]string{"aws_dev", "aws_prod"}, keys(groups[1].Entries))
+	_, err = listing.Groups(page.Entries, "color")
+	assert.Error(err)
+}
@@ -0,0 +1,132 @@
+package master
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// MinLength 主密码的最小长度
+	MinLength = 8
+
+	verifierKey = "verifier"
+	saltLength  = 16
+	hashLength  = 32
+)
+
+// ErrNotSet 还没有设置主密码
+var ErrNotSet = errors.New("master password is not set")
+
+// verifier 主密码的校验值，只保存 argon2id 的哈希，不保存主密码本身
+type verifier struct {
+	Salt    []byte `json:"salt"`
+	Hash    []byte `json:"hash"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+// MasterService 主密码的设置和校验
+type MasterService struct {
+	logger *zap.Logger
+	db     *bbolt.DB
+}
+
+// NewMasterService 创建主密码服务
+func NewMasterService(db *bbolt.DB) *MasterService {
+	return &MasterService{
+		logger: zap.L(),
+		db:     db,
+	}
+}
+
+// IsSet 是否已经设置了主密码
+func (srv *MasterService) IsSet() (bool, error) {
+	v, err := srv.getVerifier()
+	if err != nil {
+		return false, err
+	}
+	return v != nil, nil
+}
+
+// SetMasterPassword 设置或修改主密码
+func (srv *MasterService) SetMasterPassword(password string) error {
+	if len([]rune(password)) < MinLength {
+		return errors.New("master password is too short")
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	v := verifier{
+		Salt:    salt,
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}
+	v.Hash = argon2.IDKey([]byte(password), v.Salt, v.Time, v.Memory, v.Threads, hashLength)
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	err = srv.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.MasterBucketName))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(verifierKey), value)
+	})
+	if err != nil {
+		srv.logger.Error("save master password failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Verify 校验主密码，未设置主密码时返回 ErrNotSet
+func (srv *MasterService) Verify(password string) (bool, error) {
+	v, err := srv.getVerifier()
+	if err != nil {
+		return false, err
+	}
+	if v == nil {
+		return false, ErrNotSet
+	}
+	hash := argon2.IDKey([]byte(password), v.Salt, v.Time, v.Memory, v.Threads, uint32(len(v.Hash)))
+	return subtle.ConstantTimeCompare(hash, v.Hash) == 1, nil
+}
+
+// getVerifier 读取主密码的校验值，未设置时返回nil
+func (srv *MasterService) getVerifier() (*verifier, error) {
+	var value []byte
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.MasterBucketName))
+		if bucket == nil {
+			return nil
+		}
+		if data := bucket.Get([]byte(verifierKey)); data != nil {
+			value = append([]byte(nil), data...)
+		}
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("read master password failed:", zap.Error(err))
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	var v verifier
+	if err := json.Unmarshal(value, &v); err != nil {
+		srv.logger.Error("unmarshal master password failed:", zap.Error(err))
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,40 @@
+package master_test
+
+import (
+	"password_manager/service/master"
+	"password_manager/service/testvault"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasterPassword(t *testing.T) {
+	assert := assert.New(t)
+	srv := master.NewMasterService(testvault.New(t).DB)
+
+	isSet, err := srv.IsSet()
+	assert.NoError(err)
+	assert.False(isSet)
+	_, err = srv.Verify("anything")
+	assert.ErrorIs(err, master.ErrNotSet)
+
+	assert.Error(srv.SetMasterPassword("short"))
+	assert.NoError(srv.SetMasterPassword("correct horse"))
+	isSet, err = srv.IsSet()
+	assert.NoError(err)
+	assert.True(isSet)
+
+	ok, err := srv.Verify("correct horse")
+	assert.NoError(err)
+	assert.True(ok)
+	ok, err = srv.Verify("wrong horse")
+	assert.NoError(err)
+	assert.False(ok)
+
+	// 修改主密码后旧密码失效
+	assert.NoError(srv.SetMasterPassword("battery staple"))
+	ok, _ = srv.Verify("correct horse")
+	assert.False(ok)
+	ok, _ = srv.Verify("battery staple")
+	assert.True(ok)
+}
@@ -0,0 +1,54 @@
+package nativehost
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ErrNoDialog 找不到可以弹出确认框的程序
+var ErrNoDialog = errors.New("no confirmation dialog available (install zenity or kdialog)")
+
+// DialogConfirm 弹出系统确认框询问是否允许扩展获取密码
+// 标准输入输出被浏览器占用，所以只能通过图形界面确认
+func DialogConfirm(req ConfirmRequest) (bool, error) {
+	message := "Allow " + req.Origin + " to fill the password of \"" + req.Key + "\""
+	if req.Username != "" {
+		message += " (" + req.Username + ")"
+	}
+	message += " into " + req.URL + "?"
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", `display dialog (item 1 of argv) with title "pm" buttons {"Deny", "Allow"} default button "Deny" cancel button "Deny"`,
+			"-e", "end run",
+			message)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-Command",
+			"Add-Type -AssemblyName PresentationFramework; "+
+				"if ([System.Windows.MessageBox]::Show($env:PM_CONFIRM_MESSAGE, 'pm', 'YesNo') -eq 'Yes') { exit 0 } else { exit 1 }")
+		cmd.Env = append(os.Environ(), "PM_CONFIRM_MESSAGE="+message)
+	default:
+		if path, err := exec.LookPath("zenity"); err == nil {
+			cmd = exec.Command(path, "--question", "--title=pm", "--no-markup", "--text="+message)
+		} else if path, err := exec.LookPath("kdialog"); err == nil {
+			cmd = exec.Command(path, "--title", "pm", "--yesno", message)
+		} else {
+			return false, ErrNoDialog
+		}
+	}
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	//确认框返回非零状态表示用户拒绝
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return false, err
+}
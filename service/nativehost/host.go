@@ -0,0 +1,208 @@
+package nativehost
+
+import (
+	"errors"
+	"io"
+	"password_manager/service/password"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// HostName 注册到浏览器中的本地程序名称
+	HostName = "com.password_manager.pm"
+
+	actionPing  = "ping"
+	actionMatch = "match"
+	actionGet   = "get"
+)
+
+// ErrOriginNotAllowed 调用方不在允许列表中
+var ErrOriginNotAllowed = errors.New("origin is not allowed")
+
+// Request 扩展发送的请求
+type Request struct {
+	ID     string `json:"id,omitempty"`
+	Action string `json:"action"`
+	// URL 当前网页的地址
+	URL string `json:"url,omitempty"`
+	// Key 要获取的密码记录
+	Key string `json:"key,omitempty"`
+}
+
+// Entry 与网页匹配的记录，不包含密码
+type Entry struct {
+	Key      string `json:"key"`
+	Username string `json:"username,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Credential 用户确认后返回的登录信息
+type Credential struct {
+	Key      string `json:"key"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password"`
+}
+
+// Response 返回给扩展的结果
+type Response struct {
+	ID         string      `json:"id,omitempty"`
+	OK         bool        `json:"ok"`
+	Error      string      `json:"error,omitempty"`
+	Entries    []Entry     `json:"entries,omitempty"`
+	Credential *Credential `json:"credential,omitempty"`
+}
+
+// ConfirmRequest 释放密码前需要用户确认的内容
+type ConfirmRequest struct {
+	Origin   string
+	URL      string
+	Key      string
+	Username string
+}
+
+// Options 本地程序的配置
+type Options struct {
+	// Origin 调用方的来源，Chrome 为 chrome-extension://<id>/，Firefox 为扩展id
+	Origin string
+	// AllowedOrigins 允许调用的来源
+	AllowedOrigins []string
+	// Confirm 释放密码前询问用户，返回 true 表示允许
+	Confirm func(req ConfirmRequest) (bool, error)
+}
+
+// Host 浏览器扩展的本地程序，通过标准输入输出与浏览器通信
+type Host struct {
+	logger *zap.Logger
+	srv    *password.PasswordService
+	opts   Options
+}
+
+// NewHost 创建本地程序
+func NewHost(srv *password.PasswordService, opts Options) *Host {
+	return &Host{
+		logger: zap.L(),
+		srv:    srv,
+		opts:   opts,
+	}
+}
+
+// Serve 循环读取请求并写入结果，输入结束时返回nil
+// 调用方不在允许列表中时返回一条错误消息后退出
+func (h *Host) Serve(r io.Reader, w io.Writer) error {
+	if !h.originAllowed() {
+		h.logger.Warn("native messaging origin rejected", zap.String("origin", h.opts.Origin))
+		WriteMessage(w, Response{Error: ErrOriginNotAllowed.Error()})
+		return ErrOriginNotAllowed
+	}
+	for {
+		var req Request
+		if err := ReadMessage(r, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		resp := h.handle(req)
+		resp.ID = req.ID
+		if err := WriteMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// originAllowed 调用方是否在允许列表中
+func (h *Host) originAllowed() bool {
+	if h.opts.Origin == "" {
+		return false
+	}
+	for _, origin := range h.opts.AllowedOrigins {
+		if origin == h.opts.Origin {
+			return true
+		}
+	}
+	return false
+}
+
+// handle 处理单个请求
+func (h *Host) handle(req Request) Response {
+	switch req.Action {
+	case actionPing:
+		return Response{OK: true}
+	case actionMatch:
+		entries, err := h.match(req.URL)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true, Entries: entries}
+	case actionGet:
+		credential, err := h.get(req.URL, req.Key)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true, Credential: credential}
+	default:
+		return Response{Error: "unknown action: " + req.Action}
+	}
+}
+
+// match 返回与网页匹配的记录，按 key 排序，只读取平台和附加信息，不解密任何密码
+func (h *Host) match(pageURL string) ([]Entry, error) {
+	if pageURL == "" {
+		return nil, errors.New("url is empty")
+	}
+	all, err := h.srv.GetAllEntries()
+	if err != nil {
+		return nil, err
+	}
+	entries := []Entry{}
+	for _, data := range all {
+		if !MatchURL(pageURL, data) {
+			continue
+		}
+		entries = append(entries, Entry{
+			Key:      data.Key,
+			Username: data.Username,
+			Platform: data.Platform,
+			URL:      data.URL,
+		})
+	}
+	return entries, nil
+}
+
+// get 用户确认后返回与网页匹配的登录信息
+func (h *Host) get(pageURL, key string) (*Credential, error) {
+	if pageURL == "" || key == "" {
+		return nil, errors.New("url and key are required")
+	}
+	data, err := h.srv.GetEntry(key)
+	if err != nil {
+		return nil, err
+	}
+	//只返回属于当前网页的密码，用户同意之后才解密
+	if !MatchURL(pageURL, data) {
+		return nil, errors.New("key:" + key + " does not match " + pageURL)
+	}
+	if h.opts.Confirm == nil {
+		return nil, errors.New("no confirmation available")
+	}
+	allowed, err := h.opts.Confirm(ConfirmRequest{
+		Origin:   h.opts.Origin,
+		URL:      pageURL,
+		Key:      key,
+		Username: data.Username,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		h.logger.Info("user denied native messaging request", zap.String("key", key))
+		return nil, errors.New("denied by user")
+	}
+	pwd, _, err := h.srv.GetPasswordTracked(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Credential{Key: key, Username: data.Username, Password: pwd}, nil
+}
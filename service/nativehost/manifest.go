@@ -0,0 +1,181 @@
+package nativehost
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	BrowserChrome   = "chrome"
+	BrowserChromium = "chromium"
+	BrowserFirefox  = "firefox"
+
+	// AllowListFileName 允许调用的来源列表，保存在可执行文件所在目录
+	AllowListFileName = "native-host-origins.json"
+	hostDescription   = "pm password manager"
+)
+
+// Manifest 浏览器的本地程序清单
+type Manifest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Path        string `json:"path"`
+	Type        string `json:"type"`
+	// AllowedOrigins Chrome 使用的来源列表
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+	// AllowedExtensions Firefox 使用的扩展id列表
+	AllowedExtensions []string `json:"allowed_extensions,omitempty"`
+}
+
+// Origin 返回浏览器调用本地程序时使用的来源
+func Origin(browser, extensionID string) (string, error) {
+	if extensionID == "" {
+		return "", errors.New("extension id is empty")
+	}
+	switch browser {
+	case BrowserChrome, BrowserChromium:
+		return "chrome-extension://" + extensionID + "/", nil
+	case BrowserFirefox:
+		return extensionID, nil
+	default:
+		return "", errors.New("unsupported browser: " + browser)
+	}
+}
+
+// NewManifest 创建指定浏览器的清单，path 为浏览器启动的程序路径
+func NewManifest(browser, extensionID, path string) (Manifest, error) {
+	origin, err := Origin(browser, extensionID)
+	if err != nil {
+		return Manifest{}, err
+	}
+	manifest := Manifest{
+		Name:        HostName,
+		Description: hostDescription,
+		Path:        path,
+		Type:        "stdio",
+	}
+	if browser == BrowserFirefox {
+		manifest.AllowedExtensions = []string{origin}
+	} else {
+		manifest.AllowedOrigins = []string{origin}
+	}
+	return manifest, nil
+}
+
+// ManifestDir 返回当前系统中浏览器查找清单的目录
+func ManifestDir(browser string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS + "/" + browser {
+	case "linux/" + BrowserChrome:
+		return filepath.Join(home, ".config", "google-chrome", "NativeMessagingHosts"), nil
+	case "linux/" + BrowserChromium:
+		return filepath.Join(home, ".config", "chromium", "NativeMessagingHosts"), nil
+	case "linux/" + BrowserFirefox:
+		return filepath.Join(home, ".mozilla", "native-messaging-hosts"), nil
+	case "darwin/" + BrowserChrome:
+		return filepath.Join(home, "Library", "Application Support", "Google", "Chrome", "NativeMessagingHosts"), nil
+	case "darwin/" + BrowserChromium:
+		return filepath.Join(home, "Library", "Application Support", "Chromium", "NativeMessagingHosts"), nil
+	case "darwin/" + BrowserFirefox:
+		return filepath.Join(home, "Library", "Application Support", "Mozilla", "NativeMessagingHosts"), nil
+	default:
+		return "", errors.New("installing the manifest for " + browser + " on " + runtime.GOOS + " is not supported, register it manually")
+	}
+}
+
+// WriteManifest 将清单写入目录
+func WriteManifest(dir string, manifest Manifest) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, manifest.Name+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WriteLauncher 写入浏览器启动的脚本，脚本再调用 pm native-host
+// 浏览器只会把来源作为参数传给清单中的程序，无法直接指定子命令
+func WriteLauncher(path, executable string) error {
+	var content string
+	if runtime.GOOS == "windows" {
+		content = "@echo off\r\n\"" + executable + "\" native-host %*\r\n"
+	} else {
+		content = "#!/bin/sh\nexec '" + strings.ReplaceAll(executable, "'", `'\''`) + "' native-host \"$@\"\n"
+	}
+	return os.WriteFile(path, []byte(content), 0700)
+}
+
+// allowList 允许调用的来源列表文件的内容
+type allowList struct {
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// LoadAllowList 读取允许调用的来源，文件不存在时返回空列表
+func LoadAllowList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var list allowList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return list.AllowedOrigins, nil
+}
+
+// AddAllowedOrigin 将来源加入允许列表
+func AddAllowedOrigin(path, origin string) error {
+	origins, err := LoadAllowList(path)
+	if err != nil {
+		return err
+	}
+	for _, existing := range origins {
+		if existing == origin {
+			return nil
+		}
+	}
+	data, err := json.MarshalIndent(allowList{AllowedOrigins: append(origins, origin)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// CallerOrigin 从浏览器传入的参数中获取调用方的来源
+// Chrome 传入 chrome-extension://<id>/，Firefox 传入清单路径和扩展id
+func CallerOrigin(args []string) string {
+	var positional []string
+	for _, arg := range args {
+		// Windows 上的 Chrome 还会传入 --parent-window
+		if strings.HasPrefix(arg, "--") {
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	if len(positional) == 0 {
+		return ""
+	}
+	if strings.HasPrefix(positional[0], "chrome-extension://") {
+		return positional[0]
+	}
+	if len(positional) >= 2 {
+		return positional[1]
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package nativehost
+
+import (
+	"net/url"
+	"password_manager/service/password"
+	"strings"
+)
+
+// pageHost 解析网页地址中的主机名，忽略 www 前缀
+func pageHost(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		// 没有协议的地址，例如 github.com/login
+		u, err = url.Parse("https://" + strings.TrimSpace(rawURL))
+		if err != nil {
+			return ""
+		}
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// hostMatches host 是否等于 entryHost 或者是它的子域名
+func hostMatches(host, entryHost string) bool {
+	if host == "" || entryHost == "" {
+		return false
+	}
+	return host == entryHost || strings.HasSuffix(host, "."+entryHost)
+}
+
+// secondLevelSuffixes 国家顶级域名下常见的二级公共后缀，例如 co.uk、com.cn
+var secondLevelSuffixes = map[string]bool{
+	"ac": true, "co": true, "com": true, "edu": true, "gov": true, "net": true, "or": true, "org": true, "ne": true,
+}
+
+// registrableDomain 返回 host 中可以注册的域名，例如 accounts.google.com 返回 google.com，
+// mail.example.co.uk 返回 example.co.uk，host 本身就是公共后缀时返回空字符串
+func registrableDomain(host string) string {
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) >= 2 {
+		tld := labels[len(labels)-1]
+		if len(tld) == 2 && secondLevelSuffixes[labels[len(labels)-2]] {
+			n = 3
+		}
+	}
+	if len(labels) < n {
+		return ""
+	}
+	for _, label := range labels[len(labels)-n:] {
+		if label == "" {
+			return ""
+		}
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// MatchURL 判断密码记录是否属于网页地址 pageURL
+// 优先比较记录的 URL，没有 URL 时使用平台：带点的平台按域名比较，
+// 否则只与可注册域名中公共后缀前面的一段比较，例如 github 匹配 github.com 和 gist.github.com，不匹配 github.evil.com
+func MatchURL(pageURL string, data password.PasswordData) bool {
+	host := pageHost(pageURL)
+	if host == "" {
+		return false
+	}
+	if data.URL != "" {
+		return hostMatches(host, pageHost(data.URL))
+	}
+	platform := strings.ToLower(strings.TrimSpace(data.Platform))
+	if platform == "" {
+		return false
+	}
+	if strings.Contains(platform, ".") {
+		platformHost := pageHost(platform)
+		// 平台本身是公共后缀时会匹配大量无关的网站
+		if registrableDomain(platformHost) == "" {
+			return false
+		}
+		return hostMatches(host, platformHost)
+	}
+	domain := registrableDomain(host)
+	if domain == "" {
+		return false
+	}
+	label, _, _ := strings.Cut(domain, ".")
+	return label == platform
+}
@@ -0,0 +1,46 @@
+package nativehost
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// MaxMessageSize 单条消息的最大字节数，浏览器发给本地程序的消息不超过 4GB，这里限制得更小
+const MaxMessageSize = 1 << 20
+
+// ErrMessageTooLarge 消息超过了大小限制
+var ErrMessageTooLarge = errors.New("native message is too large")
+
+// ReadMessage 读取一条消息：4 字节本机字节序的长度加上 JSON 内容，与浏览器的 native messaging 协议一致
+func ReadMessage(r io.Reader, value any) error {
+	var length uint32
+	if err := binary.Read(r, binary.NativeEndian, &length); err != nil {
+		return err
+	}
+	if length > MaxMessageSize {
+		return ErrMessageTooLarge
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// WriteMessage 写入一条消息
+func WriteMessage(w io.Writer, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxMessageSize {
+		return ErrMessageTooLarge
+	}
+	if err := binary.Write(w, binary.NativeEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,248 @@
+package nativehost_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/nativehost"
+	"password_manager/service/password"
+	"password_manager/service/testvault"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+const testOrigin = "chrome-extension://abcdefg/"
+
+// newPasswordService 使用临时密码库创建密码服务并写入测试数据
+func newPasswordService(t *testing.T) *password.PasswordService {
+	srv := testvault.New(t).Srv
+	if err := srv.SavePassword("github_john", "secret-1", "github"); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.SetMeta("github_john", password.Meta{Username: "john"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.SavePassword("gitlab", "secret-2", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.SetMeta("gitlab", password.Meta{URL: "https://gitlab.com/users/sign_in"}); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+// run 将请求编码后交给本地程序，返回所有结果
+func run(t *testing.T, host *nativehost.Host, requests ...nativehost.Request) ([]nativehost.Response, error) {
+	var input bytes.Buffer
+	for _, req := range requests {
+		if err := nativehost.WriteMessage(&input, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var output bytes.Buffer
+	err := host.Serve(&input, &output)
+	var responses []nativehost.Response
+	for {
+		var resp nativehost.Response
+		if readErr := nativehost.ReadMessage(&output, &resp); readErr != nil {
+			if readErr != io.EOF {
+				t.Fatal(readErr)
+			}
+			break
+		}
+		responses = append(responses, resp)
+	}
+	return responses, err
+}
+
+func TestMessage(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(nativehost.WriteMessage(&buf, map[string]string{"action": "ping"}))
+	assert.Equal([]byte{17, 0, 0, 0}, buf.Bytes()[:4])
+	var value map[string]string
+	assert.NoError(nativehost.ReadMessage(&buf, &value))
+	assert.Equal("ping", value["action"])
+
+	// 超过大小限制
+	buf.Reset()
+	binary.Write(&buf, binary.NativeEndian, uint32(nativehost.MaxMessageSize+1))
+	assert.ErrorIs(nativehost.ReadMessage(&buf, &value), nativehost.ErrMessageTooLarge)
+
+	// 内容不完整
+	buf.Reset()
+	binary.Write(&buf, binary.NativeEndian, uint32(10))
+	buf.WriteString("{}")
+	assert.Error(nativehost.ReadMessage(&buf, &value))
+}
+
+func TestMatchURL(t *testing.T) {
+	var testCases = []struct {
+		test_name string
+		url       string
+		data      password.PasswordData
+		expected  bool
+	}{
+		{"url_same_host", "https://github.com/login", password.PasswordData{URL: "https://github.com"}, true},
+		{"url_www", "https://www.github.com/login", password.PasswordData{URL: "github.com"}, true},
+		{"url_subdomain", "https://gist.github.com/", password.PasswordData{URL: "https://github.com"}, true},
+		{"url_other_host", "https://github.com.evil.io/", password.PasswordData{URL: "https://github.com"}, false},
+		{"url_preferred_over_platform", "https://github.com/", password.PasswordData{Platform: "github", URL: "https://gitlab.com"}, false},
+		{"platform_label", "https://accounts.google.com/", password.PasswordData{Platform: "Google"}, true},
+		{"platform_domain", "https://mail.qq.com/", password.PasswordData{Platform: "qq.com"}, true},
+		{"platform_no_match", "https://github.com/", password.PasswordData{Platform: "gitlab"}, false},
+		{"platform_label_subdomain", "https://gist.github.com/", password.PasswordData{Platform: "github"}, true},
+		{"platform_label_country_suffix", "https://www.amazon.co.uk/", password.PasswordData{Platform: "amazon"}, true},
+		{"platform_label_other_domain", "https://github.evil.com/", password.PasswordData{Platform: "github"}, false},
+		{"platform_label_nested", "https://evil.com.github.attacker.net/", password.PasswordData{Platform: "github"}, false},
+		{"platform_label_suffix", "https://example.co.uk/", password.PasswordData{Platform: "co"}, false},
+		{"platform_domain_other_domain", "https://github.com.evil.io/", password.PasswordData{Platform: "github.com"}, false},
+		{"platform_public_suffix", "https://evil.co.uk/", password.PasswordData{Platform: "co.uk"}, false},
+		{"empty", "https://github.com/", password.PasswordData{}, false},
+		{"invalid_page", "", password.PasswordData{Platform: "github"}, false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, nativehost.MatchURL(testCase.url, testCase.data))
+		})
+	}
+}
+
+func TestHost(t *testing.T) {
+	assert := assert.New(t)
+	srv := newPasswordService(t)
+	var confirmed []nativehost.ConfirmRequest
+	allow := true
+	host := nativehost.NewHost(srv, nativehost.Options{
+		Origin:         testOrigin,
+		AllowedOrigins: []string{testOrigin},
+		Confirm: func(req nativehost.ConfirmRequest) (bool, error) {
+			confirmed = append(confirmed, req)
+			return allow, nil
+		},
+	})
+
+	responses, err := run(t, host,
+		nativehost.Request{ID: "1", Action: "ping"},
+		nativehost.Request{ID: "2", Action: "match", URL: "https://github.com/login"},
+		nativehost.Request{ID: "3", Action: "get", URL: "https://github.com/login", Key: "github_john"},
+		nativehost.Request{ID: "4", Action: "get", URL: "https://github.com/login", Key: "gitlab"},
+		nativehost.Request{ID: "5", Action: "unknown"},
+	)
+	assert.NoError(err)
+	if !assert.Len(responses, 5) {
+		return
+	}
+	assert.Equal(nativehost.Response{ID: "1", OK: true}, responses[0])
+	assert.Equal([]nativehost.Entry{{Key: "github_john", Username: "john", Platform: "github"}}, responses[1].Entries)
+	assert.Equal(&nativehost.Credential{Key: "github_john", Username: "john", Password: "secret-1"}, responses[2].Credential)
+	// 不属于当前网页的密码不会返回，也不会询问用户
+	assert.False(responses[3].OK)
+	assert.Nil(responses[3].Credential)
+	assert.Equal("5", responses[4].ID)
+	assert.NotEmpty(responses[4].Error)
+	if assert.Len(confirmed, 1) {
+		assert.Equal(nativehost.ConfirmRequest{Origin: testOrigin, URL: "https://github.com/login", Key: "github_john", Username: "john"}, confirmed[0])
+	}
+
+	// 用户拒绝
+	allow = false
+	responses, err = run(t, host, nativehost.Request{Action: "get", URL: "https://gitlab.com/", Key: "gitlab"})
+	assert.NoError(err)
+	if assert.Len(responses, 1) {
+		assert.False(responses[0].OK)
+		assert.Nil(responses[0].Credential)
+		assert.Equal("denied by user", responses[0].Error)
+	}
+}
+
+func TestHostDecryptsOnlyPickedEntry(t *testing.T) {
+	assert := assert.New(t)
+	vault := testvault.New(t)
+	assert.NoError(vault.Srv.SaveEntry("github_john", "secret-1", "github", password.Meta{Username: "john"}))
+	assert.NoError(vault.Srv.SaveEntry("gitlab", "secret-2", "gitlab", password.Meta{}))
+	// 破坏另一个条目的密文，匹配和读取 github_john 时不应该解密它
+	assert.NoError(vault.DB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+		value := append([]byte(nil), bucket.Get([]byte("gitlab"))...)
+		value[len(value)-1] ^= 0xff
+		return bucket.Put([]byte("gitlab"), value)
+	}))
+	host := nativehost.NewHost(vault.Srv, nativehost.Options{
+		Origin:         testOrigin,
+		AllowedOrigins: []string{testOrigin},
+		Confirm: func(req nativehost.ConfirmRequest) (bool, error) {
+			return true, nil
+		},
+	})
+	responses, err := run(t, host,
+		nativehost.Request{ID: "1", Action: "match", URL: "https://github.com/login"},
+		nativehost.Request{ID: "2", Action: "get", URL: "https://github.com/login", Key: "github_john"},
+	)
+	assert.NoError(err)
+	if assert.Len(responses, 2) {
+		assert.Equal([]nativehost.Entry{{Key: "github_john", Username: "john", Platform: "github"}}, responses[0].Entries)
+		assert.Equal(&nativehost.Credential{Key: "github_john", Username: "john", Password: "secret-1"}, responses[1].Credential)
+	}
+	access, err := vault.Srv.GetAccess("github_john")
+	assert.NoError(err)
+	assert.Equal(1, access.Count)
+}
+
+func TestHostOriginNotAllowed(t *testing.T) {
+	assert := assert.New(t)
+	srv := newPasswordService(t)
+	host := nativehost.NewHost(srv, nativehost.Options{
+		Origin:         "chrome-extension://other/",
+		AllowedOrigins: []string{testOrigin},
+		Confirm: func(req nativehost.ConfirmRequest) (bool, error) {
+			return true, nil
+		},
+	})
+	responses, err := run(t, host, nativehost.Request{Action: "match", URL: "https://github.com/"})
+	assert.ErrorIs(err, nativehost.ErrOriginNotAllowed)
+	if assert.Len(responses, 1) {
+		assert.False(responses[0].OK)
+		assert.Empty(responses[0].Entries)
+	}
+}
+
+func TestCallerOrigin(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(testOrigin, nativehost.CallerOrigin([]string{testOrigin}))
+	assert.Equal(testOrigin, nativehost.CallerOrigin([]string{testOrigin, "--parent-window=0"}))
+	assert.Equal("pm@example.com", nativehost.CallerOrigin([]string{"/path/com.password_manager.pm.json", "pm@example.com"}))
+	assert.Equal("", nativehost.CallerOrigin(nil))
+}
+
+func TestManifestAndAllowList(t *testing.T) {
+	assert := assert.New(t)
+	manifest, err := nativehost.NewManifest(nativehost.BrowserChrome, "abcdefg", "/opt/pm/pm-native-host")
+	assert.NoError(err)
+	assert.Equal([]string{testOrigin}, manifest.AllowedOrigins)
+	assert.Equal(nativehost.HostName, manifest.Name)
+	assert.Equal("stdio", manifest.Type)
+
+	manifest, err = nativehost.NewManifest(nativehost.BrowserFirefox, "pm@example.com", "/opt/pm/pm-native-host")
+	assert.NoError(err)
+	assert.Equal([]string{"pm@example.com"}, manifest.AllowedExtensions)
+	assert.Empty(manifest.AllowedOrigins)
+
+	_, err = nativehost.NewManifest("netscape", "abc", "/opt/pm/pm-native-host")
+	assert.Error(err)
+
+	path := filepath.Join(t.TempDir(), "origins.json")
+	origins, err := nativehost.LoadAllowList(path)
+	assert.NoError(err)
+	assert.Empty(origins)
+	assert.NoError(nativehost.AddAllowedOrigin(path, testOrigin))
+	assert.NoError(nativehost.AddAllowedOrigin(path, testOrigin))
+	assert.NoError(nativehost.AddAllowedOrigin(path, "pm@example.com"))
+	origins, err = nativehost.LoadAllowList(path)
+	assert.NoError(err)
+	assert.Equal([]string{testOrigin, "pm@example.com"}, origins)
+}
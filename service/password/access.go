@@ -0,0 +1,229 @@
+package password
+
+import (
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// accessTrackingKey 使用记录开关在 settings bucket 中的 key
+const accessTrackingKey = "access_tracking"
+
+// activityBuckets 与 key 一一对应的时间记录，key 改名和删除时一起处理
+var activityBuckets = []string{
+	dbfilekit.ModifiedBucketName,
+	dbfilekit.CreatedBucketName,
+	dbfilekit.AccessBucketName,
+}
+
+// Access 条目的使用记录，保存在单独的 bucket 中，记录时不需要改写条目本身
+type Access struct {
+	// LastUsed 最后一次读取密码的时间，没有记录时为零值
+	LastUsed time.Time `json:"last_used"`
+	// Count 读取密码的次数
+	Count int `json:"count"`
+}
+
+// GetPasswordTracked 与 GetPasswordWithKey 相同，读取成功后按照设置记录一次使用，用于用户查看密码的地方
+func (srv *PasswordService) GetPasswordTracked(key string) (string, string, error) {
+	password, platform, err := srv.readPassword(key)
+	if err != nil {
+		return "", "", err
+	}
+	srv.RecordAccess(key)
+	return password, platform, nil
+}
+
+// RecordAccess 按照设置记录一次对 key 的使用，查看、复制或者交给其他程序使用密码之后调用。
+// 很多地方读取密码只是为了检查或者搜索，因此读取密码本身不记录。记录失败只写日志，不影响读取密码
+func (srv *PasswordService) RecordAccess(key string) {
+	if err := srv.recordAccess(key, time.Now()); err != nil {
+		srv.logger.Error("record access failed:", zap.Error(err))
+	}
+}
+
+// AccessTrackingEnabled 是否记录使用情况，没有设置时默认记录
+func (srv *PasswordService) AccessTrackingEnabled() (bool, error) {
+	var enabled bool
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		enabled = accessTrackingEnabledWithTx(tx)
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("read access tracking setting failed:", zap.Error(err))
+		return false, err
+	}
+	return enabled, nil
+}
+
+// accessTrackingEnabledWithTx 使用tx读取是否记录使用情况
+func accessTrackingEnabledWithTx(tx *bbolt.Tx) bool {
+	bucket := tx.Bucket([]byte(dbfilekit.SettingsBucketName))
+	if bucket == nil {
+		return true
+	}
+	return string(bucket.Get([]byte(accessTrackingKey))) != "off"
+}
+
+// SetAccessTrackingEnabled 保存是否记录使用情况，关闭时保留已有的记录
+func (srv *PasswordService) SetAccessTrackingEnabled(enabled bool) error {
+	value := "on"
+	if !enabled {
+		value = "off"
+	}
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.SettingsBucketName))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(accessTrackingKey), []byte(value))
+	})
+	if err != nil {
+		srv.logger.Error("save access tracking setting failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetCreated 返回条目创建的时间，旧版本保存的条目没有记录时返回零值
+func (srv *PasswordService) GetCreated(key string) (time.Time, error) {
+	var created time.Time
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.CreatedBucketName))
+		if bucket == nil {
+			// 旧版本的数据库没有created bucket
+			return nil
+		}
+		var err error
+		created, err = parseTimestamp("created", key, bucket.Get([]byte(key)))
+		return err
+	})
+	if err != nil {
+		srv.logger.Error("get created time failed:", zap.Error(err))
+		return time.Time{}, err
+	}
+	return created, nil
+}
+
+// GetAccess 返回条目的使用记录，没有记录时返回零值
+func (srv *PasswordService) GetAccess(key string) (Access, error) {
+	var access Access
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.AccessBucketName))
+		if bucket == nil {
+			return nil
+		}
+		var err error
+		access, err = parseAccess(key, bucket.Get([]byte(key)))
+		return err
+	})
+	if err != nil {
+		srv.logger.Error("get access failed:", zap.Error(err))
+		return Access{}, err
+	}
+	return access, nil
+}
+
+// recordAccess 使用记录开启并且 key 存在时记录一次读取
+func (srv *PasswordService) recordAccess(key string, now time.Time) error {
+	return srv.db.Update(func(tx *bbolt.Tx) error {
+		if !accessTrackingEnabledWithTx(tx) {
+			return nil
+		}
+		if passwords := tx.Bucket([]byte(dbfilekit.PasswordBucketName)); passwords == nil || passwords.Get([]byte(key)) == nil {
+			return nil
+		}
+		bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.AccessBucketName))
+		if err != nil {
+			return err
+		}
+		access, err := parseAccess(key, bucket.Get([]byte(key)))
+		if err != nil {
+			return err
+		}
+		access.Count++
+		access.LastUsed = now
+		return bucket.Put([]byte(key), []byte(strconv.Itoa(access.Count)+" "+now.UTC().Format(time.RFC3339)))
+	})
+}
+
+// parseAccess 解析保存的使用记录，格式为 "次数 时间"，value 为 nil 时返回零值
+func parseAccess(key string, value []byte) (Access, error) {
+	if value == nil {
+		return Access{}, nil
+	}
+	countValue, lastUsedValue, ok := strings.Cut(string(value), " ")
+	count, err := strconv.Atoi(countValue)
+	if !ok || err != nil {
+		return Access{}, errors.New("access record of key:" + key + " is corrupted")
+	}
+	lastUsed, err := parseTimestamp("last used", key, []byte(lastUsedValue))
+	if err != nil {
+		return Access{}, err
+	}
+	return Access{LastUsed: lastUsed, Count: count}, nil
+}
+
+// parseTimestamp 解析保存的时间，value 为 nil 时返回零值，name 用于错误信息
+func parseTimestamp(name, key string, value []byte) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, string(value))
+	if err != nil {
+		return time.Time{}, errors.New(name + " time of key:" + key + " is corrupted")
+	}
+	return timestamp, nil
+}
+
+// putCreatedWithTx 保存创建时间
+func (srv *PasswordService) putCreatedWithTx(key string, created time.Time, tx *bbolt.Tx) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.CreatedBucketName))
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), []byte(created.UTC().Format(time.RFC3339)))
+}
+
+// moveActivityWithTx key 改名时修改时间、创建时间和使用记录跟随新的 key
+func (srv *PasswordService) moveActivityWithTx(key, newKey string, tx *bbolt.Tx) error {
+	for _, name := range activityBuckets {
+		bucket := tx.Bucket([]byte(name))
+		if bucket == nil {
+			continue
+		}
+		value := bucket.Get([]byte(key))
+		if value == nil {
+			if err := bucket.Delete([]byte(newKey)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := bucket.Put([]byte(newKey), append([]byte(nil), value...)); err != nil {
+			return err
+		}
+		if err := bucket.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteActivityWithTx 删除修改时间、创建时间和使用记录
+func (srv *PasswordService) deleteActivityWithTx(key string, tx *bbolt.Tx) error {
+	for _, name := range activityBuckets {
+		bucket := tx.Bucket([]byte(name))
+		if bucket == nil {
+			continue
+		}
+		if err := bucket.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
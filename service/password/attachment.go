@@ -0,0 +1,341 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const (
+	// AttachmentChunkSize 附件分块加密的大小
+	AttachmentChunkSize = 64 << 10
+	// MaxAttachmentSize 单个附件的最大大小
+	MaxAttachmentSize = 10 << 20
+	// MaxAttachments 每个条目最多的附件数量
+	MaxAttachments = 20
+
+	// attachmentInfoKey 附件子 bucket 中保存附件信息的 key，分块的 key 是8字节的序号
+	attachmentInfoKey = "info"
+)
+
+// AttachmentInfo 附件的信息，与内容一样加密保存
+type AttachmentInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Chunks  int       `json:"chunks"`
+	SHA256  string    `json:"sha256"`
+	Created time.Time `json:"created"`
+}
+
+// ValidateAttachmentName 检查附件名，不能为空也不能包含路径
+func ValidateAttachmentName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return errors.New("invalid attachment name " + strconv.Quote(name))
+	}
+	if strings.ContainsAny(name, "/\\\x00") {
+		return errors.New("invalid attachment name " + strconv.Quote(name) + ": must not contain a path")
+	}
+	return nil
+}
+
+// AddAttachment 把 r 的内容加密后作为 key 的附件保存
+// 内容按 AttachmentChunkSize 分块，每块使用独立的 nonce 加密，附件信息中记录分块数量和 SHA-256，
+// 读取时校验，避免分块被调换或截断。同名附件只有 overwrite 为true时才会被替换
+func (srv *PasswordService) AddAttachment(key, name string, r io.Reader, overwrite bool) (AttachmentInfo, error) {
+	info := AttachmentInfo{Name: name, Created: time.Now()}
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return info, ErrKeyEmpty
+	}
+	if err := ValidateAttachmentName(name); err != nil {
+		return info, err
+	}
+	//先在事务外读取和加密，避免长时间持有写锁
+	hash := sha256.New()
+	var chunks [][]byte
+	buf := make([]byte, AttachmentChunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			info.Size += int64(n)
+			if info.Size > MaxAttachmentSize {
+				return info, errors.New("attachment " + name + " is larger than " + strconv.Itoa(MaxAttachmentSize>>20) + " MiB")
+			}
+			hash.Write(buf[:n])
+			cipherData, nonce, err := srv.aesSrv.Encrypt(string(buf[:n]))
+			if err != nil {
+				srv.logger.Error("encrypt attachment failed:", zap.Error(err))
+				return info, err
+			}
+			chunks = append(chunks, append(nonce, cipherData...))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return info, err
+		}
+	}
+	if info.Size == 0 {
+		return info, errors.New("attachment " + name + " is empty")
+	}
+	info.Chunks = len(chunks)
+	info.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	infoValue, err := srv.encryptJSON(info)
+	if err != nil {
+		return info, err
+	}
+
+	err = srv.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+		if bucket == nil {
+			return errors.New("password bucket not found")
+		}
+		if bucket.Get([]byte(key)) == nil {
+			return notFoundError(key)
+		}
+		rootBucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.AttachmentBucketName))
+		if err != nil {
+			return err
+		}
+		keyBucket, err := rootBucket.CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+		if keyBucket.Bucket([]byte(name)) != nil {
+			if !overwrite {
+				return errors.New("attachment " + name + " of key:" + key + " already exists")
+			}
+			if err := keyBucket.DeleteBucket([]byte(name)); err != nil {
+				return err
+			}
+		} else if countBuckets(keyBucket) >= MaxAttachments {
+			return errors.New("key:" + key + " already has " + strconv.Itoa(MaxAttachments) + " attachments")
+		}
+		attachmentBucket, err := keyBucket.CreateBucket([]byte(name))
+		if err != nil {
+			return err
+		}
+		if err := attachmentBucket.Put([]byte(attachmentInfoKey), infoValue); err != nil {
+			return err
+		}
+		for i, chunk := range chunks {
+			if err := attachmentBucket.Put(chunkKey(i), chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("add attachment failed:", zap.Error(err))
+		return info, err
+	}
+	return info, nil
+}
+
+// ListAttachments 返回 key 的所有附件信息，按名称排序
+func (srv *PasswordService) ListAttachments(key string) ([]AttachmentInfo, error) {
+	var infos []AttachmentInfo
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		keyBucket := srv.attachmentBucketWithTx(key, tx)
+		if keyBucket == nil {
+			return nil
+		}
+		return keyBucket.ForEachBucket(func(name []byte) error {
+			info, err := srv.attachmentInfoWithTx(keyBucket.Bucket(name))
+			if err != nil {
+				return err
+			}
+			infos = append(infos, info)
+			return nil
+		})
+	})
+	if err != nil {
+		srv.logger.Error("list attachments failed:", zap.Error(err))
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// GetAttachment 解密并返回附件的内容，内容与保存时的 SHA-256 不一致时返回错误
+func (srv *PasswordService) GetAttachment(key, name string) ([]byte, AttachmentInfo, error) {
+	var info AttachmentInfo
+	var content bytes.Buffer
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		keyBucket := srv.attachmentBucketWithTx(key, tx)
+		var attachmentBucket *bbolt.Bucket
+		if keyBucket != nil {
+			attachmentBucket = keyBucket.Bucket([]byte(name))
+		}
+		if attachmentBucket == nil {
+			return errors.New("attachment " + name + " of key:" + key + " not found")
+		}
+		var err error
+		info, err = srv.attachmentInfoWithTx(attachmentBucket)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < info.Chunks; i++ {
+			value := attachmentBucket.Get(chunkKey(i))
+			if value == nil {
+				return errors.New("attachment " + name + " is corrupted: chunk " + strconv.Itoa(i) + " is missing")
+			}
+			chunk, err := srv.decryptValue(value)
+			if err != nil {
+				return err
+			}
+			content.Write(chunk)
+		}
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("get attachment failed:", zap.Error(err))
+		return nil, info, err
+	}
+	sum := sha256.Sum256(content.Bytes())
+	if int64(content.Len()) != info.Size || hex.EncodeToString(sum[:]) != info.SHA256 {
+		return nil, info, errors.New("attachment " + name + " is corrupted: checksum mismatch")
+	}
+	return content.Bytes(), info, nil
+}
+
+// DeleteAttachment 删除 key 的一个附件
+func (srv *PasswordService) DeleteAttachment(key, name string) error {
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		keyBucket := srv.attachmentBucketWithTx(key, tx)
+		if keyBucket == nil || keyBucket.Bucket([]byte(name)) == nil {
+			return errors.New("attachment " + name + " of key:" + key + " not found")
+		}
+		if err := keyBucket.DeleteBucket([]byte(name)); err != nil {
+			return err
+		}
+		//没有任何附件时删除子 bucket
+		if countBuckets(keyBucket) == 0 {
+			return tx.Bucket([]byte(dbfilekit.AttachmentBucketName)).DeleteBucket([]byte(key))
+		}
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("delete attachment failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// attachmentBucketWithTx 返回 key 的附件子 bucket，不存在时返回nil
+func (srv *PasswordService) attachmentBucketWithTx(key string, tx *bbolt.Tx) *bbolt.Bucket {
+	rootBucket := tx.Bucket([]byte(dbfilekit.AttachmentBucketName))
+	if rootBucket == nil {
+		// 旧版本的数据库没有attachments bucket
+		return nil
+	}
+	return rootBucket.Bucket([]byte(key))
+}
+
+// attachmentInfoWithTx 解密附件信息
+func (srv *PasswordService) attachmentInfoWithTx(attachmentBucket *bbolt.Bucket) (AttachmentInfo, error) {
+	var info AttachmentInfo
+	value := attachmentBucket.Get([]byte(attachmentInfoKey))
+	if value == nil {
+		return info, errors.New("attachment info not found")
+	}
+	plain, err := srv.decryptValue(value)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(plain, &info); err != nil {
+		srv.logger.Error("unmarshal attachment info failed:", zap.Error(err))
+		return info, err
+	}
+	return info, nil
+}
+
+// moveAttachmentsWithTx key 改名时把附件移动到新的 key
+func (srv *PasswordService) moveAttachmentsWithTx(key, newKey string, tx *bbolt.Tx) error {
+	keyBucket := srv.attachmentBucketWithTx(key, tx)
+	if keyBucket == nil {
+		return nil
+	}
+	rootBucket := tx.Bucket([]byte(dbfilekit.AttachmentBucketName))
+	if rootBucket.Bucket([]byte(newKey)) != nil {
+		if err := rootBucket.DeleteBucket([]byte(newKey)); err != nil {
+			return err
+		}
+	}
+	newBucket, err := rootBucket.CreateBucket([]byte(newKey))
+	if err != nil {
+		return err
+	}
+	if err := copyBucket(keyBucket, newBucket); err != nil {
+		return err
+	}
+	return srv.deleteAttachmentsWithTx(key, tx)
+}
+
+// deleteAttachmentsWithTx 删除 key 的所有附件
+func (srv *PasswordService) deleteAttachmentsWithTx(key string, tx *bbolt.Tx) error {
+	if srv.attachmentBucketWithTx(key, tx) == nil {
+		return nil
+	}
+	return tx.Bucket([]byte(dbfilekit.AttachmentBucketName)).DeleteBucket([]byte(key))
+}
+
+// encryptJSON 把 v 编码为 JSON 后加密，返回 nonce 和密文
+func (srv *PasswordService) encryptJSON(v any) ([]byte, error) {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	cipherData, nonce, err := srv.aesSrv.Encrypt(string(plain))
+	if err != nil {
+		srv.logger.Error("encrypt failed:", zap.Error(err))
+		return nil, err
+	}
+	return append(nonce, cipherData...), nil
+}
+
+// chunkKey 分块的 key，大端序保证按顺序遍历
+func chunkKey(i int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(i))
+	return key
+}
+
+// countBuckets 返回子 bucket 的数量
+func countBuckets(bucket *bbolt.Bucket) int {
+	count := 0
+	bucket.ForEachBucket(func([]byte) error {
+		count++
+		return nil
+	})
+	return count
+}
+
+// copyBucket 递归复制 bucket 中的所有内容
+func copyBucket(src, dst *bbolt.Bucket) error {
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(append([]byte(nil), k...), append([]byte(nil), v...))
+		}
+		child, err := dst.CreateBucket(append([]byte(nil), k...))
+		if err != nil {
+			return err
+		}
+		return copyBucket(src.Bucket(k), child)
+	})
+}
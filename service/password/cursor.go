@@ -0,0 +1,296 @@
+package password
+
+import (
+	"bytes"
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"strconv"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// Cursor 按 key 的顺序遍历条目，只读取平台和附加信息等不需要解密的内容，类型只解密类型本身，
+// 密码在调用 Password 或 Decrypt 时才解密。Cursor 持有一个只读事务，用完需要调用 Close
+//
+//	cursor, err := srv.NewCursor()
+//	defer cursor.Close()
+//	for cursor.Next() {
+//		entry := cursor.Entry()
+//	}
+//	err = cursor.Err()
+type Cursor struct {
+	srv    *PasswordService
+	tx     *bbolt.Tx
+	cursor *bbolt.Cursor
+	// 平台长度、附加信息、类型、时间和使用记录与密码使用相同的 key，按顺序同步遍历，不需要每个条目查找一次
+	platforms *sideCursor
+	metas     *sideCursor
+	kinds     *sideCursor
+	modified  *sideCursor
+	created   *sideCursor
+	access    *sideCursor
+	// seek 不为空时下一次 Next 从这里开始
+	seek    []byte
+	started bool
+	value   []byte
+	entry   PasswordData
+	err     error
+}
+
+// NewCursor 打开一个遍历所有条目的 Cursor
+func (srv *PasswordService) NewCursor() (*Cursor, error) {
+	tx, err := srv.db.Begin(false)
+	if err != nil {
+		srv.logger.Error("begin read transaction failed:", zap.Error(err))
+		return nil, err
+	}
+	bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+	if bucket == nil {
+		tx.Rollback()
+		srv.logger.Error("password bucket not found")
+		return nil, errors.New("password bucket not found")
+	}
+	platforms := tx.Bucket([]byte(dbfilekit.PlatformLenBucketName))
+	if platforms == nil {
+		tx.Rollback()
+		srv.logger.Error("platform bucket not found")
+		return nil, errors.New("platform bucket not found")
+	}
+	return &Cursor{
+		srv:       srv,
+		tx:        tx,
+		cursor:    bucket.Cursor(),
+		platforms: newSideCursor(platforms),
+		metas:     newSideCursor(tx.Bucket([]byte(dbfilekit.MetaBucketName))),
+		kinds:     newSideCursor(tx.Bucket([]byte(dbfilekit.KindBucketName))),
+		modified:  newSideCursor(tx.Bucket([]byte(dbfilekit.ModifiedBucketName))),
+		created:   newSideCursor(tx.Bucket([]byte(dbfilekit.CreatedBucketName))),
+		access:    newSideCursor(tx.Bucket([]byte(dbfilekit.AccessBucketName))),
+	}, nil
+}
+
+// Seek 让下一次 Next 从第一个不小于 key 的条目开始，可以用来分页或者按前缀遍历
+func (c *Cursor) Seek(key string) {
+	c.seek = []byte(key)
+}
+
+// Next 移动到下一个条目，没有更多条目或者出错时返回 false
+func (c *Cursor) Next() bool {
+	if c.err != nil || c.tx == nil {
+		return false
+	}
+	var k, v []byte
+	switch {
+	case c.seek != nil:
+		k, v = c.cursor.Seek(c.seek)
+		c.seek = nil
+		c.platforms.reset()
+		c.metas.reset()
+		c.kinds.reset()
+		c.modified.reset()
+		c.created.reset()
+		c.access.reset()
+	case !c.started:
+		k, v = c.cursor.First()
+	default:
+		k, v = c.cursor.Next()
+	}
+	c.started = true
+	if k == nil {
+		return false
+	}
+	c.entry, c.value, c.err = c.readEntry(k, v)
+	return c.err == nil
+}
+
+// Entry 当前条目不需要解密的信息，Password、Envs 和 Fields 为空
+func (c *Cursor) Entry() PasswordData {
+	return c.entry
+}
+
+// Password 解密当前条目的密码
+func (c *Cursor) Password() (string, error) {
+	if c.value == nil {
+		return "", errors.New("cursor is not positioned on an entry")
+	}
+	password, err := c.srv.decryptValue(c.value)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// Decrypt 解密当前条目的密码、各个环境的密码和类型的其他字段，返回完整的条目
+func (c *Cursor) Decrypt() (PasswordData, error) {
+	data := c.entry
+	password, err := c.Password()
+	if err != nil {
+		return data, err
+	}
+	data.Password = password
+	//获取各个环境的密码
+	if data.Envs, err = c.srv.getEnvPasswordsWithTx(data.Key, c.tx); err != nil {
+		return data, err
+	}
+	//获取类型的其他字段
+	if data.Fields, err = c.srv.getFieldsWithTx(data.Key, c.tx); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// Err 返回遍历过程中的错误
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close 结束只读事务，之后 Next 总是返回 false
+func (c *Cursor) Close() error {
+	if c.tx == nil {
+		return nil
+	}
+	err := c.tx.Rollback()
+	c.tx = nil
+	c.value = nil
+	return err
+}
+
+// readEntry 读取条目的平台、附加信息、类型、时间和使用记录，返回不含平台的加密值
+func (c *Cursor) readEntry(k, v []byte) (PasswordData, []byte, error) {
+	key := string(k)
+	//没有平台长度的旧条目没有平台信息
+	platformLen := 0
+	if platformLenByte := c.platforms.get(k); platformLenByte != nil {
+		var err error
+		if platformLen, err = strconv.Atoi(string(platformLenByte)); err != nil {
+			c.srv.logger.Error("convert platformLen failed:", zap.Error(err))
+			return PasswordData{}, nil, err
+		}
+	}
+	if platformLen > len(v) {
+		return PasswordData{}, nil, errors.New("platform of key:" + key + " is corrupted")
+	}
+	//获取附加信息
+	meta, err := c.srv.decodeMeta(c.metas.get(k))
+	if err != nil {
+		return PasswordData{}, nil, err
+	}
+	//获取类型
+	kind, err := c.srv.decryptKind(key, c.kinds.get(k))
+	if err != nil {
+		return PasswordData{}, nil, err
+	}
+	//获取修改时间
+	modified, err := parseModified(key, c.modified.get(k))
+	if err != nil {
+		return PasswordData{}, nil, err
+	}
+	created, err := parseTimestamp("created", key, c.created.get(k))
+	if err != nil {
+		return PasswordData{}, nil, err
+	}
+	access, err := parseAccess(key, c.access.get(k))
+	if err != nil {
+		return PasswordData{}, nil, err
+	}
+	return PasswordData{
+		Key:          key,
+		Platform:     string(v[:platformLen]),
+		Username:     meta.Username,
+		URL:          meta.URL,
+		Tags:         meta.Tags,
+		Folder:       meta.Folder,
+		Type:         kind,
+		Modified:     modified,
+		Created:      created,
+		LastUsed:     access.LastUsed,
+		UseCount:     access.Count,
+		ExpiresAt:    meta.ExpiresAt,
+		RotationDays: meta.RotationDays,
+	}, v[platformLen:], nil
+}
+
+// sideCursor 按 key 的顺序遍历另一个 bucket，查找的 key 递增时只需要向后移动
+type sideCursor struct {
+	cursor  *bbolt.Cursor
+	key     []byte
+	value   []byte
+	started bool
+}
+
+// newSideCursor 旧版本的数据库可能没有这个 bucket，此时返回 nil，查找总是返回 nil
+func newSideCursor(bucket *bbolt.Bucket) *sideCursor {
+	if bucket == nil {
+		return nil
+	}
+	return &sideCursor{cursor: bucket.Cursor()}
+}
+
+// get 返回 key 对应的值，没有时返回 nil
+func (s *sideCursor) get(key []byte) []byte {
+	if s == nil {
+		return nil
+	}
+	if !s.started || bytes.Compare(s.key, key) > 0 {
+		s.key, s.value = s.cursor.Seek(key)
+		s.started = true
+	}
+	for s.key != nil && bytes.Compare(s.key, key) < 0 {
+		s.key, s.value = s.cursor.Next()
+	}
+	if s.key == nil || !bytes.Equal(s.key, key) {
+		return nil
+	}
+	return s.value
+}
+
+// reset 主 cursor 跳转之后重新定位
+func (s *sideCursor) reset() {
+	if s != nil {
+		s.started = false
+	}
+}
+
+// GetEntry 返回 key 的条目中不需要解密的信息，与 Cursor.Entry 相同，Password、Envs 和 Fields 为空
+func (srv *PasswordService) GetEntry(key string) (PasswordData, error) {
+	cursor, err := srv.NewCursor()
+	if err != nil {
+		return PasswordData{}, err
+	}
+	defer cursor.Close()
+	cursor.Seek(key)
+	if !cursor.Next() || cursor.Entry().Key != key {
+		if err := cursor.Err(); err != nil {
+			return PasswordData{}, err
+		}
+		return PasswordData{}, notFoundError(key)
+	}
+	return cursor.Entry(), nil
+}
+
+// GetPasswordsWithKeys 只解密指定的条目，返回的顺序与 keys 相同，某个 key 不存在时返回错误
+func (srv *PasswordService) GetPasswordsWithKeys(keys ...string) ([]PasswordData, error) {
+	cursor, err := srv.NewCursor()
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+	result := make([]PasswordData, 0, len(keys))
+	for _, key := range keys {
+		cursor.Seek(key)
+		if !cursor.Next() || cursor.Entry().Key != key {
+			if err := cursor.Err(); err != nil {
+				return nil, err
+			}
+			return nil, notFoundError(key)
+		}
+		data, err := cursor.Decrypt()
+		if err != nil {
+			srv.logger.Error("decrypt entry failed:", zap.Error(err))
+			return nil, err
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
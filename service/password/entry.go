@@ -0,0 +1,120 @@
+package password
+
+import (
+	dbfilekit "password_manager/service/dbfile_Kit"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// EntryUpdate 一次修改条目的内容，空字符串和 nil 表示保持不变
+type EntryUpdate struct {
+	// Password 新的密码，带类型的条目是该类型的主字段
+	Password string
+	Platform string
+	// Key 新的 key
+	Key      string
+	Username *string
+	URL      *string
+	Tags     *[]string
+}
+
+// SaveEntry 保存新的条目和附加信息，key 已经存在时返回错误
+func (srv *PasswordService) SaveEntry(key, password, platform string, meta Meta) error {
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return ErrKeyEmpty
+	}
+	if password == "" {
+		srv.logger.Error("password is empty")
+		return ErrPasswordEmpty
+	}
+	encryptedValue, platformLenByte, err := srv.encodeValue(password, platform)
+	if err != nil {
+		return err
+	}
+	//密码和附加信息在同一个事务中写入，失败时不会留下不完整的条目
+	err = srv.db.Update(func(tx *bbolt.Tx) error {
+		if srv.existsWithTx(key, tx) {
+			return existsError(key)
+		}
+		if err := srv.putPasswordWithTx(key, password, encryptedValue, platformLenByte, tx); err != nil {
+			return err
+		}
+		return srv.putMetaWithTx(key, meta, tx)
+	})
+	if err != nil {
+		srv.logger.Error("save entry failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateEntry 在同一个事务中修改条目的密码、平台、key 和附加信息，任何一步失败时都不修改。
+// 带类型的条目通过类型检查修改主字段、用户名和 URL，见 UpdateRecord
+func (srv *PasswordService) UpdateEntry(key string, update EntryUpdate) (UpdateResult, error) {
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return UpdateResult{}, ErrKeyEmpty
+	}
+	var result UpdateResult
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		if !srv.existsWithTx(key, tx) {
+			return notFoundError(key)
+		}
+		kindName, err := srv.getKindWithTx(key, tx)
+		if err != nil {
+			return err
+		}
+		newPassword := update.Password
+		if kindName != "" {
+			kind, err := LookupKind(kindName)
+			if err != nil {
+				return err
+			}
+			changes := make(map[string]string)
+			if update.Password != "" {
+				changes[kind.Primary] = update.Password
+			}
+			if update.Username != nil {
+				changes[FieldUsername] = *update.Username
+			}
+			if update.URL != nil {
+				changes[FieldURL] = *update.URL
+			}
+			if err := srv.updateRecordWithTx(key, changes, tx); err != nil {
+				return err
+			}
+			newPassword = ""
+		}
+		meta, err := srv.getMetaWithTx(key, tx)
+		if err != nil {
+			return err
+		}
+		if update.Username != nil {
+			meta.Username = *update.Username
+		}
+		if update.URL != nil {
+			meta.URL = *update.URL
+		}
+		if update.Tags != nil {
+			meta.Tags = *update.Tags
+		}
+		if err := srv.putMetaWithTx(key, meta, tx); err != nil {
+			return err
+		}
+		result, err = srv.updatePasswordWithTx(key, newPassword, update.Platform, update.Key, tx)
+		return err
+	})
+	if err != nil {
+		srv.logger.Error("update entry failed:", zap.Error(err))
+		return UpdateResult{}, err
+	}
+	return result, nil
+}
+
+// existsWithTx 使用tx判断 key 是否存在
+func (srv *PasswordService) existsWithTx(key string, tx *bbolt.Tx) bool {
+	bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+	return bucket != nil && bucket.Get([]byte(key)) != nil
+}
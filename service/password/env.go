@@ -0,0 +1,230 @@
+package password
+
+import (
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"sort"
+	"strings"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// ValidateEnv 检查环境名，只能包含字母、数字、- 和 _
+func ValidateEnv(env string) error {
+	if env == "" {
+		return emptyError("environment")
+	}
+	for _, r := range env {
+		switch {
+		case r == '-', r == '_', r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		default:
+			return errors.New("invalid environment " + env + ", only letters, digits, - and _ are allowed")
+		}
+	}
+	return nil
+}
+
+// SetEnvPassword 设置 key 在指定环境下的密码，key 必须已经存在
+// 每个环境的密码单独加密，存放在 envs bucket 下以 key 命名的子 bucket 中
+func (srv *PasswordService) SetEnvPassword(key, env, password string) error {
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return ErrKeyEmpty
+	}
+	if err := ValidateEnv(env); err != nil {
+		return err
+	}
+	if password == "" {
+		srv.logger.Error("password is empty")
+		return ErrPasswordEmpty
+	}
+	cipherPassword, nonce, err := srv.aesSrv.Encrypt(password)
+	if err != nil {
+		srv.logger.Error("encrypt password failed:", zap.Error(err))
+		return err
+	}
+	value := append(nonce, cipherPassword...)
+	err = srv.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+		if bucket == nil {
+			return errors.New("password bucket not found")
+		}
+		if bucket.Get([]byte(key)) == nil {
+			return notFoundError(key)
+		}
+		envBucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.EnvBucketName))
+		if err != nil {
+			return err
+		}
+		keyBucket, err := envBucket.CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+		return keyBucket.Put([]byte(env), value)
+	})
+	if err != nil {
+		srv.logger.Error("set env password failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetEnvPassword 获取 key 在指定环境下的密码
+func (srv *PasswordService) GetEnvPassword(key, env string) (string, error) {
+	var value []byte
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		keyBucket := srv.envBucketWithTx(key, tx)
+		if keyBucket != nil {
+			value = keyBucket.Get([]byte(env))
+		}
+		if value == nil {
+			return errors.New("environment " + env + " not found for key:" + key)
+		}
+		//bbolt 返回的切片只在事务中有效
+		value = append([]byte(nil), value...)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	password, err := srv.decryptValue(value)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// GetPasswordForEnv 获取 key 在指定环境下使用的密码和平台信息
+// env 为空或者 key 没有任何环境的密码时返回默认密码，
+// key 有其他环境的密码但没有 env 时返回错误，避免误用其他环境的值
+func (srv *PasswordService) GetPasswordForEnv(key, env string) (string, string, error) {
+	password, platform, err := srv.readPassword(key)
+	if err != nil || env == "" {
+		return password, platform, err
+	}
+	envs, err := srv.GetEnvs(key)
+	if err != nil {
+		return "", "", err
+	}
+	if len(envs) == 0 {
+		return password, platform, nil
+	}
+	password, err = srv.GetEnvPassword(key, env)
+	if err != nil {
+		return "", "", errors.New(err.Error() + ", available: " + strings.Join(envs, ", "))
+	}
+	return password, platform, nil
+}
+
+// GetEnvs 返回 key 设置了密码的环境，按名称排序
+func (srv *PasswordService) GetEnvs(key string) ([]string, error) {
+	var envs []string
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		keyBucket := srv.envBucketWithTx(key, tx)
+		if keyBucket == nil {
+			return nil
+		}
+		return keyBucket.ForEach(func(k, _ []byte) error {
+			envs = append(envs, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		srv.logger.Error("get envs failed:", zap.Error(err))
+		return nil, err
+	}
+	sort.Strings(envs)
+	return envs, nil
+}
+
+// DeleteEnvPassword 删除 key 在指定环境下的密码
+func (srv *PasswordService) DeleteEnvPassword(key, env string) error {
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		keyBucket := srv.envBucketWithTx(key, tx)
+		if keyBucket == nil || keyBucket.Get([]byte(env)) == nil {
+			return errors.New("environment " + env + " not found for key:" + key)
+		}
+		if err := keyBucket.Delete([]byte(env)); err != nil {
+			return err
+		}
+		//没有任何环境时删除子 bucket
+		if k, _ := keyBucket.Cursor().First(); k == nil {
+			return tx.Bucket([]byte(dbfilekit.EnvBucketName)).DeleteBucket([]byte(key))
+		}
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("delete env password failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// envBucketWithTx 返回 key 的环境子 bucket，不存在时返回nil
+func (srv *PasswordService) envBucketWithTx(key string, tx *bbolt.Tx) *bbolt.Bucket {
+	envBucket := tx.Bucket([]byte(dbfilekit.EnvBucketName))
+	if envBucket == nil {
+		// 旧版本的数据库没有envs bucket
+		return nil
+	}
+	return envBucket.Bucket([]byte(key))
+}
+
+// getEnvPasswordsWithTx 解密 key 所有环境的密码，没有时返回nil
+func (srv *PasswordService) getEnvPasswordsWithTx(key string, tx *bbolt.Tx) (map[string]string, error) {
+	keyBucket := srv.envBucketWithTx(key, tx)
+	if keyBucket == nil {
+		return nil, nil
+	}
+	envs := make(map[string]string)
+	err := keyBucket.ForEach(func(k, v []byte) error {
+		password, err := srv.decryptValue(v)
+		if err != nil {
+			return err
+		}
+		envs[string(k)] = string(password)
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("decrypt env passwords failed:", zap.Error(err))
+		return nil, err
+	}
+	if len(envs) == 0 {
+		return nil, nil
+	}
+	return envs, nil
+}
+
+// moveEnvsWithTx key 改名时把环境的密码移动到新的 key
+func (srv *PasswordService) moveEnvsWithTx(key, newKey string, tx *bbolt.Tx) error {
+	keyBucket := srv.envBucketWithTx(key, tx)
+	if keyBucket == nil {
+		return nil
+	}
+	envBucket := tx.Bucket([]byte(dbfilekit.EnvBucketName))
+	if envBucket.Bucket([]byte(newKey)) != nil {
+		if err := envBucket.DeleteBucket([]byte(newKey)); err != nil {
+			return err
+		}
+	}
+	newBucket, err := envBucket.CreateBucket([]byte(newKey))
+	if err != nil {
+		return err
+	}
+	err = keyBucket.ForEach(func(k, v []byte) error {
+		return newBucket.Put(append([]byte(nil), k...), append([]byte(nil), v...))
+	})
+	if err != nil {
+		return err
+	}
+	return srv.deleteEnvsWithTx(key, tx)
+}
+
+// deleteEnvsWithTx 删除 key 所有环境的密码
+func (srv *PasswordService) deleteEnvsWithTx(key string, tx *bbolt.Tx) error {
+	if srv.envBucketWithTx(key, tx) == nil {
+		return nil
+	}
+	return tx.Bucket([]byte(dbfilekit.EnvBucketName)).DeleteBucket([]byte(key))
+}
@@ -0,0 +1,49 @@
+package password
+
+import "errors"
+
+// 密码服务返回的错误分类，返回的错误带有 key 等具体信息，用 errors.Is 判断属于哪一类
+var (
+	// ErrNotFound key 不存在
+	ErrNotFound = errors.New("not found")
+	// ErrExists key 已经存在
+	ErrExists = errors.New("already exists")
+	// ErrEmpty 必填的参数为空
+	ErrEmpty = errors.New("is empty")
+)
+
+var (
+	// ErrKeyEmpty key 为空
+	ErrKeyEmpty error = &serviceError{message: "key is empty", kind: ErrEmpty}
+	// ErrPasswordEmpty 密码为空
+	ErrPasswordEmpty error = &serviceError{message: "password is empty", kind: ErrEmpty}
+)
+
+// serviceError 错误信息与分类分开保存，Unwrap 返回 ErrNotFound 等分类
+type serviceError struct {
+	message string
+	kind    error
+}
+
+func (e *serviceError) Error() string {
+	return e.message
+}
+
+func (e *serviceError) Unwrap() error {
+	return e.kind
+}
+
+// notFoundError key 不存在的错误
+func notFoundError(key string) error {
+	return &serviceError{message: "key:" + key + " not found", kind: ErrNotFound}
+}
+
+// existsError key 已经存在的错误
+func existsError(key string) error {
+	return &serviceError{message: "key:" + key + " already exists", kind: ErrExists}
+}
+
+// emptyError 参数为空的错误，name 是参数的名字
+func emptyError(name string) error {
+	return &serviceError{message: name + " is empty", kind: ErrEmpty}
+}
@@ -0,0 +1,103 @@
+package password
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ExpiryDateLayout 到期日期的格式
+const ExpiryDateLayout = "2006-01-02"
+
+// DueEntry 需要轮换的条目
+type DueEntry struct {
+	Key string `json:"key"`
+	// Due 需要轮换的时间，设置了轮换周期但没有修改时间的旧条目为零值
+	Due time.Time `json:"due"`
+	// Expired 已经过了轮换时间
+	Expired bool `json:"expired"`
+}
+
+// ParseExpiryDate 解析到期日期，日期按本地时区的零点计算
+func ParseExpiryDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation(ExpiryDateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("invalid expiry date " + value + ", expected e.g. 2026-12-31")
+	}
+	return date, nil
+}
+
+// DueDate 返回需要轮换的时间，没有设置到期日期和轮换周期时返回 false
+// 两者都设置时取较早的一个，设置了轮换周期但没有修改时间的旧条目返回零值，即已经到期
+func (meta Meta) DueDate(modified time.Time) (time.Time, bool) {
+	var due time.Time
+	found := false
+	if meta.ExpiresAt != "" {
+		date, err := ParseExpiryDate(meta.ExpiresAt)
+		if err == nil {
+			due, found = date, true
+		}
+	}
+	if meta.RotationDays > 0 {
+		rotation := time.Time{}
+		if !modified.IsZero() {
+			rotation = modified.AddDate(0, 0, meta.RotationDays)
+		}
+		if !found || rotation.Before(due) {
+			due, found = rotation, true
+		}
+	}
+	return due, found
+}
+
+// DueDate 返回条目需要轮换的时间，见 Meta.DueDate
+func (data PasswordData) DueDate() (time.Time, bool) {
+	meta := Meta{ExpiresAt: data.ExpiresAt, RotationDays: data.RotationDays}
+	return meta.DueDate(data.Modified)
+}
+
+// Expired 条目在 now 时是否已经过了轮换时间
+func (data PasswordData) Expired(now time.Time) bool {
+	due, ok := data.DueDate()
+	return ok && !now.Before(due)
+}
+
+// SetExpiry 设置到期日期和轮换周期，都为空时条目不会到期
+func (srv *PasswordService) SetExpiry(key, expiresAt string, rotationDays int) error {
+	if expiresAt != "" {
+		if _, err := ParseExpiryDate(expiresAt); err != nil {
+			return err
+		}
+	}
+	if rotationDays < 0 {
+		return errors.New("invalid rotation interval " + strconv.Itoa(rotationDays) + " days")
+	}
+	meta, err := srv.GetMeta(key)
+	if err != nil {
+		return err
+	}
+	meta.ExpiresAt = expiresAt
+	meta.RotationDays = rotationDays
+	return srv.SetMeta(key, meta)
+}
+
+// DueEntries 返回 now 之后 within 时间内需要轮换的条目，包括已经到期的，按轮换时间排序
+func DueEntries(entries map[string]PasswordData, within time.Duration, now time.Time) []DueEntry {
+	deadline := now.Add(within)
+	var due []DueEntry
+	for key, data := range entries {
+		date, ok := data.DueDate()
+		if !ok || date.After(deadline) {
+			continue
+		}
+		due = append(due, DueEntry{Key: key, Due: date, Expired: !now.Before(date)})
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].Due.Equal(due[j].Due) {
+			return due[i].Due.Before(due[j].Due)
+		}
+		return due[i].Key < due[j].Key
+	})
+	return due
+}
@@ -0,0 +1,163 @@
+package password
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// FolderSeparator 文件夹路径的分隔符
+const FolderSeparator = "/"
+
+// Filter 按标签和文件夹筛选条目，字段为空时不筛选
+type Filter struct {
+	// Tags 条目需要带有所有这些标签
+	Tags []string
+	// Folder 条目需要在这个文件夹或者它的子文件夹中
+	Folder string
+}
+
+// NormalizeFolder 规范化文件夹路径，去掉首尾和重复的分隔符，根目录返回空
+// 路径中不能包含 . 和 ..
+func NormalizeFolder(folder string) (string, error) {
+	var parts []string
+	for _, part := range strings.Split(folder, FolderSeparator) {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "":
+			continue
+		case ".", "..":
+			return "", errors.New("invalid folder " + folder + ", . and .. are not allowed")
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, FolderSeparator), nil
+}
+
+// InFolder folder 是否是 parent 或者 parent 的子文件夹，parent 为空时总是返回 true
+func InFolder(folder, parent string) bool {
+	if parent == "" || folder == parent {
+		return true
+	}
+	return strings.HasPrefix(folder, parent+FolderSeparator)
+}
+
+// NormalizeTags 去掉标签两端的空白、空标签和重复的标签，保持原来的顺序
+func NormalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !HasTag(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// HasTag tags 中是否包含 tag
+func HasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Match 条目是否满足筛选条件
+func (filter Filter) Match(data PasswordData) bool {
+	for _, tag := range filter.Tags {
+		if !HasTag(data.Tags, tag) {
+			return false
+		}
+	}
+	return InFolder(data.Folder, filter.Folder)
+}
+
+// Empty 是否没有任何筛选条件
+func (filter Filter) Empty() bool {
+	return len(filter.Tags) == 0 && filter.Folder == ""
+}
+
+// Apply 返回满足筛选条件的条目
+func (filter Filter) Apply(entries map[string]PasswordData) map[string]PasswordData {
+	if filter.Empty() {
+		return entries
+	}
+	result := make(map[string]PasswordData)
+	for key, data := range entries {
+		if filter.Match(data) {
+			result[key] = data
+		}
+	}
+	return result
+}
+
+// AddTags 给条目加上标签，已有的标签不会重复添加
+func (srv *PasswordService) AddTags(key string, tags ...string) error {
+	tags = NormalizeTags(tags)
+	if len(tags) == 0 {
+		return errors.New("no tag given")
+	}
+	meta, err := srv.GetMeta(key)
+	if err != nil {
+		return err
+	}
+	meta.Tags = NormalizeTags(append(meta.Tags, tags...))
+	return srv.SetMeta(key, meta)
+}
+
+// RemoveTags 删除条目的标签，条目没有其中某个标签时返回错误
+func (srv *PasswordService) RemoveTags(key string, tags ...string) error {
+	tags = NormalizeTags(tags)
+	if len(tags) == 0 {
+		return errors.New("no tag given")
+	}
+	meta, err := srv.GetMeta(key)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if !HasTag(meta.Tags, tag) {
+			return errors.New("key:" + key + " has no tag " + tag)
+		}
+	}
+	var kept []string
+	for _, tag := range meta.Tags {
+		if !HasTag(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	meta.Tags = kept
+	return srv.SetMeta(key, meta)
+}
+
+// MoveToFolder 把条目移动到文件夹，folder 为空或者 / 时移动到根目录
+func (srv *PasswordService) MoveToFolder(key, folder string) error {
+	folder, err := NormalizeFolder(folder)
+	if err != nil {
+		return err
+	}
+	meta, err := srv.GetMeta(key)
+	if err != nil {
+		return err
+	}
+	meta.Folder = folder
+	return srv.SetMeta(key, meta)
+}
+
+// TagCounts 返回所有标签和使用它们的条目数量，按标签排序
+func TagCounts(entries map[string]PasswordData) ([]string, map[string]int) {
+	counts := make(map[string]int)
+	for _, data := range entries {
+		for _, tag := range data.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, counts
+}
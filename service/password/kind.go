@@ -0,0 +1,288 @@
+package password
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// 条目类型，普通密码的类型为空，与 TypeLogin 相同
+const (
+	TypeLogin    = "login"
+	TypeAPIKey   = "api_key"
+	TypeCard     = "card"
+	TypeIdentity = "identity"
+	TypeSSHKey   = "ssh_key"
+	TypeDatabase = "database"
+	// TypeNote 安全笔记类型的条目，密码字段保存多行的笔记内容
+	TypeNote = "note"
+)
+
+// 所有类型共用的字段名，username 和 url 保存在附加信息中，可以被搜索
+const (
+	FieldUsername = "username"
+	FieldURL      = "url"
+)
+
+// Field 条目类型中的一个字段
+type Field struct {
+	Name  string
+	Label string
+	// Secret 输入时隐藏
+	Secret bool
+	// Required 必须填写
+	Required bool
+	// Multiline 多行内容，例如私钥和笔记
+	Multiline bool
+	// Validate 检查字段的值，值为空时不调用
+	Validate func(value string) error
+}
+
+// Kind 条目类型的字段定义
+type Kind struct {
+	Name  string
+	Label string
+	// Primary 保存在密码位置的字段，必须是 Required
+	Primary string
+	Fields  []Field
+}
+
+// kinds 所有的条目类型
+var kinds = map[string]Kind{
+	TypeLogin: {
+		Name: TypeLogin, Label: "Login", Primary: "password",
+		Fields: []Field{
+			{Name: FieldUsername, Label: "Username"},
+			{Name: "password", Label: "Password", Secret: true, Required: true},
+			{Name: FieldURL, Label: "URL", Validate: ValidateURL},
+		},
+	},
+	TypeAPIKey: {
+		Name: TypeAPIKey, Label: "API key", Primary: "key",
+		Fields: []Field{
+			{Name: "key", Label: "Key", Secret: true, Required: true},
+			{Name: "client_id", Label: "Client ID"},
+			{Name: FieldURL, Label: "URL", Validate: ValidateURL},
+			{Name: "expires", Label: "Expires (YYYY-MM-DD)", Validate: ValidateDate},
+		},
+	},
+	TypeCard: {
+		Name: TypeCard, Label: "Credit card", Primary: "number",
+		Fields: []Field{
+			{Name: "cardholder", Label: "Cardholder", Required: true},
+			{Name: "number", Label: "Number", Secret: true, Required: true, Validate: ValidateCardNumber},
+			{Name: "expiry", Label: "Expiry (MM/YY)", Required: true, Validate: ValidateExpiry},
+			{Name: "cvv", Label: "CVV", Secret: true, Validate: validateDigits(3, 4)},
+			{Name: "pin", Label: "PIN", Secret: true, Validate: validateDigits(4, 12)},
+		},
+	},
+	TypeIdentity: {
+		Name: TypeIdentity, Label: "Identity", Primary: "document_number",
+		Fields: []Field{
+			{Name: "full_name", Label: "Full name", Required: true},
+			{Name: "document_type", Label: "Document type"},
+			{Name: "document_number", Label: "Document number", Secret: true, Required: true},
+			{Name: "birth_date", Label: "Birth date (YYYY-MM-DD)", Validate: ValidateDate},
+			{Name: "email", Label: "Email", Validate: ValidateEmail},
+			{Name: "phone", Label: "Phone"},
+			{Name: "address", Label: "Address", Multiline: true},
+		},
+	},
+	TypeSSHKey: {
+		Name: TypeSSHKey, Label: "SSH key", Primary: "private_key",
+		Fields: []Field{
+			{Name: "private_key", Label: "Private key", Secret: true, Required: true, Multiline: true, Validate: ValidatePrivateKey},
+			{Name: "public_key", Label: "Public key", Validate: ValidatePublicKey},
+			{Name: "passphrase", Label: "Passphrase", Secret: true},
+			{Name: FieldUsername, Label: "Username"},
+			{Name: "host", Label: "Host"},
+		},
+	},
+	TypeDatabase: {
+		Name: TypeDatabase, Label: "Database", Primary: "password",
+		Fields: []Field{
+			{Name: "engine", Label: "Engine (postgres, mysql, ...)"},
+			{Name: "host", Label: "Host", Required: true},
+			{Name: "port", Label: "Port", Validate: ValidatePort},
+			{Name: "database", Label: "Database"},
+			{Name: FieldUsername, Label: "Username", Required: true},
+			{Name: "password", Label: "Password", Secret: true, Required: true},
+		},
+	},
+	TypeNote: {
+		Name: TypeNote, Label: "Secure note", Primary: "content",
+		Fields: []Field{
+			{Name: "content", Label: "Content", Secret: true, Required: true, Multiline: true},
+		},
+	},
+}
+
+// LookupKind 返回条目类型的定义，空字符串表示 TypeLogin
+func LookupKind(name string) (Kind, error) {
+	if name == "" {
+		name = TypeLogin
+	}
+	kind, ok := kinds[name]
+	if !ok {
+		return Kind{}, errors.New("unknown type " + name + ", expected one of " + strings.Join(KindNames(), ", "))
+	}
+	return kind, nil
+}
+
+// KindNames 返回所有条目类型的名称
+func KindNames() []string {
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Field 返回指定名称的字段
+func (k Kind) Field(name string) (Field, bool) {
+	for _, field := range k.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// Validate 检查字段是否属于该类型、必填字段是否填写以及字段的格式
+func (k Kind) Validate(fields map[string]string) error {
+	for name := range fields {
+		if _, ok := k.Field(name); !ok {
+			return errors.New("unknown field " + name + " for type " + k.Name)
+		}
+	}
+	for _, field := range k.Fields {
+		value := fields[field.Name]
+		if strings.TrimSpace(value) == "" {
+			if field.Required {
+				return errors.New(field.Label + " is required")
+			}
+			continue
+		}
+		if field.Validate != nil {
+			if err := field.Validate(value); err != nil {
+				return errors.New(field.Label + ": " + err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateCardNumber 检查卡号，允许空格和 -，使用 Luhn 算法校验
+func ValidateCardNumber(value string) error {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(value)
+	if len(digits) < 12 || len(digits) > 19 {
+		return errors.New("card number must have 12 to 19 digits")
+	}
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		c := digits[len(digits)-1-i]
+		if c < '0' || c > '9' {
+			return errors.New("card number must only contain digits")
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	if sum%10 != 0 {
+		return errors.New("invalid card number (Luhn check failed)")
+	}
+	return nil
+}
+
+// ValidateExpiry 检查 MM/YY 或 MM/YYYY 格式的有效期
+func ValidateExpiry(value string) error {
+	month, year, ok := strings.Cut(value, "/")
+	m, err := strconv.Atoi(month)
+	if !ok || err != nil || m < 1 || m > 12 {
+		return errors.New("expected MM/YY")
+	}
+	if _, err := strconv.Atoi(year); err != nil || (len(year) != 2 && len(year) != 4) {
+		return errors.New("expected MM/YY")
+	}
+	return nil
+}
+
+// ValidateDate 检查 YYYY-MM-DD 格式的日期
+func ValidateDate(value string) error {
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return errors.New("expected YYYY-MM-DD")
+	}
+	return nil
+}
+
+// ValidateURL 检查 URL，必须包含协议和主机
+func ValidateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("invalid URL " + value + ", expected for example https://example.com")
+	}
+	return nil
+}
+
+// ValidateEmail 检查邮箱地址
+func ValidateEmail(value string) error {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return errors.New("invalid email address " + value)
+	}
+	return nil
+}
+
+// ValidatePort 检查端口号
+func ValidatePort(value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return errors.New("port must be a number between 1 and 65535")
+	}
+	return nil
+}
+
+// ValidatePrivateKey 检查 PEM 格式的私钥，带密码保护的私钥只检查格式
+func ValidatePrivateKey(value string) error {
+	_, err := ssh.ParseRawPrivateKey([]byte(value))
+	var missing *ssh.PassphraseMissingError
+	if err != nil && !errors.As(err, &missing) {
+		return errors.New("invalid PEM private key: " + err.Error())
+	}
+	return nil
+}
+
+// ValidatePublicKey 检查 authorized_keys 格式的公钥
+func ValidatePublicKey(value string) error {
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value)); err != nil {
+		return errors.New("invalid public key: " + err.Error())
+	}
+	return nil
+}
+
+// validateDigits 返回检查数字位数的函数
+func validateDigits(min, max int) func(string) error {
+	return func(value string) error {
+		if len(value) < min || len(value) > max {
+			return errors.New("must have " + strconv.Itoa(min) + " to " + strconv.Itoa(max) + " digits")
+		}
+		for _, r := range value {
+			if r < '0' || r > '9' {
+				return errors.New("must only contain digits")
+			}
+		}
+		return nil
+	}
+}
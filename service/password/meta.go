@@ -0,0 +1,113 @@
+package password
+
+import (
+	"encoding/json"
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// Meta 条目的附加信息，与平台信息一样以明文形式存放，读取时不需要解密
+type Meta struct {
+	Username string   `json:"username,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Folder 条目所在的文件夹，例如 work/aws/prod，根目录为空
+	Folder string `json:"folder,omitempty"`
+	// ExpiresAt 密码的到期日期，格式见 ExpiryDateLayout
+	ExpiresAt string `json:"expires_at,omitempty"`
+	// RotationDays 轮换周期，从最后一次修改密码开始计算
+	RotationDays int `json:"rotation_days,omitempty"`
+}
+
+// SetMeta 设置指定 key 的附加信息
+func (srv *PasswordService) SetMeta(key string, meta Meta) error {
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return ErrKeyEmpty
+	}
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+		if bucket == nil {
+			return errors.New("password bucket not found")
+		}
+		if bucket.Get([]byte(key)) == nil {
+			return notFoundError(key)
+		}
+		return srv.putMetaWithTx(key, meta, tx)
+	})
+	if err != nil {
+		srv.logger.Error("set meta failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetMeta 获取指定 key 的附加信息，没有附加信息时返回空的 Meta
+func (srv *PasswordService) GetMeta(key string) (Meta, error) {
+	var meta Meta
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return meta, ErrKeyEmpty
+	}
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		meta, err = srv.getMetaWithTx(key, tx)
+		return err
+	})
+	if err != nil {
+		srv.logger.Error("get meta failed:", zap.Error(err))
+		return meta, err
+	}
+	return meta, nil
+}
+
+// getMetaWithTx 使用tx读取附加信息
+func (srv *PasswordService) getMetaWithTx(key string, tx *bbolt.Tx) (Meta, error) {
+	var meta Meta
+	bucket := tx.Bucket([]byte(dbfilekit.MetaBucketName))
+	if bucket == nil {
+		// 旧版本的数据库没有meta bucket
+		return meta, nil
+	}
+	return srv.decodeMeta(bucket.Get([]byte(key)))
+}
+
+// decodeMeta 解析保存的附加信息，value 为 nil 时返回空的 Meta
+func (srv *PasswordService) decodeMeta(value []byte) (Meta, error) {
+	var meta Meta
+	if value == nil {
+		return meta, nil
+	}
+	if err := json.Unmarshal(value, &meta); err != nil {
+		srv.logger.Error("unmarshal meta failed:", zap.Error(err))
+		return meta, err
+	}
+	return meta, nil
+}
+
+// putMetaWithTx 使用tx写入附加信息
+func (srv *PasswordService) putMetaWithTx(key string, meta Meta, tx *bbolt.Tx) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.MetaBucketName))
+	if err != nil {
+		srv.logger.Error("create meta bucket failed:", zap.Error(err))
+		return err
+	}
+	value, err := json.Marshal(meta)
+	if err != nil {
+		srv.logger.Error("marshal meta failed:", zap.Error(err))
+		return err
+	}
+	return bucket.Put([]byte(key), value)
+}
+
+// deleteMetaWithTx 使用tx删除附加信息
+func (srv *PasswordService) deleteMetaWithTx(key string, tx *bbolt.Tx) error {
+	bucket := tx.Bucket([]byte(dbfilekit.MetaBucketName))
+	if bucket == nil {
+		return nil
+	}
+	return bucket.Delete([]byte(key))
+}
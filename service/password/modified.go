@@ -0,0 +1,102 @@
+package password
+
+import (
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// GetModified 返回密码最后一次修改的时间，旧版本保存的条目没有记录时返回零值
+func (srv *PasswordService) GetModified(key string) (time.Time, error) {
+	var modified time.Time
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		modified, err = srv.getModifiedWithTx(key, tx)
+		return err
+	})
+	if err != nil {
+		srv.logger.Error("get modified time failed:", zap.Error(err))
+		return time.Time{}, err
+	}
+	return modified, nil
+}
+
+// SetModified 设置密码的修改时间，用于导入和恢复数据时保留原来的时间
+func (srv *PasswordService) SetModified(key string, modified time.Time) error {
+	if _, _, err := srv.readPassword(key); err != nil {
+		return err
+	}
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		return srv.putModifiedWithTx(key, modified, tx)
+	})
+	if err != nil {
+		srv.logger.Error("set modified time failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// getModifiedWithTx 读取修改时间，没有记录时返回零值
+func (srv *PasswordService) getModifiedWithTx(key string, tx *bbolt.Tx) (time.Time, error) {
+	bucket := tx.Bucket([]byte(dbfilekit.ModifiedBucketName))
+	if bucket == nil {
+		// 旧版本的数据库没有modified bucket
+		return time.Time{}, nil
+	}
+	return parseModified(key, bucket.Get([]byte(key)))
+}
+
+// parseModified 解析保存的修改时间，value 为 nil 时返回零值
+func parseModified(key string, value []byte) (time.Time, error) {
+	return parseTimestamp("modified", key, value)
+}
+
+// putModifiedWithTx 保存修改时间
+func (srv *PasswordService) putModifiedWithTx(key string, modified time.Time, tx *bbolt.Tx) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.ModifiedBucketName))
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), []byte(modified.UTC().Format(time.RFC3339)))
+}
+
+// passwordWithTx 读取并解密 key 当前的密码，key 不存在时返回 false
+func (srv *PasswordService) passwordWithTx(key string, tx *bbolt.Tx) (string, bool, error) {
+	bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+	if bucket == nil {
+		return "", false, errors.New("password bucket not found")
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return "", false, nil
+	}
+	platformLen, err := srv.getPlatformLen(key, tx)
+	if err != nil {
+		return "", false, err
+	}
+	password, err := srv.decryptValue(value[platformLen:])
+	if err != nil {
+		return "", false, err
+	}
+	return string(password), true, nil
+}
+
+// platformWithTx 使用tx读取 key 的平台信息
+func (srv *PasswordService) platformWithTx(key string, tx *bbolt.Tx) (string, error) {
+	bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+	if bucket == nil {
+		return "", errors.New("password bucket not found")
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return "", notFoundError(key)
+	}
+	platformLen, err := srv.getPlatformLen(key, tx)
+	if err != nil {
+		return "", err
+	}
+	return string(value[:platformLen]), nil
+}
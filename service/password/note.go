@@ -0,0 +1,15 @@
+package password
+
+// SaveNote 保存安全笔记，内容和普通密码一样加密保存
+func (srv *PasswordService) SaveNote(key, content, platform string) error {
+	return srv.SaveRecord(key, platform, Record{Type: TypeNote, Fields: map[string]string{"content": content}})
+}
+
+// IsNote 判断 key 是否是安全笔记
+func (srv *PasswordService) IsNote(key string) (bool, error) {
+	kind, err := srv.GetKind(key)
+	if err != nil {
+		return false, err
+	}
+	return kind == TypeNote, nil
+}
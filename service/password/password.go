@@ -5,8 +5,8 @@ import (
 	"password_manager/service/aes"
 	dbfilekit "password_manager/service/dbfile_Kit"
 	"strconv"
+	"time"
 
-	"github.com/gookit/color"
 	"go.etcd.io/bbolt"
 	"go.uber.org/zap"
 )
@@ -19,9 +19,29 @@ type PasswordService struct {
 	aesSrv aes.AesInterface
 }
 type PasswordData struct {
-	Key      string `json:"key"`
-	Platform string `json:"platform"`
-	Password string `json:"password"`
+	Key      string   `json:"key"`
+	Platform string   `json:"platform"`
+	Password string   `json:"password"`
+	Username string   `json:"username,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Folder 条目所在的文件夹，根目录为空
+	Folder string `json:"folder,omitempty"`
+	// Envs 各个环境的密码，没有时为nil
+	Envs map[string]string `json:"envs,omitempty"`
+	// Type 条目类型，普通密码为空
+	Type string `json:"type,omitempty"`
+	// Fields 该类型中除了密码、用户名和URL之外的字段，解密后的值
+	Fields map[string]string `json:"fields,omitempty"`
+	// Modified 密码最后一次修改的时间，没有记录时为零值
+	Modified time.Time `json:"modified"`
+	// Created 条目创建的时间，LastUsed 最后一次读取密码的时间，UseCount 读取次数，没有记录时为零值
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"last_used"`
+	UseCount int       `json:"use_count"`
+	// ExpiresAt 到期日期，RotationDays 轮换周期（天），见 Meta
+	ExpiresAt    string `json:"expires_at,omitempty"`
+	RotationDays int    `json:"rotation_days,omitempty"`
 }
 
 func NewPasswordData(key, platform, password string) *PasswordData {
@@ -46,11 +66,11 @@ func NewPasswordService(aesSrv aes.AesInterface, db *bbolt.DB) *PasswordService
 func (srv *PasswordService) SavePassword(key, password, platform string) error {
 	if key == "" {
 		srv.logger.Error("key is empty")
-		return errors.New("key is empty")
+		return ErrKeyEmpty
 	}
 	if password == "" {
 		srv.logger.Error("password is empty")
-		return errors.New("password is empty")
+		return ErrPasswordEmpty
 	}
 	// 先检查 key 是否存在
 	var exists bool
@@ -68,8 +88,8 @@ func (srv *PasswordService) SavePassword(key, password, platform string) error {
 	}
 	//存在就返回错误
 	if exists {
-		srv.logger.Debug("save password failed, key has been set ", zap.String("key", key))
-		return errors.New("key has been set")
+		srv.logger.Debug("save password failed, key already exists", zap.String("key", key))
+		return existsError(key)
 	}
 	//第一次存因此newKey参赛可以为空
 	err = srv.updateDb(key, password, platform, "")
@@ -80,11 +100,16 @@ func (srv *PasswordService) SavePassword(key, password, platform string) error {
 	return nil
 }
 
-// GetPasswordWithKey 获取指定 key 的密码
+// GetPasswordWithKey 获取指定 key 的密码，不记录使用情况，用户查看密码时使用 GetPasswordTracked
 func (srv *PasswordService) GetPasswordWithKey(key string) (string, string, error) {
+	return srv.readPassword(key)
+}
+
+// readPassword 获取指定 key 的密码和平台信息，不记录使用情况
+func (srv *PasswordService) readPassword(key string) (string, string, error) {
 	if key == "" {
 		srv.logger.Debug("key is empty")
-		return "", "", errors.New("key is empty")
+		return "", "", ErrKeyEmpty
 	}
 
 	var encryptedValue []byte
@@ -99,7 +124,7 @@ func (srv *PasswordService) GetPasswordWithKey(key string) (string, string, erro
 		}
 		encryptedValue = bucket.Get([]byte(key))
 		if encryptedValue == nil {
-			return errors.New("key:" + key + " not found")
+			return notFoundError(key)
 		}
 		bucket = tx.Bucket([]byte(dbfilekit.PlatformLenBucketName))
 		if bucket == nil {
@@ -142,157 +167,135 @@ func (srv *PasswordService) GetPasswordWithKey(key string) (string, string, erro
 // GetAllPasswords 获取所有存储的密码
 func (srv *PasswordService) GetAllPasswords() (map[string]PasswordData, error) {
 	passwordsData := make(map[string]PasswordData)
+	cursor, err := srv.NewCursor()
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		data, err := cursor.Decrypt()
+		if err != nil {
+			srv.logger.Error("get all passwords failed:", zap.Error(err))
+			return nil, err
+		}
+		passwordsData[data.Key] = data
+	}
+	if err := cursor.Err(); err != nil {
+		srv.logger.Error("get all passwords failed:", zap.Error(err))
+		return nil, err
+	}
+	return passwordsData, nil
+}
 
+// GetAllKeys 获取所有的key，不做解密操作
+func (srv *PasswordService) GetAllKeys() ([]string, error) {
+	var keys []string
 	err := srv.db.View(func(tx *bbolt.Tx) error {
-		//获取密码
-		passwordBucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
-		if passwordBucket == nil {
+		bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+		if bucket == nil {
 			srv.logger.Error("password bucket not found")
 			return errors.New("password bucket not found")
 		}
-		platformBucket := tx.Bucket([]byte(dbfilekit.PlatformLenBucketName))
-		if platformBucket == nil {
-			srv.logger.Error("platform bucket not found")
-			return errors.New("platform bucket not found")
-		}
-		err := passwordBucket.ForEach(func(k, v []byte) error {
-			//截取平台信息
-			platformLenByte := platformBucket.Get(k)
-			if platformLenByte == nil {
-				password, err := srv.decryptValue(v)
-				if err != nil {
-					srv.logger.Error("decrypt password failed:", zap.Error(err))
-					return err
-				}
-				passwordsData[string(k)] = PasswordData{
-					Key:      string(k),
-					Password: string(password),
-				}
-			} else {
-				platformLen, err := strconv.Atoi(string(platformLenByte))
-				if err != nil {
-					srv.logger.Error("convert platformLen failed:", zap.Error(err))
-					return err
-				}
-				platform := v[:platformLen]
-				// 解密密码
-				password, err := srv.decryptValue(v[platformLen:])
-				if err != nil {
-					srv.logger.Error("decrypt password failed:", zap.Error(err))
-					return err
-				}
-				passwordsData[string(k)] = PasswordData{
-					Key:      string(k),
-					Password: string(password),
-					Platform: string(platform),
-				}
-			}
+		return bucket.ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
 			return nil
 		})
-		if err != nil {
-			srv.logger.Error("get all passwords failed:", zap.Error(err))
-			return err
-		}
-		return nil
 	})
 	if err != nil {
-		srv.logger.Error("get all passwords failed:", zap.Error(err))
+		srv.logger.Error("get all keys failed:", zap.Error(err))
 		return nil, err
 	}
+	return keys, nil
+}
 
-	return passwordsData, nil
+// GetAllEntries 获取所有条目的平台、附加信息和修改时间，按 key 排序
+// 不解密密码，返回的 Password、Envs 和 Fields 为空
+func (srv *PasswordService) GetAllEntries() ([]PasswordData, error) {
+	var entries []PasswordData
+	cursor, err := srv.NewCursor()
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		entries = append(entries, cursor.Entry())
+	}
+	if err := cursor.Err(); err != nil {
+		srv.logger.Error("get all entries failed:", zap.Error(err))
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UpdateResult 一次更新的结果，由调用方决定如何输出，不包含密码
+type UpdateResult struct {
+	// Key 更新后的 key
+	Key string
+	// OldPlatform 更新前的平台信息
+	OldPlatform string
+	// PasswordChanged 新密码与旧密码不同时为 true
+	PasswordChanged bool
 }
 
-// UpdatePassword 更新密码
-func (srv *PasswordService) UpdatePassword(key, newPassword, newPlatform, newKey string) error {
-	var (
-		password    string
-		platform    string
-		oldPassword string
-		oldPlatform string
-		err         error
-	)
+// UpdatePassword 更新密码，参数为空时保持不变，不输出任何内容
+func (srv *PasswordService) UpdatePassword(key, newPassword, newPlatform, newKey string) (UpdateResult, error) {
 	if key == "" {
 		srv.logger.Error("key is empty")
-		return errors.New("key is empty")
+		return UpdateResult{}, ErrKeyEmpty
 	}
-	if newPassword != "" {
-		password = newPassword
-	}
-	if newPlatform != "" {
-		platform = newPlatform
+	var result UpdateResult
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		result, err = srv.updatePasswordWithTx(key, newPassword, newPlatform, newKey, tx)
+		return err
+	})
+	if err != nil {
+		srv.logger.Error("update password failed:", zap.Error(err))
+		return UpdateResult{}, err
 	}
+	return result, nil
+}
 
-	// 先检查 key 是否存在
-	var exists bool
-	var oldValue []byte
-	err = srv.db.View(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
-		if bucket == nil {
-			return errors.New("password bucket not found")
-		}
-		value := bucket.Get([]byte(key))
-		exists = value != nil
-		oldValue = value
-		//获取平台信息长度
-		platformLen, err := srv.getPlatformLen(key, tx)
-		if err != nil {
-			srv.logger.Error("get key "+key+" platform len failed:", zap.Error(err))
-			return err
-		}
-		//获取旧的密码和平台信息
-		oldPasswordByte, err := srv.decryptValue(oldValue[platformLen:])
-		if err != nil {
-			srv.logger.Error("decrypt password failed:", zap.Error(err))
-			return err
-		}
-		oldPassword = string(oldPasswordByte)
-		srv.logger.Sugar().Debugf("oldPassword:" + oldPassword)
-		oldPlatform = string(oldValue[:platformLen])
-		//判断是否需要更新平台信息
-		if platform == "" {
-			platform = oldPlatform
-		}
-		//判断是否需要更新密码
-		if password == "" {
-			password = oldPassword
-		}
-		srv.logger.Sugar().Debugf("key:" + key + " password:" + password + " platform:" + platform)
-		return nil
-	})
+// updatePasswordWithTx 使用tx更新密码、平台和 key，参数为空时保持不变
+func (srv *PasswordService) updatePasswordWithTx(key, newPassword, newPlatform, newKey string, tx *bbolt.Tx) (UpdateResult, error) {
+	oldPassword, exists, err := srv.passwordWithTx(key, tx)
 	if err != nil {
-		srv.logger.Error("get key "+key+" failed:", zap.Error(err))
-		return errors.New("key:" + key + " not found")
+		return UpdateResult{}, err
 	}
 	if !exists {
-		srv.logger.Sugar().Debugf("key:" + key + " not found")
-		return errors.New("key:" + key + " not found")
+		return UpdateResult{}, notFoundError(key)
 	}
-
-	srv.logger.Sugar().Debugf("password:" + password)
-	err = srv.updateDb(key, password, platform, newKey)
+	oldPlatform, err := srv.platformWithTx(key, tx)
 	if err != nil {
-		srv.logger.Error("update db failed:", zap.Error(err))
-		return err
-	}
-	if newKey != "" {
-		color.Green.Println("key updated successfully:" + key + " -> " + newKey)
+		return UpdateResult{}, err
 	}
+	password := oldPassword
 	if newPassword != "" {
-		color.Green.Println("password updated successfully:" + oldPassword + " -> " + newPassword)
+		password = newPassword
 	}
+	platform := oldPlatform
 	if newPlatform != "" {
-		color.Green.Println("platform updated successfully:" + oldPlatform + " -> " + newPlatform)
+		platform = newPlatform
 	}
-
-	return nil
+	if err := srv.updateDbWithTx(key, password, platform, newKey, tx); err != nil {
+		return UpdateResult{}, err
+	}
+	result := UpdateResult{
+		Key:             key,
+		OldPlatform:     oldPlatform,
+		PasswordChanged: password != oldPassword,
+	}
+	if newKey != "" {
+		result.Key = newKey
+	}
+	return result, nil
 }
 
 // getPlatformLen 获取平台信息的长度
 func (srv *PasswordService) getPlatformLen(key string, tx *bbolt.Tx) (int, error) {
 	if key == "" {
 		srv.logger.Error("key is empty")
-		return 0, errors.New("key is empty")
+		return 0, ErrKeyEmpty
 	}
 	bucket := tx.Bucket([]byte(dbfilekit.PlatformLenBucketName))
 	if bucket == nil {
@@ -302,7 +305,7 @@ func (srv *PasswordService) getPlatformLen(key string, tx *bbolt.Tx) (int, error
 	platformLenByte := bucket.Get([]byte(key))
 	if platformLenByte == nil {
 		srv.logger.Error("key:" + key + " not found")
-		return 0, errors.New("key:" + key + " not found")
+		return 0, notFoundError(key)
 	}
 	platformLen, err := strconv.Atoi(string(platformLenByte))
 	if err != nil {
@@ -314,9 +317,22 @@ func (srv *PasswordService) getPlatformLen(key string, tx *bbolt.Tx) (int, error
 
 // updateDb 更新数据库
 func (srv *PasswordService) updateDb(key string, password string, platform string, newKey string) error {
+	// 将密码存入 BoltDB
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		return srv.updateDbWithTx(key, password, platform, newKey, tx)
+	})
+	if err != nil {
+		srv.logger.Error("save password failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// updateDbWithTx 使用tx保存密码和平台，newKey 不为空时条目的所有内容移动到 newKey
+func (srv *PasswordService) updateDbWithTx(key string, password string, platform string, newKey string, tx *bbolt.Tx) error {
 	if key == "" {
 		srv.logger.Error("key is empty")
-		return errors.New("key is empty")
+		return ErrKeyEmpty
 	}
 	if key == newKey {
 		srv.logger.Error("newKey is the same as key")
@@ -324,58 +340,130 @@ func (srv *PasswordService) updateDb(key string, password string, platform strin
 	}
 	if password == "" {
 		srv.logger.Error("password is empty")
-		return errors.New("password is empty")
+		return ErrPasswordEmpty
+	}
+	encryptedValue, platformLenByte, err := srv.encodeValue(password, platform)
+	if err != nil {
+		return err
+	}
+	if newKey == "" {
+		//没有修改key就直接更新
+		return srv.putPasswordWithTx(key, password, encryptedValue, platformLenByte, tx)
+	}
+	//密码有变化时记录修改时间
+	oldPassword, exists, err := srv.passwordWithTx(key, tx)
+	if err != nil {
+		srv.logger.Error("passwordWithTx failed:", zap.Error(err))
+		return err
+	}
+	//不能覆盖已有的条目
+	if srv.existsWithTx(newKey, tx) {
+		return existsError(newKey)
+	}
+	//更新
+	if err := srv.updateWithTx(newKey, encryptedValue, platformLenByte, tx); err != nil {
+		srv.logger.Error("updateWithTx failed:", zap.Error(err))
+		return err
+	}
+	//附加信息跟随新的key
+	meta, err := srv.getMetaWithTx(key, tx)
+	if err != nil {
+		return err
+	}
+	if err := srv.putMetaWithTx(newKey, meta, tx); err != nil {
+		srv.logger.Error("putMetaWithTx failed:", zap.Error(err))
+		return err
+	}
+	//环境的密码跟随新的key
+	if err := srv.moveEnvsWithTx(key, newKey, tx); err != nil {
+		srv.logger.Error("moveEnvsWithTx failed:", zap.Error(err))
+		return err
+	}
+	//附件跟随新的key
+	if err := srv.moveAttachmentsWithTx(key, newKey, tx); err != nil {
+		srv.logger.Error("moveAttachmentsWithTx failed:", zap.Error(err))
+		return err
+	}
+	//字段和类型跟随新的key
+	if err := srv.moveFieldsWithTx(key, newKey, tx); err != nil {
+		srv.logger.Error("moveFieldsWithTx failed:", zap.Error(err))
+		return err
+	}
+	if err := srv.moveKindWithTx(key, newKey, tx); err != nil {
+		srv.logger.Error("moveKindWithTx failed:", zap.Error(err))
+		return err
+	}
+	//修改时间、创建时间和使用记录跟随新的key
+	if err := srv.moveActivityWithTx(key, newKey, tx); err != nil {
+		srv.logger.Error("moveActivityWithTx failed:", zap.Error(err))
+		return err
+	}
+	//删除之前的
+	err = srv.deleteWithTx(key, tx)
+	if err != nil {
+		srv.logger.Error("deleteWithTx failed:", zap.Error(err))
+		return err
 	}
+	now := time.Now()
+	if !exists || oldPassword != password {
+		if err := srv.putModifiedWithTx(newKey, now, tx); err != nil {
+			srv.logger.Error("putModifiedWithTx failed:", zap.Error(err))
+			return err
+		}
+	}
+	//新的条目记录创建时间
+	if !exists {
+		if err := srv.putCreatedWithTx(newKey, now, tx); err != nil {
+			srv.logger.Error("putCreatedWithTx failed:", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeValue 加密密码并在前面拼接平台信息，返回保存的值和平台信息的长度
+func (srv *PasswordService) encodeValue(password, platform string) ([]byte, []byte, error) {
 	// 将平台信息转化为byte数组
 	platformByte := []byte(platform)
-	// 获取数组长度
-	platformLen := len(platformByte)
-	//将数组长度转化为字符串
-	platformLenStr := strconv.Itoa(platformLen)
-	// 将平台信息长度字符串转化为byte数组
-	platformLenByte := []byte(platformLenStr)
+	// 将平台信息长度转化为字符串保存
+	platformLenByte := []byte(strconv.Itoa(len(platformByte)))
 
 	// 加密密码
 	cipherPassword, nonce, err := srv.aesSrv.Encrypt(password)
 	if err != nil {
 		srv.logger.Error("encrypt password failed:", zap.Error(err))
-		return err
+		return nil, nil, err
 	}
 	// 拼接平台信息和nonce
 	encryptedValue := append(platformByte, nonce...)
 	encryptedValue = append(encryptedValue, cipherPassword...)
+	return encryptedValue, platformLenByte, nil
+}
 
-	// 将密码存入 BoltDB
-	err = srv.db.Update(func(tx *bbolt.Tx) error {
-		if newKey == "" {
-			//没有修改key就直接更新
-			err := srv.updateWithTx(key, encryptedValue, platformLenByte, tx)
-			if err != nil {
-				srv.logger.Error("updateWithTx failed:", zap.Error(err))
-				return err
-			}
-		} else {
-			//更新
-			err := srv.updateWithTx(newKey, encryptedValue, platformLenByte, tx)
-			if err != nil {
-				srv.logger.Error("updateWithTx failed:", zap.Error(err))
-				return err
-			}
-			//删除之前的
-			err = srv.deleteWithTx(key, tx)
-			if err != nil {
-				srv.logger.Error("deleteWithTx failed:", zap.Error(err))
-				return err
-			}
-		}
-
-		return nil
-	})
-
+// putPasswordWithTx 使用tx保存 key 的密码，密码有变化时记录修改时间，新的条目记录创建时间
+func (srv *PasswordService) putPasswordWithTx(key, password string, encryptedValue, platformLenByte []byte, tx *bbolt.Tx) error {
+	oldPassword, exists, err := srv.passwordWithTx(key, tx)
 	if err != nil {
-		srv.logger.Error("save password failed:", zap.Error(err))
+		srv.logger.Error("passwordWithTx failed:", zap.Error(err))
 		return err
 	}
+	if err := srv.updateWithTx(key, encryptedValue, platformLenByte, tx); err != nil {
+		srv.logger.Error("updateWithTx failed:", zap.Error(err))
+		return err
+	}
+	now := time.Now()
+	if !exists || oldPassword != password {
+		if err := srv.putModifiedWithTx(key, now, tx); err != nil {
+			srv.logger.Error("putModifiedWithTx failed:", zap.Error(err))
+			return err
+		}
+	}
+	if !exists {
+		if err := srv.putCreatedWithTx(key, now, tx); err != nil {
+			srv.logger.Error("putCreatedWithTx failed:", zap.Error(err))
+			return err
+		}
+	}
 	return nil
 }
 
@@ -383,7 +471,7 @@ func (srv *PasswordService) updateDb(key string, password string, platform strin
 func (srv *PasswordService) DeletePassword(key string) error {
 	if key == "" {
 		srv.logger.Error("key is empty")
-		return errors.New("key is empty")
+		return ErrKeyEmpty
 	}
 	// 先检查 key 是否存在
 	var exists bool
@@ -396,7 +484,7 @@ func (srv *PasswordService) DeletePassword(key string) error {
 		return nil
 	})
 	if err != nil || !exists {
-		return errors.New("key:" + key + " not found")
+		return notFoundError(key)
 	}
 	//执行删除操作
 	err = srv.db.Update(func(tx *bbolt.Tx) error {
@@ -430,7 +518,22 @@ func (srv *PasswordService) deleteWithTx(key string, tx *bbolt.Tx) error {
 	if err != nil {
 		return err
 	}
-	return nil
+	if err := srv.deleteEnvsWithTx(key, tx); err != nil {
+		return err
+	}
+	if err := srv.deleteAttachmentsWithTx(key, tx); err != nil {
+		return err
+	}
+	if err := srv.deleteFieldsWithTx(key, tx); err != nil {
+		return err
+	}
+	if err := srv.deleteKindWithTx(key, tx); err != nil {
+		return err
+	}
+	if err := srv.deleteActivityWithTx(key, tx); err != nil {
+		return err
+	}
+	return srv.deleteMetaWithTx(key, tx)
 }
 
 // updateWithTx 使用tx操作
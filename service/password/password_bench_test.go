@@ -0,0 +1,142 @@
+package password_test
+
+import (
+	"password_manager/service/aes"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/listing"
+	"password_manager/service/password"
+	"password_manager/service/search"
+	secretkey "password_manager/service/secret_key"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// benchSizes 基准测试的条目数量
+var benchSizes = []int{1000, 10000, 100000}
+
+// benchPlatforms 条目平均分布在这么多个平台上
+const benchPlatforms = 100
+
+// newBenchVault 在临时目录中创建有 n 个条目的数据库
+func newBenchVault(b *testing.B, n int) *password.PasswordService {
+	b.Helper()
+	//写入大量条目时不输出日志也不同步到磁盘
+	restore := zap.ReplaceGlobals(zap.NewNop())
+	defer restore()
+	dir := b.TempDir()
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath(filepath.Join(dir, "test.gob"))
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath(dir+string(filepath.Separator), secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { dbfileKitInstance.Close() })
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		b.Fatal(err)
+	}
+	db.NoSync = true
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	srv := password.NewPasswordService(aes.NewAesService(key), db)
+	for i := 0; i < n; i++ {
+		platform := "platform-" + strconv.Itoa(i%benchPlatforms)
+		if err := srv.SavePassword("key-"+strconv.Itoa(i), "password-"+strconv.Itoa(i), platform); err != nil {
+			b.Fatal(err)
+		}
+	}
+	db.NoSync = false
+	return srv
+}
+
+// benchBySize 对每种条目数量运行一次基准测试
+func benchBySize(b *testing.B, run func(b *testing.B, srv *password.PasswordService)) {
+	for _, n := range benchSizes {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			srv := newBenchVault(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				run(b, srv)
+			}
+		})
+	}
+}
+
+// BenchmarkListDecryptAll 旧的 pm list：解密所有条目
+func BenchmarkListDecryptAll(b *testing.B) {
+	benchBySize(b, func(b *testing.B, srv *password.PasswordService) {
+		if _, err := srv.GetAllPasswords(); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+// BenchmarkListKeysOnly pm list --keys-only：不解密
+func BenchmarkListKeysOnly(b *testing.B) {
+	benchBySize(b, func(b *testing.B, srv *password.PasswordService) {
+		if _, err := srv.GetAllEntries(); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+// BenchmarkListPage pm list --sort platform --limit 20：排序后只解密一页
+func BenchmarkListPage(b *testing.B) {
+	benchBySize(b, func(b *testing.B, srv *password.PasswordService) {
+		entries, err := srv.GetAllEntries()
+		if err != nil {
+			b.Fatal(err)
+		}
+		page, err := listing.Apply(entries, listing.Options{Sort: listing.SortPlatform, Limit: 20})
+		if err != nil {
+			b.Fatal(err)
+		}
+		keys := make([]string, len(page.Entries))
+		for i, data := range page.Entries {
+			keys[i] = data.Key
+		}
+		if _, err := srv.GetPasswordsWithKeys(keys...); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+// BenchmarkPlaDecryptAll 旧的 pm pla：解密所有条目后按平台搜索
+func BenchmarkPlaDecryptAll(b *testing.B) {
+	benchBySize(b, func(b *testing.B, srv *password.PasswordService) {
+		all, err := srv.GetAllPasswords()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, data := range all {
+			search.Score("platform-7", data.Platform)
+		}
+	})
+}
+
+// BenchmarkPlaCursor pm pla：遍历时按平台搜索，只解密匹配的条目
+func BenchmarkPlaCursor(b *testing.B) {
+	benchBySize(b, func(b *testing.B, srv *password.PasswordService) {
+		cursor, err := srv.NewCursor()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer cursor.Close()
+		for cursor.Next() {
+			if search.Score("platform-7", cursor.Entry().Platform) == 0 {
+				continue
+			}
+			if _, err := cursor.Decrypt(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
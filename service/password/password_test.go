@@ -1,7 +1,10 @@
 package password_test
 
 import (
-	"errors"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/pem"
+	"io"
 	"math/rand"
 	"os"
 	zaplog "password_manager/common/log"
@@ -9,11 +12,16 @@ import (
 	dbfilekit "password_manager/service/dbfile_Kit"
 	"password_manager/service/password"
 	secretkey "password_manager/service/secret_key"
+	"password_manager/service/testvault"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestSavePasswordAndGetPassword(t *testing.T) {
@@ -48,8 +56,8 @@ func TestSavePasswordAndGetPassword(t *testing.T) {
 	}{
 		{nil, "test-password-1", "google"},
 		{nil, "test-password-2", "edge"},
-		{errors.New("key is empty"), "", ""},
-		{errors.New("password is empty"), "", ""},
+		{password.ErrKeyEmpty, "", ""},
+		{password.ErrPasswordEmpty, "", ""},
 		{nil, "test-password-5", ""},
 	}
 	//初始化密钥实例
@@ -212,7 +220,7 @@ func TestUpdatePassword(t *testing.T) {
 	}{
 		{nil, "test_init", "test-password-1", "google"},
 		{nil, "test_init", "test-password-2", "edge"},
-		{errors.New("key is empty"), "test_init", "", ""},
+		{password.ErrKeyEmpty, "test_init", "", ""},
 		{nil, "test_init", "test-password-2", "google"},
 		{nil, "test_init", "test-password-5", "google"},
 		{nil, "test_new", "test-password-5", "google"},
@@ -258,7 +266,7 @@ func TestUpdatePassword(t *testing.T) {
 
 	for i, testCase := range testCases {
 		t.Run(testCase.test_name, func(t *testing.T) {
-			err := passwordInstance.UpdatePassword(testCase.key, testCase.password, testCase.platform, testCase.newKey)
+			result, err := passwordInstance.UpdatePassword(testCase.key, testCase.password, testCase.platform, testCase.newKey)
 			if !assert.Equal(expectedValue[i].err, err) {
 				t.Errorf("expect err %v, but got %v", expectedValue[i].err, err)
 				return
@@ -266,6 +274,8 @@ func TestUpdatePassword(t *testing.T) {
 			if err != nil {
 				return
 			}
+			assert.Equal(expectedValue[i].key, result.Key)
+			assert.Equal(testCase.password != "", result.PasswordChanged)
 			value, platform, err = passwordInstance.GetPasswordWithKey(expectedValue[i].key)
 			if err != nil {
 				t.Log(err.Error())
@@ -353,3 +363,1134 @@ func TestDeletePassword(t *testing.T) {
 func init() {
 	zaplog.LoggerInit()
 }
+
+func TestMeta(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	if err := passwordInstance.SavePassword("test_meta", "test_meta_password", "github"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	//不存在的key不能设置附加信息
+	assert.Error(passwordInstance.SetMeta("not_exist", password.Meta{Username: "john"}))
+
+	meta := password.Meta{Username: "john", URL: "https://github.com", Tags: []string{"work"}}
+	if err := passwordInstance.SetMeta("test_meta", meta); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	values, err := passwordInstance.GetAllPasswords()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal("john", values["test_meta"].Username)
+	assert.Equal("https://github.com", values["test_meta"].URL)
+	assert.Equal([]string{"work"}, values["test_meta"].Tags)
+
+	//修改key后附加信息跟随新的key
+	if _, err := passwordInstance.UpdatePassword("test_meta", "", "", "test_meta_new"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	newMeta, err := passwordInstance.GetMeta("test_meta_new")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal(meta, newMeta)
+	oldMeta, err := passwordInstance.GetMeta("test_meta")
+	assert.NoError(err)
+	assert.Equal(password.Meta{}, oldMeta)
+
+	keys, err := passwordInstance.GetAllKeys()
+	assert.NoError(err)
+	assert.Equal([]string{"test_meta_new"}, keys)
+
+	//删除后附加信息也被删除
+	if err := passwordInstance.DeletePassword("test_meta_new"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	newMeta, err = passwordInstance.GetMeta("test_meta_new")
+	assert.NoError(err)
+	assert.Equal(password.Meta{}, newMeta)
+}
+
+func TestEnvPassword(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	if err := passwordInstance.SavePassword("db", "default-pass", "postgres"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := passwordInstance.SavePassword("shared", "shared-pass", ""); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	//不存在的key和不合法的环境名
+	assert.Error(passwordInstance.SetEnvPassword("not_exist", "prod", "x"))
+	assert.Error(passwordInstance.SetEnvPassword("db", "pro d", "x"))
+	assert.Error(passwordInstance.SetEnvPassword("db", "", "x"))
+	assert.Error(passwordInstance.SetEnvPassword("db", "prod", ""))
+
+	assert.NoError(passwordInstance.SetEnvPassword("db", "prod", "prod-pass"))
+	assert.NoError(passwordInstance.SetEnvPassword("db", "dev", "dev-pass"))
+	assert.NoError(passwordInstance.SetEnvPassword("db", "dev", "dev-pass-2"))
+
+	envs, err := passwordInstance.GetEnvs("db")
+	assert.NoError(err)
+	assert.Equal([]string{"dev", "prod"}, envs)
+	value, err := passwordInstance.GetEnvPassword("db", "dev")
+	assert.NoError(err)
+	assert.Equal("dev-pass-2", value)
+
+	//选择环境
+	value, platform, err := passwordInstance.GetPasswordForEnv("db", "prod")
+	assert.NoError(err)
+	assert.Equal("prod-pass", value)
+	assert.Equal("postgres", platform)
+	value, _, err = passwordInstance.GetPasswordForEnv("db", "")
+	assert.NoError(err)
+	assert.Equal("default-pass", value)
+	_, _, err = passwordInstance.GetPasswordForEnv("db", "staging")
+	assert.EqualError(err, "environment staging not found for key:db, available: dev, prod")
+	//没有环境的条目在所有环境中使用默认密码
+	value, _, err = passwordInstance.GetPasswordForEnv("shared", "prod")
+	assert.NoError(err)
+	assert.Equal("shared-pass", value)
+
+	values, err := passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	assert.Equal(map[string]string{"dev": "dev-pass-2", "prod": "prod-pass"}, values["db"].Envs)
+	assert.Nil(values["shared"].Envs)
+
+	//修改key后环境的密码跟随新的key
+	if _, err := passwordInstance.UpdatePassword("db", "", "", "db_new"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	envs, err = passwordInstance.GetEnvs("db")
+	assert.NoError(err)
+	assert.Empty(envs)
+	envs, err = passwordInstance.GetEnvs("db_new")
+	assert.NoError(err)
+	assert.Equal([]string{"dev", "prod"}, envs)
+
+	//删除单个环境
+	assert.NoError(passwordInstance.DeleteEnvPassword("db_new", "dev"))
+	assert.Error(passwordInstance.DeleteEnvPassword("db_new", "dev"))
+	assert.NoError(passwordInstance.DeleteEnvPassword("db_new", "prod"))
+	envs, err = passwordInstance.GetEnvs("db_new")
+	assert.NoError(err)
+	assert.Empty(envs)
+
+	//删除key后环境的密码也被删除
+	assert.NoError(passwordInstance.SetEnvPassword("db_new", "prod", "prod-pass"))
+	assert.NoError(passwordInstance.DeletePassword("db_new"))
+	assert.NoError(passwordInstance.SavePassword("db_new", "again", ""))
+	envs, err = passwordInstance.GetEnvs("db_new")
+	assert.NoError(err)
+	assert.Empty(envs)
+}
+
+func TestAttachment(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	if err := passwordInstance.SavePassword("server", "server-password", "ssh"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	//跨越多个分块的二进制内容
+	content := make([]byte, password.AttachmentChunkSize*2+100)
+	rand.New(rand.NewSource(1)).Read(content)
+	info, err := passwordInstance.AddAttachment("server", "id_ed25519", bytes.NewReader(content), false)
+	assert.NoError(err)
+	assert.Equal(int64(len(content)), info.Size)
+	assert.Equal(3, info.Chunks)
+	_, err = passwordInstance.AddAttachment("server", "kubeconfig", strings.NewReader("apiVersion: v1\n"), false)
+	assert.NoError(err)
+
+	//名称、大小和 key 的检查
+	_, err = passwordInstance.AddAttachment("not_exist", "a.txt", strings.NewReader("x"), false)
+	assert.Error(err)
+	_, err = passwordInstance.AddAttachment("server", "../a.txt", strings.NewReader("x"), false)
+	assert.Error(err)
+	_, err = passwordInstance.AddAttachment("server", "empty.txt", strings.NewReader(""), false)
+	assert.Error(err)
+	_, err = passwordInstance.AddAttachment("server", "big.bin", io.LimitReader(zeroReader{}, password.MaxAttachmentSize+1), false)
+	assert.Error(err)
+	_, err = passwordInstance.AddAttachment("server", "kubeconfig", strings.NewReader("new"), false)
+	assert.Error(err)
+	_, err = passwordInstance.AddAttachment("server", "kubeconfig", strings.NewReader("apiVersion: v2\n"), true)
+	assert.NoError(err)
+
+	infos, err := passwordInstance.ListAttachments("server")
+	assert.NoError(err)
+	if assert.Len(infos, 2) {
+		assert.Equal("id_ed25519", infos[0].Name)
+		assert.Equal("kubeconfig", infos[1].Name)
+	}
+
+	data, _, err := passwordInstance.GetAttachment("server", "id_ed25519")
+	assert.NoError(err)
+	assert.Equal(content, data)
+	data, _, err = passwordInstance.GetAttachment("server", "kubeconfig")
+	assert.NoError(err)
+	assert.Equal("apiVersion: v2\n", string(data))
+	_, _, err = passwordInstance.GetAttachment("server", "missing")
+	assert.Error(err)
+
+	//修改key后附件跟随新的key
+	if _, err := passwordInstance.UpdatePassword("server", "", "", "server_new"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	infos, err = passwordInstance.ListAttachments("server")
+	assert.NoError(err)
+	assert.Empty(infos)
+	data, _, err = passwordInstance.GetAttachment("server_new", "id_ed25519")
+	assert.NoError(err)
+	assert.Equal(content, data)
+
+	//删除附件
+	assert.NoError(passwordInstance.DeleteAttachment("server_new", "kubeconfig"))
+	assert.Error(passwordInstance.DeleteAttachment("server_new", "kubeconfig"))
+
+	//删除key后附件也被删除
+	assert.NoError(passwordInstance.DeletePassword("server_new"))
+	assert.NoError(passwordInstance.SavePassword("server_new", "again", ""))
+	infos, err = passwordInstance.ListAttachments("server_new")
+	assert.NoError(err)
+	assert.Empty(infos)
+}
+
+// zeroReader 无限输出0
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestNote(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	content := "recovery codes:\n1111-2222\n3333-4444\n"
+	assert.NoError(passwordInstance.SaveNote("github_recovery", content, "github"))
+	assert.Error(passwordInstance.SaveNote("github_recovery", content, "github"))
+	assert.Error(passwordInstance.SaveNote("empty_note", " \n", ""))
+
+	value, platform, err := passwordInstance.GetPasswordWithKey("github_recovery")
+	assert.NoError(err)
+	assert.Equal(content, value)
+	assert.Equal("github", platform)
+	isNote, err := passwordInstance.IsNote("github_recovery")
+	assert.NoError(err)
+	assert.True(isNote)
+
+	assert.NoError(passwordInstance.SavePassword("plain", "x", ""))
+	isNote, err = passwordInstance.IsNote("plain")
+	assert.NoError(err)
+	assert.False(isNote)
+
+	values, err := passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	assert.Equal(password.TypeNote, values["github_recovery"].Type)
+	assert.Equal("", values["plain"].Type)
+}
+
+func TestKindValidate(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		name  string
+		kind  string
+		value map[string]string
+		ok    bool
+	}{
+		{"login", "", map[string]string{"password": "x", "url": "https://github.com"}, true},
+		{"login bad url", password.TypeLogin, map[string]string{"password": "x", "url": "github"}, false},
+		{"login missing password", password.TypeLogin, map[string]string{"username": "bob"}, false},
+		{"unknown field", password.TypeLogin, map[string]string{"password": "x", "cvv": "123"}, false},
+		{"card", password.TypeCard, map[string]string{"cardholder": "Bob", "number": "4111 1111 1111 1111", "expiry": "12/29", "cvv": "123"}, true},
+		{"card luhn", password.TypeCard, map[string]string{"cardholder": "Bob", "number": "4111 1111 1111 1112", "expiry": "12/29"}, false},
+		{"card expiry", password.TypeCard, map[string]string{"cardholder": "Bob", "number": "4111111111111111", "expiry": "13/29"}, false},
+		{"card cvv", password.TypeCard, map[string]string{"cardholder": "Bob", "number": "4111111111111111", "expiry": "01/30", "cvv": "12a"}, false},
+		{"database port", password.TypeDatabase, map[string]string{"host": "db", "username": "app", "password": "x", "port": "70000"}, false},
+		{"identity email", password.TypeIdentity, map[string]string{"full_name": "Bob", "document_number": "X1", "email": "bob"}, false},
+		{"ssh bad key", password.TypeSSHKey, map[string]string{"private_key": "not a key"}, false},
+	}
+	for _, tc := range testCases {
+		kind, err := password.LookupKind(tc.kind)
+		assert.NoError(err, tc.name)
+		if tc.ok {
+			assert.NoError(kind.Validate(tc.value), tc.name)
+		} else {
+			assert.Error(kind.Validate(tc.value), tc.name)
+		}
+	}
+	_, err := password.LookupKind("bank")
+	assert.Error(err)
+
+	//生成真实的私钥检查 PEM 解析
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "test")
+	assert.NoError(err)
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	assert.NoError(err)
+	kind, _ := password.LookupKind(password.TypeSSHKey)
+	assert.NoError(kind.Validate(map[string]string{
+		"private_key": string(pem.EncodeToMemory(block)),
+		"public_key":  string(ssh.MarshalAuthorizedKey(sshPublicKey)),
+	}))
+	assert.Error(kind.Validate(map[string]string{
+		"private_key": string(pem.EncodeToMemory(block)),
+		"public_key":  "ssh-ed25519 AAAA",
+	}))
+}
+
+func TestRecord(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	card := map[string]string{"cardholder": "Bob", "number": "4111111111111111", "expiry": "12/29", "cvv": "123"}
+	assert.NoError(passwordInstance.SaveRecord("visa", "bank", password.Record{Type: password.TypeCard, Fields: card}))
+	//校验失败时不保存
+	assert.Error(passwordInstance.SaveRecord("bad_card", "", password.Record{Type: password.TypeCard, Fields: map[string]string{"number": "1234"}}))
+	_, _, err = passwordInstance.GetPasswordWithKey("bad_card")
+	assert.Error(err)
+
+	record, err := passwordInstance.GetRecord("visa")
+	assert.NoError(err)
+	assert.Equal(password.TypeCard, record.Type)
+	assert.Equal(card, record.Fields)
+	value, _, err := passwordInstance.GetPasswordWithKey("visa")
+	assert.NoError(err)
+	assert.Equal("4111111111111111", value)
+	//key 已经存在时不覆盖
+	assert.Error(passwordInstance.SaveRecord("visa", "", password.Record{Type: password.TypeNote, Fields: map[string]string{"content": "x"}}))
+	//类型加密保存，附加信息和类型的值中都没有类型的名字
+	kind, err := passwordInstance.GetKind("visa")
+	assert.NoError(err)
+	assert.Equal(password.TypeCard, kind)
+	assert.NoError(db.View(func(tx *bbolt.Tx) error {
+		assert.NotContains(string(tx.Bucket([]byte(dbfilekit.MetaBucketName)).Get([]byte("visa"))), password.TypeCard)
+		assert.NotContains(string(tx.Bucket([]byte(dbfilekit.KindBucketName)).Get([]byte("visa"))), password.TypeCard)
+		return nil
+	}))
+
+	//普通密码是 login 类型
+	assert.NoError(passwordInstance.SavePassword("github", "pwd", ""))
+	assert.NoError(passwordInstance.SetMeta("github", password.Meta{Username: "bob", Tags: []string{"dev"}}))
+	record, err = passwordInstance.GetRecord("github")
+	assert.NoError(err)
+	assert.Equal(password.Record{Type: password.TypeLogin, Fields: map[string]string{"password": "pwd", "username": "bob"}}, record)
+
+	//修改字段，值为空时删除字段，标签保留
+	assert.NoError(passwordInstance.UpdateRecord("github", map[string]string{"url": "https://github.com", "username": ""}))
+	meta, err := passwordInstance.GetMeta("github")
+	assert.NoError(err)
+	assert.Equal(password.Meta{URL: "https://github.com", Tags: []string{"dev"}}, meta)
+	assert.Error(passwordInstance.UpdateRecord("visa", map[string]string{"number": "4111111111111112"}))
+	assert.Error(passwordInstance.UpdateRecord("visa", map[string]string{"cardholder": ""}))
+	assert.NoError(passwordInstance.UpdateRecord("visa", map[string]string{"number": "5555 5555 5555 4444", "cvv": ""}))
+	record, err = passwordInstance.GetRecord("visa")
+	assert.NoError(err)
+	assert.Equal(map[string]string{"cardholder": "Bob", "number": "5555 5555 5555 4444", "expiry": "12/29"}, record.Fields)
+
+	values, err := passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	assert.Equal(password.TypeCard, values["visa"].Type)
+	assert.Equal(map[string]string{"cardholder": "Bob", "expiry": "12/29"}, values["visa"].Fields)
+	assert.Nil(values["github"].Fields)
+
+	//改名和删除时字段跟随条目
+	_, err = passwordInstance.UpdatePassword("visa", "", "", "visa_old")
+	assert.NoError(err)
+	record, err = passwordInstance.GetRecord("visa_old")
+	assert.NoError(err)
+	assert.Equal(password.TypeCard, record.Type)
+	assert.Equal("Bob", record.Fields["cardholder"])
+	kind, err = passwordInstance.GetKind("visa")
+	assert.NoError(err)
+	assert.Empty(kind)
+	assert.NoError(passwordInstance.DeletePassword("visa_old"))
+	assert.NoError(passwordInstance.SaveRecord("visa_old", "", password.Record{Type: password.TypeLogin, Fields: map[string]string{"password": "x"}}))
+	values, err = passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	assert.Nil(values["visa_old"].Fields)
+	assert.Empty(values["visa_old"].Type)
+}
+
+func TestEntry(t *testing.T) {
+	assert := assert.New(t)
+	srv := testvault.New(t).Srv
+
+	assert.ErrorIs(srv.SaveEntry("", "pwd", "", password.Meta{}), password.ErrEmpty)
+	assert.ErrorIs(srv.SaveEntry("github", "", "", password.Meta{}), password.ErrEmpty)
+	assert.NoError(srv.SaveEntry("github", "pwd", "github.com", password.Meta{Username: "bob", Tags: []string{"dev"}}))
+	err := srv.SaveEntry("github", "other", "", password.Meta{})
+	assert.ErrorIs(err, password.ErrExists)
+	assert.EqualError(err, "key:github already exists")
+	assert.ErrorIs(srv.SavePassword("github", "other", ""), password.ErrExists)
+	_, _, err = srv.GetPasswordWithKey("missing")
+	assert.ErrorIs(err, password.ErrNotFound)
+	assert.EqualError(err, "key:missing not found")
+	_, err = srv.UpdateEntry("missing", password.EntryUpdate{Password: "x"})
+	assert.ErrorIs(err, password.ErrNotFound)
+
+	//密码、平台、key 和附加信息一起修改
+	url := "https://github.com"
+	result, err := srv.UpdateEntry("github", password.EntryUpdate{Password: "new", Key: "github_bob", URL: &url})
+	assert.NoError(err)
+	assert.Equal(password.UpdateResult{Key: "github_bob", OldPlatform: "github.com", PasswordChanged: true}, result)
+	value, platform, err := srv.GetPasswordWithKey("github_bob")
+	assert.NoError(err)
+	assert.Equal("new", value)
+	assert.Equal("github.com", platform)
+	meta, err := srv.GetMeta("github_bob")
+	assert.NoError(err)
+	assert.Equal(password.Meta{Username: "bob", URL: url, Tags: []string{"dev"}}, meta)
+
+	//改名到已有的 key 时返回错误，附加信息也不修改
+	assert.NoError(srv.SavePassword("gitlab", "pwd", ""))
+	username := "alice"
+	_, err = srv.UpdateEntry("github_bob", password.EntryUpdate{Key: "gitlab", Username: &username})
+	assert.ErrorIs(err, password.ErrExists)
+	meta, err = srv.GetMeta("github_bob")
+	assert.NoError(err)
+	assert.Equal("bob", meta.Username)
+	value, _, err = srv.GetPasswordWithKey("gitlab")
+	assert.NoError(err)
+	assert.Equal("pwd", value)
+	_, err = srv.UpdatePassword("github_bob", "", "", "gitlab")
+	assert.ErrorIs(err, password.ErrExists)
+
+	//带类型的条目通过类型检查，检查失败时标签也不修改
+	assert.NoError(srv.SaveRecord("visa", "bank", password.Record{Type: password.TypeCard, Fields: map[string]string{
+		"cardholder": "Bob", "number": "4111111111111111", "expiry": "12/29",
+	}}))
+	tags := []string{"finance"}
+	_, err = srv.UpdateEntry("visa", password.EntryUpdate{Password: "4111111111111112", Tags: &tags})
+	assert.Error(err)
+	meta, err = srv.GetMeta("visa")
+	assert.NoError(err)
+	assert.Empty(meta.Tags)
+	_, err = srv.UpdateEntry("visa", password.EntryUpdate{Password: "5555 5555 5555 4444", Tags: &tags})
+	assert.NoError(err)
+	record, err := srv.GetRecord("visa")
+	assert.NoError(err)
+	assert.Equal("5555 5555 5555 4444", record.Fields["number"])
+	assert.Equal("Bob", record.Fields["cardholder"])
+	meta, err = srv.GetMeta("visa")
+	assert.NoError(err)
+	assert.Equal(tags, meta.Tags)
+}
+
+func TestModified(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	assert.NoError(passwordInstance.SavePassword("github", "pwd", ""))
+	modified, err := passwordInstance.GetModified("github")
+	assert.NoError(err)
+	assert.WithinDuration(time.Now(), modified, time.Minute)
+
+	//只修改平台或者密码没有变化时不更新修改时间
+	old := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(passwordInstance.SetModified("github", old))
+	_, err = passwordInstance.UpdatePassword("github", "", "GitHub", "")
+	assert.NoError(err)
+	_, err = passwordInstance.UpdatePassword("github", "pwd", "", "")
+	assert.NoError(err)
+	modified, err = passwordInstance.GetModified("github")
+	assert.NoError(err)
+	assert.Equal(old, modified)
+
+	//改名后修改时间跟随新的 key
+	_, err = passwordInstance.UpdatePassword("github", "", "", "github_work")
+	assert.NoError(err)
+	modified, err = passwordInstance.GetModified("github_work")
+	assert.NoError(err)
+	assert.Equal(old, modified)
+	modified, err = passwordInstance.GetModified("github")
+	assert.NoError(err)
+	assert.True(modified.IsZero())
+
+	values, err := passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	assert.Equal(old, values["github_work"].Modified)
+
+	_, err = passwordInstance.UpdatePassword("github_work", "new", "", "")
+	assert.NoError(err)
+	modified, err = passwordInstance.GetModified("github_work")
+	assert.NoError(err)
+	assert.WithinDuration(time.Now(), modified, time.Minute)
+
+	assert.NoError(passwordInstance.DeletePassword("github_work"))
+	modified, err = passwordInstance.GetModified("github_work")
+	assert.NoError(err)
+	assert.True(modified.IsZero())
+	assert.Error(passwordInstance.SetModified("github_work", old))
+}
+
+func TestExpiry(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	now := time.Now()
+	for _, k := range []string{"github", "aws", "db", "mail"} {
+		assert.NoError(passwordInstance.SavePassword(k, "pwd", ""))
+	}
+	assert.NoError(passwordInstance.SetMeta("github", password.Meta{Username: "john"}))
+	//设置到期时间不影响其他附加信息
+	assert.NoError(passwordInstance.SetExpiry("github", now.AddDate(0, 0, -1).Format(password.ExpiryDateLayout), 0))
+	assert.NoError(passwordInstance.SetExpiry("aws", "", 90))
+	assert.NoError(passwordInstance.SetModified("aws", now.AddDate(0, 0, -80)))
+	assert.NoError(passwordInstance.SetExpiry("db", now.AddDate(0, 1, 0).Format(password.ExpiryDateLayout), 30))
+	assert.Error(passwordInstance.SetExpiry("mail", "31/12/2026", 0))
+	assert.Error(passwordInstance.SetExpiry("mail", "", -1))
+	assert.Error(passwordInstance.SetExpiry("missing", "", 30))
+	meta, err := passwordInstance.GetMeta("github")
+	assert.NoError(err)
+	assert.Equal("john", meta.Username)
+
+	values, err := passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	assert.True(values["github"].Expired(now))
+	assert.False(values["aws"].Expired(now))
+	assert.True(values["aws"].Expired(now.AddDate(0, 0, 11)))
+	due, ok := values["db"].DueDate()
+	assert.True(ok)
+	//两个都设置时取较早的轮换周期
+	assert.WithinDuration(now.AddDate(0, 0, 30), due, time.Minute)
+	_, ok = values["mail"].DueDate()
+	assert.False(ok)
+
+	testCases := []struct {
+		within time.Duration
+		keys   []string
+	}{
+		{0, []string{"github"}},
+		{14 * 24 * time.Hour, []string{"github", "aws"}},
+		{60 * 24 * time.Hour, []string{"github", "aws", "db"}},
+	}
+	for _, tc := range testCases {
+		var keys []string
+		for _, entry := range password.DueEntries(values, tc.within, now) {
+			keys = append(keys, entry.Key)
+			assert.Equal(entry.Key == "github", entry.Expired)
+		}
+		assert.Equal(tc.keys, keys)
+	}
+
+	//没有修改时间的旧条目视为已经到期
+	old := password.PasswordData{Key: "old", RotationDays: 30}
+	assert.True(old.Expired(now))
+}
+
+func TestFolder(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		folder string
+		want   string
+		err    bool
+	}{
+		{"work/aws/prod", "work/aws/prod", false},
+		{"/work//aws/", "work/aws", false},
+		{" work / aws ", "work/aws", false},
+		{"/", "", false},
+		{"", "", false},
+		{"work/../home", "", true},
+		{"./work", "", true},
+	}
+	for _, tc := range testCases {
+		got, err := password.NormalizeFolder(tc.folder)
+		if tc.err {
+			assert.Error(err, tc.folder)
+			continue
+		}
+		assert.NoError(err, tc.folder)
+		assert.Equal(tc.want, got, tc.folder)
+	}
+	assert.True(password.InFolder("work/aws/prod", "work"))
+	assert.True(password.InFolder("work", "work"))
+	assert.True(password.InFolder("", ""))
+	assert.False(password.InFolder("workshop", "work"))
+	assert.False(password.InFolder("", "work"))
+	assert.Equal([]string{"a", "b"}, password.NormalizeTags([]string{" a", "b", "", "a "}))
+}
+
+func TestTagsAndFolders(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	for _, k := range []string{"aws_prod", "aws_dev", "github", "bank"} {
+		assert.NoError(passwordInstance.SavePassword(k, "pwd", ""))
+	}
+	assert.NoError(passwordInstance.SetMeta("github", password.Meta{Username: "john", Tags: []string{"code"}}))
+	assert.NoError(passwordInstance.AddTags("github", "work", "code", " "))
+	assert.NoError(passwordInstance.AddTags("aws_prod", "work", "cloud"))
+	assert.NoError(passwordInstance.AddTags("aws_dev", "cloud"))
+	assert.Error(passwordInstance.AddTags("github"))
+	assert.Error(passwordInstance.AddTags("missing", "work"))
+	assert.NoError(passwordInstance.MoveToFolder("aws_prod", "/work/aws/prod/"))
+	assert.NoError(passwordInstance.MoveToFolder("aws_dev", "work/aws/dev"))
+	assert.NoError(passwordInstance.MoveToFolder("github", "work"))
+	assert.Error(passwordInstance.MoveToFolder("bank", "../bank"))
+
+	meta, err := passwordInstance.GetMeta("github")
+	assert.NoError(err)
+	assert.Equal(password.Meta{Username: "john", Tags: []string{"code", "work"}, Folder: "work"}, meta)
+
+	values, err := passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	testCases := []struct {
+		filter password.Filter
+		keys   []string
+	}{
+		{password.Filter{}, []string{"aws_dev", "aws_prod", "bank", "github"}},
+		{password.Filter{Folder: "work"}, []string{"aws_dev", "aws_prod", "github"}},
+		{password.Filter{Folder: "work/aws"}, []string{"aws_dev", "aws_prod"}},
+		{password.Filter{Tags: []string{"cloud"}}, []string{"aws_dev", "aws_prod"}},
+		{password.Filter{Tags: []string{"cloud", "work"}}, []string{"aws_prod"}},
+		{password.Filter{Tags: []string{"work"}, Folder: "work/aws/dev"}, []string{}},
+	}
+	for _, tc := range testCases {
+		keys := []string{}
+		for k := range tc.filter.Apply(values) {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		assert.Equal(tc.keys, keys)
+	}
+	tags, counts := password.TagCounts(values)
+	assert.Equal([]string{"cloud", "code", "work"}, tags)
+	assert.Equal(2, counts["work"])
+
+	//删除标签和移动到根目录
+	assert.Error(passwordInstance.RemoveTags("github", "cloud"))
+	assert.NoError(passwordInstance.RemoveTags("github", "code"))
+	assert.NoError(passwordInstance.MoveToFolder("github", "/"))
+	//改名后文件夹跟随新的 key
+	_, err = passwordInstance.UpdatePassword("aws_prod", "", "", "aws_production")
+	assert.NoError(err)
+	values, err = passwordInstance.GetAllPasswords()
+	assert.NoError(err)
+	assert.Equal([]string{"work"}, values["github"].Tags)
+	assert.Equal("", values["github"].Folder)
+	assert.Equal("work/aws/prod", values["aws_production"].Folder)
+}
+
+func TestGetAllEntries(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	assert.NoError(passwordInstance.SavePassword("github", "pwd1", "GitHub"))
+	assert.NoError(passwordInstance.SavePassword("aws", "pwd2", ""))
+	assert.NoError(passwordInstance.SetEnvPassword("aws", "prod", "pwd3"))
+	assert.NoError(passwordInstance.AddTags("aws", "cloud"))
+
+	//使用错误的密钥也能列出条目，说明没有解密
+	wrongKey := strings.Repeat("1", len(key))
+	entries, err := password.NewPasswordService(aes.NewAesService(wrongKey), db).GetAllEntries()
+	assert.NoError(err)
+	if assert.Len(entries, 2) {
+		assert.Equal("aws", entries[0].Key)
+		assert.Equal([]string{"cloud"}, entries[0].Tags)
+		assert.Nil(entries[0].Envs)
+		assert.Equal("github", entries[1].Key)
+		assert.Equal("GitHub", entries[1].Platform)
+		assert.Equal("", entries[1].Password)
+		assert.False(entries[1].Modified.IsZero())
+	}
+	_, err = password.NewPasswordService(aes.NewAesService(wrongKey), db).GetAllPasswords()
+	assert.Error(err)
+}
+
+func TestCursor(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	assert.NoError(passwordInstance.SavePassword("c_mail", "pwd-c", "mail"))
+	assert.NoError(passwordInstance.SavePassword("a_github", "pwd-a", "GitHub"))
+	assert.NoError(passwordInstance.SavePassword("b_db", "pwd-b", ""))
+	assert.NoError(passwordInstance.SetEnvPassword("b_db", "prod", "pwd-prod"))
+	assert.NoError(passwordInstance.SaveRecord("d_visa", "bank", password.Record{Type: password.TypeCard, Fields: map[string]string{
+		"cardholder": "John Doe", "number": "4111 1111 1111 1111", "expiry": "12/29",
+	}}))
+
+	//按 key 的顺序遍历，不解密
+	cursor, err := passwordInstance.NewCursor()
+	if !assert.NoError(err) {
+		return
+	}
+	var keys []string
+	for cursor.Next() {
+		entry := cursor.Entry()
+		assert.Equal("", entry.Password)
+		keys = append(keys, entry.Key)
+		if entry.Key == "a_github" {
+			assert.Equal("GitHub", entry.Platform)
+			value, err := cursor.Password()
+			assert.NoError(err)
+			assert.Equal("pwd-a", value)
+		}
+		if entry.Key == "b_db" {
+			data, err := cursor.Decrypt()
+			assert.NoError(err)
+			assert.Equal("pwd-b", data.Password)
+			assert.Equal(map[string]string{"prod": "pwd-prod"}, data.Envs)
+		}
+		if entry.Key == "d_visa" {
+			data, err := cursor.Decrypt()
+			assert.NoError(err)
+			assert.Equal("12/29", data.Fields["expiry"])
+		}
+	}
+	assert.NoError(cursor.Err())
+	assert.Equal([]string{"a_github", "b_db", "c_mail", "d_visa"}, keys)
+
+	//从中间开始遍历
+	cursor.Seek("b")
+	assert.True(cursor.Next())
+	assert.Equal("b_db", cursor.Entry().Key)
+	assert.True(cursor.Next())
+	assert.Equal("c_mail", cursor.Entry().Key)
+	cursor.Seek("z")
+	assert.False(cursor.Next())
+	assert.NoError(cursor.Close())
+	assert.False(cursor.Next())
+	_, err = cursor.Password()
+	assert.Error(err)
+	assert.NoError(cursor.Close())
+
+	//只解密指定的条目，保持参数的顺序
+	values, err := passwordInstance.GetPasswordsWithKeys("c_mail", "a_github")
+	assert.NoError(err)
+	if assert.Len(values, 2) {
+		assert.Equal("pwd-c", values[0].Password)
+		assert.Equal("mail", values[0].Platform)
+		assert.Equal("pwd-a", values[1].Password)
+	}
+	_, err = passwordInstance.GetPasswordsWithKeys("a_github", "b")
+	assert.Error(err)
+	values, err = passwordInstance.GetPasswordsWithKeys()
+	assert.NoError(err)
+	assert.Empty(values)
+}
+
+func TestAccess(t *testing.T) {
+	assert := assert.New(t)
+	os.Remove("./test.gob")
+	os.Remove("./data.db")
+	os.Remove("./data.backup.db")
+	defer func() {
+		os.Remove("./test.gob")
+		os.Remove("./data.db")
+		os.Remove("./data.backup.db")
+	}()
+	//初始化密钥实例
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath("./test.gob")
+	//初始化数据库实例
+	dbfileKitInstance := dbfilekit.NewDBKitWithFilePath("./", secretKeyInstance)
+	if err := dbfileKitInstance.Init(); err != nil {
+		t.Log(err.Error())
+		return
+	}
+	//获取数据库实例
+	db, err := dbfileKitInstance.GetDB()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	defer db.Close()
+	//获取密钥
+	key, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		t.Log(err.Error())
+		return
+	}
+	aesInstance := aes.NewAesService(key)
+
+	passwordInstance := password.NewPasswordService(aesInstance, db)
+	assert.NoError(passwordInstance.SavePassword("github", "pwd", ""))
+	created, err := passwordInstance.GetCreated("github")
+	assert.NoError(err)
+	assert.WithinDuration(time.Now(), created, time.Minute)
+
+	//读取密码本身不记录
+	_, _, err = passwordInstance.GetPasswordWithKey("github")
+	assert.NoError(err)
+	_, _, err = passwordInstance.GetPasswordForEnv("github", "")
+	assert.NoError(err)
+	access, err := passwordInstance.GetAccess("github")
+	assert.NoError(err)
+	assert.Equal(password.Access{}, access)
+
+	_, _, err = passwordInstance.GetPasswordTracked("github")
+	assert.NoError(err)
+	passwordInstance.RecordAccess("github")
+	//不存在的 key 不记录
+	_, _, err = passwordInstance.GetPasswordTracked("gitlab")
+	assert.Error(err)
+	passwordInstance.RecordAccess("gitlab")
+	access, err = passwordInstance.GetAccess("github")
+	assert.NoError(err)
+	assert.Equal(2, access.Count)
+	assert.WithinDuration(time.Now(), access.LastUsed, time.Minute)
+	access, err = passwordInstance.GetAccess("gitlab")
+	assert.NoError(err)
+	assert.Equal(0, access.Count)
+
+	//修改密码不改变创建时间，改名后跟随新的 key
+	_, err = passwordInstance.UpdatePassword("github", "new", "", "")
+	assert.NoError(err)
+	_, err = passwordInstance.UpdatePassword("github", "", "", "github_work")
+	assert.NoError(err)
+	entries, err := passwordInstance.GetAllEntries()
+	assert.NoError(err)
+	if assert.Len(entries, 1) {
+		assert.Equal("github_work", entries[0].Key)
+		assert.Equal(created.Truncate(time.Second), entries[0].Created.Truncate(time.Second))
+		assert.Equal(2, entries[0].UseCount)
+		assert.WithinDuration(time.Now(), entries[0].LastUsed, time.Minute)
+	}
+	created, err = passwordInstance.GetCreated("github")
+	assert.NoError(err)
+	assert.True(created.IsZero())
+	access, err = passwordInstance.GetAccess("github")
+	assert.NoError(err)
+	assert.Equal(0, access.Count)
+
+	//关闭后不再记录，已有的记录保留
+	enabled, err := passwordInstance.AccessTrackingEnabled()
+	assert.NoError(err)
+	assert.True(enabled)
+	assert.NoError(passwordInstance.SetAccessTrackingEnabled(false))
+	enabled, err = passwordInstance.AccessTrackingEnabled()
+	assert.NoError(err)
+	assert.False(enabled)
+	_, _, err = passwordInstance.GetPasswordTracked("github_work")
+	assert.NoError(err)
+	passwordInstance.RecordAccess("github_work")
+	access, err = passwordInstance.GetAccess("github_work")
+	assert.NoError(err)
+	assert.Equal(2, access.Count)
+
+	//删除条目时一起删除
+	assert.NoError(passwordInstance.DeletePassword("github_work"))
+	created, err = passwordInstance.GetCreated("github_work")
+	assert.NoError(err)
+	assert.True(created.IsZero())
+	access, err = passwordInstance.GetAccess("github_work")
+	assert.NoError(err)
+	assert.Equal(password.Access{}, access)
+}
@@ -0,0 +1,17 @@
+package password
+
+import (
+	"password_manager/service/strength"
+)
+
+// CheckStrength 估算新密码的强度并检查是否满足保存的强度策略，所有保存新密码的地方都要先调用它。
+// 不满足时同时返回估算结果和 *strength.WeakPasswordError，调用方只有在用户明确要求时才能忽略这个错误。
+// userInputs 为 key、平台和用户名，密码中包含它们时分数更低
+func (srv *PasswordService) CheckStrength(value string, userInputs ...string) (strength.Result, error) {
+	result := strength.Estimate(value, userInputs...)
+	policy, err := strength.NewPolicyService(srv.db).GetPolicy()
+	if err != nil {
+		return result, err
+	}
+	return result, policy.Check(result)
+}
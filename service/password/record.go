@@ -0,0 +1,322 @@
+package password
+
+import (
+	"encoding/json"
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"strings"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// Record 带类型的条目，Fields 包含该类型的所有字段
+// 保存时主字段放在密码的位置，username 和 url 放在附加信息中，其他字段加密后保存在 fields bucket 中，
+// 类型加密后保存在 kinds bucket 中，普通密码不记录类型
+type Record struct {
+	Type   string
+	Fields map[string]string
+}
+
+// SaveRecord 检查并保存带类型的条目，key 已经存在时返回错误
+func (srv *PasswordService) SaveRecord(key, platform string, record Record) error {
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return ErrKeyEmpty
+	}
+	kind, err := LookupKind(record.Type)
+	if err != nil {
+		return err
+	}
+	fields := compactFields(record.Fields)
+	if err := kind.Validate(fields); err != nil {
+		return err
+	}
+	encryptedValue, platformLenByte, err := srv.encodeValue(fields[kind.Primary], platform)
+	if err != nil {
+		return err
+	}
+	//密码、附加信息、字段和类型在同一个事务中写入，失败时不会留下不完整的条目
+	err = srv.db.Update(func(tx *bbolt.Tx) error {
+		if srv.existsWithTx(key, tx) {
+			return existsError(key)
+		}
+		if err := srv.putPasswordWithTx(key, fields[kind.Primary], encryptedValue, platformLenByte, tx); err != nil {
+			return err
+		}
+		return srv.storeRecordWithTx(key, kind, fields, Meta{}, tx)
+	})
+	if err != nil {
+		srv.logger.Error("save record failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetRecord 返回条目的类型和所有字段
+func (srv *PasswordService) GetRecord(key string) (Record, error) {
+	var record Record
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		record, err = srv.getRecordWithTx(key, tx)
+		return err
+	})
+	return record, err
+}
+
+// getRecordWithTx 使用tx读取条目的类型和所有字段
+func (srv *PasswordService) getRecordWithTx(key string, tx *bbolt.Tx) (Record, error) {
+	pwd, exists, err := srv.passwordWithTx(key, tx)
+	if err != nil {
+		return Record{}, err
+	}
+	if !exists {
+		return Record{}, notFoundError(key)
+	}
+	meta, err := srv.getMetaWithTx(key, tx)
+	if err != nil {
+		return Record{}, err
+	}
+	kindName, err := srv.getKindWithTx(key, tx)
+	if err != nil {
+		return Record{}, err
+	}
+	kind, err := LookupKind(kindName)
+	if err != nil {
+		return Record{}, err
+	}
+	fields, err := srv.getFieldsWithTx(key, tx)
+	if err != nil {
+		return Record{}, err
+	}
+	if fields == nil {
+		fields = make(map[string]string)
+	}
+	fields[kind.Primary] = pwd
+	if _, ok := kind.Field(FieldUsername); ok && meta.Username != "" {
+		fields[FieldUsername] = meta.Username
+	}
+	if _, ok := kind.Field(FieldURL); ok && meta.URL != "" {
+		fields[FieldURL] = meta.URL
+	}
+	return Record{Type: kind.Name, Fields: fields}, nil
+}
+
+// UpdateRecord 修改条目的字段，值为空时删除该字段，修改后的条目需要通过类型检查
+func (srv *PasswordService) UpdateRecord(key string, changes map[string]string) error {
+	err := srv.db.Update(func(tx *bbolt.Tx) error {
+		return srv.updateRecordWithTx(key, changes, tx)
+	})
+	if err != nil {
+		srv.logger.Error("update record failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// updateRecordWithTx 使用tx修改条目的字段，平台和标签等附加信息保持不变
+func (srv *PasswordService) updateRecordWithTx(key string, changes map[string]string, tx *bbolt.Tx) error {
+	record, err := srv.getRecordWithTx(key, tx)
+	if err != nil {
+		return err
+	}
+	kind, err := LookupKind(record.Type)
+	if err != nil {
+		return err
+	}
+	for name, value := range changes {
+		record.Fields[name] = value
+	}
+	fields := compactFields(record.Fields)
+	if err := kind.Validate(fields); err != nil {
+		return err
+	}
+	platform, err := srv.platformWithTx(key, tx)
+	if err != nil {
+		return err
+	}
+	encryptedValue, platformLenByte, err := srv.encodeValue(fields[kind.Primary], platform)
+	if err != nil {
+		return err
+	}
+	if err := srv.putPasswordWithTx(key, fields[kind.Primary], encryptedValue, platformLenByte, tx); err != nil {
+		return err
+	}
+	meta, err := srv.getMetaWithTx(key, tx)
+	if err != nil {
+		return err
+	}
+	return srv.storeRecordWithTx(key, kind, fields, meta, tx)
+}
+
+// storeRecordWithTx 使用tx保存主字段之外的字段和类型，meta 中的标签会保留
+func (srv *PasswordService) storeRecordWithTx(key string, kind Kind, fields map[string]string, meta Meta, tx *bbolt.Tx) error {
+	meta.Username = fields[FieldUsername]
+	meta.URL = fields[FieldURL]
+	if err := srv.putMetaWithTx(key, meta, tx); err != nil {
+		return err
+	}
+	if err := srv.putKindWithTx(key, kind.Name, tx); err != nil {
+		return err
+	}
+	extra := make(map[string]string)
+	for name, value := range fields {
+		if name != kind.Primary && name != FieldUsername && name != FieldURL {
+			extra[name] = value
+		}
+	}
+	if len(extra) == 0 {
+		return srv.deleteFieldsWithTx(key, tx)
+	}
+	value, err := srv.encryptJSON(extra)
+	if err != nil {
+		return err
+	}
+	bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.FieldBucketName))
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), value)
+}
+
+// GetKind 返回条目的类型，普通密码返回空字符串
+func (srv *PasswordService) GetKind(key string) (string, error) {
+	if key == "" {
+		srv.logger.Error("key is empty")
+		return "", ErrKeyEmpty
+	}
+	var kind string
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		kind, err = srv.getKindWithTx(key, tx)
+		return err
+	})
+	if err != nil {
+		srv.logger.Error("get kind failed:", zap.Error(err))
+		return "", err
+	}
+	return kind, nil
+}
+
+// putKindWithTx 加密保存条目的类型，普通密码不记录类型，与旧的条目一致
+func (srv *PasswordService) putKindWithTx(key, kind string, tx *bbolt.Tx) error {
+	if kind == "" || kind == TypeLogin {
+		return srv.deleteKindWithTx(key, tx)
+	}
+	cipherKind, nonce, err := srv.aesSrv.Encrypt(kind)
+	if err != nil {
+		srv.logger.Error("encrypt kind failed:", zap.Error(err))
+		return err
+	}
+	bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.KindBucketName))
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), append(nonce, cipherKind...))
+}
+
+// getKindWithTx 解密条目的类型，没有记录类型时返回空字符串
+func (srv *PasswordService) getKindWithTx(key string, tx *bbolt.Tx) (string, error) {
+	bucket := tx.Bucket([]byte(dbfilekit.KindBucketName))
+	if bucket == nil {
+		// 旧版本的数据库没有kinds bucket
+		return "", nil
+	}
+	return srv.decryptKind(key, bucket.Get([]byte(key)))
+}
+
+// decryptKind 解密保存的类型，value 为 nil 时返回空字符串
+func (srv *PasswordService) decryptKind(key string, value []byte) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	kind, err := srv.decryptValue(value)
+	if err != nil {
+		return "", errors.New("kind of key:" + key + " is corrupted")
+	}
+	return string(kind), nil
+}
+
+// moveKindWithTx key 改名时把类型移动到新的 key
+func (srv *PasswordService) moveKindWithTx(key, newKey string, tx *bbolt.Tx) error {
+	bucket := tx.Bucket([]byte(dbfilekit.KindBucketName))
+	if bucket == nil {
+		return nil
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return bucket.Delete([]byte(newKey))
+	}
+	if err := bucket.Put([]byte(newKey), append([]byte(nil), value...)); err != nil {
+		return err
+	}
+	return bucket.Delete([]byte(key))
+}
+
+// deleteKindWithTx 删除 key 的类型
+func (srv *PasswordService) deleteKindWithTx(key string, tx *bbolt.Tx) error {
+	bucket := tx.Bucket([]byte(dbfilekit.KindBucketName))
+	if bucket == nil {
+		return nil
+	}
+	return bucket.Delete([]byte(key))
+}
+
+// getFieldsWithTx 解密主字段之外的字段，没有时返回nil
+func (srv *PasswordService) getFieldsWithTx(key string, tx *bbolt.Tx) (map[string]string, error) {
+	bucket := tx.Bucket([]byte(dbfilekit.FieldBucketName))
+	if bucket == nil {
+		// 旧版本的数据库没有fields bucket
+		return nil, nil
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return nil, nil
+	}
+	plain, err := srv.decryptValue(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(plain, &fields); err != nil {
+		srv.logger.Error("unmarshal fields failed:", zap.Error(err))
+		return nil, errors.New("fields of key:" + key + " are corrupted")
+	}
+	return fields, nil
+}
+
+// moveFieldsWithTx key 改名时把字段移动到新的 key
+func (srv *PasswordService) moveFieldsWithTx(key, newKey string, tx *bbolt.Tx) error {
+	bucket := tx.Bucket([]byte(dbfilekit.FieldBucketName))
+	if bucket == nil {
+		return nil
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return bucket.Delete([]byte(newKey))
+	}
+	if err := bucket.Put([]byte(newKey), append([]byte(nil), value...)); err != nil {
+		return err
+	}
+	return bucket.Delete([]byte(key))
+}
+
+// deleteFieldsWithTx 删除 key 的字段
+func (srv *PasswordService) deleteFieldsWithTx(key string, tx *bbolt.Tx) error {
+	bucket := tx.Bucket([]byte(dbfilekit.FieldBucketName))
+	if bucket == nil {
+		return nil
+	}
+	return bucket.Delete([]byte(key))
+}
+
+// compactFields 去掉值为空的字段
+func compactFields(fields map[string]string) map[string]string {
+	result := make(map[string]string, len(fields))
+	for name, value := range fields {
+		if strings.TrimSpace(value) != "" {
+			result[name] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Mask 替换密码时输出的内容
+const Mask = "*****"
+
+// MaskWriter 把写入内容中的密码替换为 Mask 后写入 w
+// 密码可能被拆分到多次写入中，结尾可能是密码开头的部分会暂存到下一次写入或 Flush
+type MaskWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	pending []byte
+}
+
+// NewMaskWriter 创建屏蔽密码的 writer，空字符串会被忽略
+func NewMaskWriter(w io.Writer, secrets []string) *MaskWriter {
+	m := &MaskWriter{w: w}
+	for _, secret := range secrets {
+		if secret != "" {
+			m.secrets = append(m.secrets, []byte(secret))
+		}
+	}
+	//较长的密码优先匹配，避免只屏蔽其中一部分
+	sort.Slice(m.secrets, func(i, j int) bool {
+		return len(m.secrets[i]) > len(m.secrets[j])
+	})
+	return m
+}
+
+// Write 写入内容，返回值是 p 的长度而不是实际写入 w 的长度
+func (m *MaskWriter) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending = append(m.pending, p...)
+	out, rest := m.mask(m.pending, false)
+	m.pending = append(m.pending[:0], rest...)
+	if len(out) != 0 {
+		if _, err := m.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush 写出暂存的内容
+func (m *MaskWriter) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out, _ := m.mask(m.pending, true)
+	m.pending = m.pending[:0]
+	if len(out) == 0 {
+		return nil
+	}
+	_, err := m.w.Write(out)
+	return err
+}
+
+// mask 替换 data 中的密码，final 为false时返回结尾可能是密码开头的部分
+func (m *MaskWriter) mask(data []byte, final bool) ([]byte, []byte) {
+	var out bytes.Buffer
+	i := 0
+scan:
+	for i < len(data) {
+		for _, secret := range m.secrets {
+			if bytes.HasPrefix(data[i:], secret) {
+				out.WriteString(Mask)
+				i += len(secret)
+				continue scan
+			}
+		}
+		if !final {
+			for _, secret := range m.secrets {
+				if len(data)-i < len(secret) && bytes.HasPrefix(secret, data[i:]) {
+					return out.Bytes(), data[i:]
+				}
+			}
+		}
+		out.WriteByte(data[i])
+		i++
+	}
+	return out.Bytes(), nil
+}
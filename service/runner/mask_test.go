@@ -0,0 +1,57 @@
+package runner_test
+
+import (
+	"bytes"
+	"password_manager/service/runner"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskWriter(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		secrets   []string
+		chunks    []string
+		expected  string
+	}{
+		{"no_secret", []string{"s3cret"}, []string{"hello world\n"}, "hello world\n"},
+		{"single_write", []string{"s3cret"}, []string{"pass=s3cret\n"}, "pass=*****\n"},
+		{"repeated", []string{"s3cret"}, []string{"s3crets3cret"}, "**********"},
+		{"split", []string{"s3cret"}, []string{"pass=s3", "cr", "et\n"}, "pass=*****\n"},
+		{"byte_by_byte", []string{"abc"}, []string{"x", "a", "b", "c", "y"}, "x*****y"},
+		{"false_prefix", []string{"s3cret"}, []string{"s3c", "ond"}, "s3cond"},
+		{"prefix_at_end", []string{"s3cret"}, []string{"value s3c"}, "value s3c"},
+		{"longest_first", []string{"abc", "abcdef"}, []string{"abcdef abc"}, "***** *****"},
+		{"overlap_restart", []string{"aab"}, []string{"aa", "ab"}, "a*****"},
+		{"empty_secret", []string{""}, []string{"text"}, "text"},
+		{"unicode", []string{"密码"}, []string{"值是密", "码。"}, "值是*****。"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := runner.NewMaskWriter(&buf, testCase.secrets)
+			for _, chunk := range testCase.chunks {
+				n, err := writer.Write([]byte(chunk))
+				assert.NoError(err)
+				assert.Equal(len(chunk), n)
+			}
+			assert.NoError(writer.Flush())
+			assert.Equal(testCase.expected, buf.String())
+		})
+	}
+}
+
+func TestMaskWriterStreams(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	writer := runner.NewMaskWriter(&buf, []string{"s3cret"})
+	// 不可能是密码开头的内容立即写出，交互式的提示不会被延迟
+	writer.Write([]byte("Continue? "))
+	assert.Equal("Continue? ", buf.String())
+	writer.Write([]byte("s3"))
+	assert.Equal("Continue? ", buf.String())
+	writer.Write([]byte("x"))
+	assert.Equal("Continue? s3x", buf.String())
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+)
+
+// Options 子进程的启动参数
+type Options struct {
+	// Command 要执行的命令和参数
+	Command []string
+	// Env 子进程的完整环境变量，格式为 NAME=VALUE
+	Env []string
+	// Secrets 需要在输出中屏蔽的值
+	Secrets []string
+	// NoMask 为true时子进程直接使用 Stdout 和 Stderr，不屏蔽输出
+	NoMask bool
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Signals 需要转发给子进程的信号，为nil时监听 ForwardSignals 中的信号
+	Signals <-chan os.Signal
+}
+
+// Run 启动子进程并等待结束，返回子进程的退出码
+// 收到的信号会转发给子进程，由子进程决定是否退出
+func Run(opts Options) (int, error) {
+	if len(opts.Command) == 0 {
+		return -1, errors.New("command is empty")
+	}
+	cmd := exec.Command(opts.Command[0], opts.Command[1:]...)
+	cmd.Env = opts.Env
+	cmd.Stdin = opts.Stdin
+
+	var stdout, stderr *MaskWriter
+	if opts.NoMask {
+		cmd.Stdout = opts.Stdout
+		cmd.Stderr = opts.Stderr
+	} else {
+		stdout = NewMaskWriter(opts.Stdout, opts.Secrets)
+		stderr = NewMaskWriter(opts.Stderr, opts.Secrets)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+	}
+
+	signals := opts.Signals
+	if signals == nil {
+		ch := make(chan os.Signal, 4)
+		signal.Notify(ch, ForwardSignals...)
+		defer signal.Stop(ch)
+		signals = ch
+	}
+
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case sig := <-signals:
+				cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+	wg.Wait()
+
+	if stdout != nil {
+		stdout.Flush()
+		stderr.Flush()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitCode(exitErr), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,126 @@
+//go:build !windows
+
+package runner_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"password_manager/service/runner"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer 可以并发读写的 buffer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	var stdout, stderr bytes.Buffer
+	code, err := runner.Run(runner.Options{
+		Command: []string{"sh", "-c", `echo "db=$DB_PASS user=$DB_USER"; echo "err $DB_PASS" >&2; exit 3`},
+		Env:     []string{"DB_PASS=s3cret", "DB_USER=admin", "PATH=" + os.Getenv("PATH")},
+		Secrets: []string{"s3cret"},
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	assert.NoError(err)
+	assert.Equal(3, code)
+	assert.Equal("db=***** user=admin\n", stdout.String())
+	assert.Equal("err *****\n", stderr.String())
+
+	// 不屏蔽输出
+	stdout.Reset()
+	code, err = runner.Run(runner.Options{
+		Command: []string{"sh", "-c", `printf %s "$DB_PASS"`},
+		Env:     []string{"DB_PASS=s3cret"},
+		Secrets: []string{"s3cret"},
+		NoMask:  true,
+		Stdout:  &stdout,
+		Stderr:  io.Discard,
+	})
+	assert.NoError(err)
+	assert.Equal(0, code)
+	assert.Equal("s3cret", stdout.String())
+
+	// 命令不存在
+	_, err = runner.Run(runner.Options{Command: []string{"./not-exist-command"}, Stdout: io.Discard, Stderr: io.Discard})
+	assert.Error(err)
+	_, err = runner.Run(runner.Options{})
+	assert.Error(err)
+}
+
+func TestRunForwardSignals(t *testing.T) {
+	assert := assert.New(t)
+	stdout := &syncBuffer{}
+	signals := make(chan os.Signal, 1)
+	result := make(chan int, 1)
+	go func() {
+		code, err := runner.Run(runner.Options{
+			Command: []string{"sh", "-c", `trap 'echo got term; exit 7' TERM; echo ready; while :; do sleep 0.05; done`},
+			Env:     []string{"PATH=" + os.Getenv("PATH")},
+			Stdout:  stdout,
+			Stderr:  io.Discard,
+			Signals: signals,
+		})
+		assert.NoError(err)
+		result <- code
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(stdout.String(), "ready") {
+		if time.Now().After(deadline) {
+			t.Fatal("child did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	signals <- syscall.SIGTERM
+	select {
+	case code := <-result:
+		assert.Equal(7, code)
+		assert.Contains(stdout.String(), "got term")
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal was not forwarded")
+	}
+
+	// 被信号终止时返回 128+信号
+	signals = make(chan os.Signal, 1)
+	go func() {
+		code, err := runner.Run(runner.Options{
+			Command: []string{"sleep", "10"},
+			Env:     []string{"PATH=" + os.Getenv("PATH")},
+			Stdout:  io.Discard,
+			Stderr:  io.Discard,
+			Signals: signals,
+		})
+		assert.NoError(err)
+		result <- code
+	}()
+	time.Sleep(100 * time.Millisecond)
+	signals <- syscall.SIGKILL
+	select {
+	case code := <-result:
+		assert.Equal(128+int(syscall.SIGKILL), code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal was not forwarded")
+	}
+}
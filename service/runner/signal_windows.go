@@ -0,0 +1,16 @@
+//go:build windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+// ForwardSignals 转发给子进程的信号
+var ForwardSignals = []os.Signal{os.Interrupt}
+
+// exitCode 返回子进程的退出码
+func exitCode(err *exec.ExitError) int {
+	return err.ExitCode()
+}
@@ -0,0 +1,30 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize 统一大小写和Unicode形式，去掉变音符号，全角字符转为半角
+func Normalize(s string) string {
+	// NFKD 会把全角字符和兼容字符拆成基础字符，并把变音符号分离出来
+	decomposed := norm.NFKD.String(s)
+	var builder strings.Builder
+	builder.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return norm.NFC.String(strings.TrimSpace(builder.String()))
+}
+
+// tokenize 按非字母数字字符切分文本
+func tokenize(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
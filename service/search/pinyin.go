@@ -0,0 +1,107 @@
+package search
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPinyinCombinations 多音字组合的上限，超过后只使用各字的首个读音
+const maxPinyinCombinations = 32
+
+//go:embed data/pinyin.txt
+var pinyinTable string
+
+var (
+	pinyinOnce sync.Once
+	pinyinDict map[rune][]string
+)
+
+// loadPinyin 解析内嵌的拼音表，只在第一次使用时执行
+func loadPinyin() {
+	pinyinDict = make(map[rune][]string, 21000)
+	scanner := bufio.NewScanner(strings.NewReader(pinyinTable))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		han, readings, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(han)
+		pinyinDict[r] = strings.Split(readings, ",")
+	}
+}
+
+// PinyinReadings 返回汉字的全部读音(不带声调)，不是汉字或不在表中时返回nil
+func PinyinReadings(r rune) []string {
+	if !unicode.Is(unicode.Han, r) {
+		return nil
+	}
+	pinyinOnce.Do(loadPinyin)
+	return pinyinDict[r]
+}
+
+// Pinyin 返回文本的全拼和首字母两种形式，多音字会展开成多个组合
+// 非汉字字符原样保留，文本中没有汉字时返回nil
+func Pinyin(text string) (full []string, initials []string) {
+	type syllable struct {
+		readings []string
+		han      bool
+	}
+	var syllables []syllable
+	hasHan := false
+	for _, r := range text {
+		if readings := PinyinReadings(r); len(readings) > 0 {
+			hasHan = true
+			syllables = append(syllables, syllable{readings, true})
+			continue
+		}
+		syllables = append(syllables, syllable{[]string{string(r)}, false})
+	}
+	if !hasHan {
+		return nil, nil
+	}
+	// 计算组合数量，超过上限时只取首个读音
+	combinations := 1
+	for _, s := range syllables {
+		combinations *= len(s.readings)
+		if combinations > maxPinyinCombinations {
+			break
+		}
+	}
+	fullSeen := make(map[string]bool)
+	initialsSeen := make(map[string]bool)
+	var walk func(i int, fullPrefix, initialsPrefix string)
+	walk = func(i int, fullPrefix, initialsPrefix string) {
+		if i == len(syllables) {
+			if !fullSeen[fullPrefix] {
+				fullSeen[fullPrefix] = true
+				full = append(full, fullPrefix)
+			}
+			if !initialsSeen[initialsPrefix] {
+				initialsSeen[initialsPrefix] = true
+				initials = append(initials, initialsPrefix)
+			}
+			return
+		}
+		readings := syllables[i].readings
+		if combinations > maxPinyinCombinations {
+			readings = readings[:1]
+		}
+		for _, reading := range readings {
+			initial := reading
+			if syllables[i].han {
+				initial = reading[:1]
+			}
+			walk(i+1, fullPrefix+reading, initialsPrefix+initial)
+		}
+	}
+	walk(0, "", "")
+	return full, initials
+}
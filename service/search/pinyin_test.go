@@ -0,0 +1,105 @@
+package search_test
+
+import (
+	"password_manager/service/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinyinReadings(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{"wei"}, search.PinyinReadings('微'))
+	assert.Equal([]string{"lv", "lu"}, search.PinyinReadings('绿'))
+	assert.Contains(search.PinyinReadings('行'), "xing")
+	assert.Contains(search.PinyinReadings('行'), "hang")
+	assert.Nil(search.PinyinReadings('a'))
+	assert.Nil(search.PinyinReadings('1'))
+}
+
+func TestPinyin(t *testing.T) {
+	assert := assert.New(t)
+	full, initials := search.Pinyin("微信")
+	assert.Equal([]string{"weixin", "weishen"}, full)
+	assert.Equal([]string{"wx", "ws"}, initials)
+
+	// 非汉字字符原样保留
+	full, initials = search.Pinyin("qq音乐")
+	assert.Contains(full, "qqyinyue")
+	assert.Contains(full, "qqyinle")
+	assert.Contains(initials, "qqyy")
+	assert.Contains(initials, "qqyl")
+
+	full, initials = search.Pinyin("github")
+	assert.Nil(full)
+	assert.Nil(initials)
+}
+
+func TestPinyinPolyphonic(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		query     string
+		text      string
+	}{
+		{"bank_hang", "yinhang", "银行"},
+		{"walk_xing", "xingzou", "行走"},
+		{"chongqing_chong", "chongqing", "重庆"},
+		{"important_zhong", "zhongyao", "重要"},
+		{"great_wall_chang", "changcheng", "长城"},
+		{"principal_zhang", "xiaozhang", "校长"},
+		{"music_yue", "yinyue", "音乐"},
+		{"happy_le", "kuaile", "快乐"},
+		{"green_lv", "lvse", "绿色"},
+		{"initials_bank", "yh", "银行"},
+		{"initials_chongqing", "cq", "重庆"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			//多音字的每种读音都参与匹配，正确的读音完全匹配而不是模糊匹配
+			assert.Equal(100, search.Score(testCase.query, testCase.text))
+			//完全匹配的拼音排在只是前缀匹配的英文条目前面
+			results := search.Search(testCase.query, []search.Entry{
+				{Key: "latin", Platform: testCase.query + "_app"},
+				{Key: "pinyin", Platform: testCase.text},
+			})
+			if assert.Len(results, 2) {
+				assert.Equal("pinyin", results[0].Entry.Key)
+				assert.Equal("latin", results[1].Entry.Key)
+				assert.Greater(results[0].Score, results[1].Score)
+			}
+		})
+	}
+}
+
+func TestSearchPinyin(t *testing.T) {
+	assert := assert.New(t)
+	entries := []search.Entry{
+		{Key: "wechat_main", Platform: "微信"},
+		{Key: "alipay", Platform: "支付宝"},
+		{Key: "bank_card", Platform: "招商银行"},
+		{Key: "github", Platform: "GitHub"},
+	}
+	var testCases = []struct {
+		test_name string
+		query     string
+		key       string
+	}{
+		{"initials_wx", "wx", "wechat_main"},
+		{"full_weixin", "weixin", "wechat_main"},
+		{"prefix_weix", "weix", "wechat_main"},
+		{"initials_zfb", "zfb", "alipay"},
+		{"full_zhifubao", "zhifubao", "alipay"},
+		{"original_text", "支付宝", "alipay"},
+		{"partial_text", "银行", "bank_card"},
+		{"partial_pinyin", "yinhang", "bank_card"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			results := search.Search(testCase.query, entries)
+			if assert.NotEmpty(results) {
+				assert.Equal(testCase.key, results[0].Entry.Key)
+			}
+		})
+	}
+}
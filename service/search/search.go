@@ -0,0 +1,311 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 各种匹配方式的得分，满分为100
+const (
+	scoreExact       = 100
+	scorePrefix      = 90
+	scoreTokenPrefix = 85
+	scoreContains    = 75
+	scoreSubseqMax   = 70
+	scoreSubseqMin   = 40
+	scoreTypoMax     = 65
+	scoreTypoStep    = 15
+)
+
+// 字段名
+const (
+	FieldKey      = "key"
+	FieldPlatform = "platform"
+	FieldUsername = "username"
+	FieldURL      = "url"
+	FieldTag      = "tag"
+)
+
+// fieldWeights 各字段的权重(百分比)，key 的匹配最重要
+var fieldWeights = map[string]int{
+	FieldKey:      100,
+	FieldPlatform: 90,
+	FieldUsername: 80,
+	FieldTag:      80,
+	FieldURL:      70,
+}
+
+// Entry 参与搜索的条目
+type Entry struct {
+	Key      string
+	Platform string
+	Username string
+	URL      string
+	Tags     []string
+}
+
+// Result 搜索结果
+type Result struct {
+	Entry Entry
+	// Score 0-100，越大越匹配
+	Score int
+	// Field 得分最高的字段
+	Field string
+}
+
+// Search 在所有条目的 key、平台、用户名、URL 和标签中搜索，结果按得分从高到低排序
+// 查询中的多个词需要同时匹配
+func Search(query string, entries []Entry) []Result {
+	terms := strings.Fields(Normalize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	var results []Result
+	for _, entry := range entries {
+		total := 0
+		bestField := ""
+		bestScore := 0
+		matched := true
+		for _, term := range terms {
+			score, field := scoreEntry(term, entry)
+			if score == 0 {
+				matched = false
+				break
+			}
+			total += score
+			if score > bestScore {
+				bestScore = score
+				bestField = field
+			}
+		}
+		if !matched {
+			continue
+		}
+		results = append(results, Result{
+			Entry: entry,
+			Score: total / len(terms),
+			Field: bestField,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Entry.Key < results[j].Entry.Key
+	})
+	return results
+}
+
+// Suggest 返回与 query 最接近的若干候选项，用于 "did you mean" 提示
+func Suggest(query string, candidates []string, limit int) []string {
+	type scored struct {
+		value string
+		score int
+	}
+	var list []scored
+	for _, candidate := range candidates {
+		score := Score(query, candidate)
+		if score == 0 || score == scoreExact {
+			continue
+		}
+		list = append(list, scored{candidate, score})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		return list[i].value < list[j].value
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	suggestions := make([]string, 0, len(list))
+	for _, item := range list {
+		suggestions = append(suggestions, item.value)
+	}
+	return suggestions
+}
+
+// Score 计算 query 与 text 的匹配得分，0 表示不匹配
+func Score(query, text string) int {
+	q := Normalize(query)
+	if q == "" {
+		return 0
+	}
+	best := 0
+	for _, variant := range variants(text) {
+		if score := scoreNormalized(q, variant); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// variants 返回 text 参与匹配的各种形式，包含汉字时追加全拼和首字母
+func variants(text string) []string {
+	t := Normalize(text)
+	if t == "" {
+		return nil
+	}
+	full, initials := Pinyin(t)
+	result := make([]string, 0, 1+len(full)+len(initials))
+	result = append(result, t)
+	result = append(result, full...)
+	result = append(result, initials...)
+	return result
+}
+
+// scoreEntry 计算单个查询词在条目中得分最高的字段
+func scoreEntry(term string, entry Entry) (int, string) {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{FieldKey, entry.Key},
+		{FieldPlatform, entry.Platform},
+		{FieldUsername, entry.Username},
+		{FieldURL, entry.URL},
+	}
+	for _, tag := range entry.Tags {
+		fields = append(fields, struct {
+			name  string
+			value string
+		}{FieldTag, tag})
+	}
+	best := 0
+	bestField := ""
+	for _, field := range fields {
+		score := Score(term, field.value) * fieldWeights[field.name] / 100
+		if score > best {
+			best = score
+			bestField = field.name
+		}
+	}
+	return best, bestField
+}
+
+// scoreNormalized 对已经规范化的 q 和 t 打分
+func scoreNormalized(q, t string) int {
+	switch {
+	case t == q:
+		return scoreExact
+	case strings.HasPrefix(t, q):
+		return scorePrefix
+	}
+	tokens := tokenize(t)
+	for _, token := range tokens {
+		if strings.HasPrefix(token, q) {
+			return scoreTokenPrefix
+		}
+	}
+	if strings.Contains(t, q) {
+		return scoreContains
+	}
+	best := 0
+	if span := subsequenceSpan(q, t); span > 0 {
+		qLen := utf8.RuneCountInString(q)
+		best = scoreSubseqMin + (scoreSubseqMax-scoreSubseqMin)*qLen/span
+	}
+	if score := typoScore(q, t, tokens); score > best {
+		best = score
+	}
+	return best
+}
+
+// subsequenceSpan q 是 t 的子序列时返回最短匹配窗口的长度，否则返回0
+func subsequenceSpan(q, t string) int {
+	qr := []rune(q)
+	tr := []rune(t)
+	best := 0
+	for start := range tr {
+		if tr[start] != qr[0] {
+			continue
+		}
+		i := 0
+		end := start
+		for ; end < len(tr) && i < len(qr); end++ {
+			if tr[end] == qr[i] {
+				i++
+			}
+		}
+		if i < len(qr) {
+			break
+		}
+		if span := end - start; best == 0 || span < best {
+			best = span
+		}
+	}
+	return best
+}
+
+// typoScore 基于编辑距离的容错匹配，比较整个文本、各个分词以及与 q 等长的前缀
+func typoScore(q string, t string, tokens []string) int {
+	qLen := utf8.RuneCountInString(q)
+	maxDist := maxTypos(qLen)
+	if maxDist == 0 {
+		return 0
+	}
+	candidates := append([]string{t}, tokens...)
+	if prefix := []rune(t); len(prefix) > qLen {
+		candidates = append(candidates, string(prefix[:qLen]))
+	}
+	best := maxDist + 1
+	for _, candidate := range candidates {
+		if dist := EditDistance(q, candidate); dist < best {
+			best = dist
+		}
+	}
+	if best > maxDist {
+		return 0
+	}
+	return scoreTypoMax - scoreTypoStep*(best-1)
+}
+
+// maxTypos 根据查询长度决定允许的编辑距离
+func maxTypos(length int) int {
+	switch {
+	case length >= 12:
+		return 3
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// EditDistance 计算两个字符串的编辑距离(允许相邻字符交换)
+func EditDistance(a, b string) int {
+	ar := []rune(a)
+	br := []rune(b)
+	if len(ar) == 0 {
+		return len(br)
+	}
+	if len(br) == 0 {
+		return len(ar)
+	}
+	// prev2、prev、cur 分别是动态规划表中的三行
+	prev2 := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(br)]
+}
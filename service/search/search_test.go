@@ -0,0 +1,113 @@
+package search_test
+
+import (
+	"password_manager/service/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		input     string
+		expected  string
+	}{
+		{"lower_case", "GitHub", "github"},
+		{"diacritics", "Café Crème", "cafe creme"},
+		{"full_width", "ＧｉｔＨｕｂ", "github"},
+		{"trim_space", "  google ", "google"},
+		{"chinese", "微信", "微信"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			assert.Equal(testCase.expected, search.Normalize(testCase.input))
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(0, search.EditDistance("github", "github"))
+	assert.Equal(1, search.EditDistance("githb", "github"))
+	assert.Equal(1, search.EditDistance("gihtub", "github"))
+	assert.Equal(3, search.EditDistance("kitten", "sitting"))
+	assert.Equal(2, search.EditDistance("微信", ""))
+}
+
+func TestScore(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		query     string
+		text      string
+		match     bool
+	}{
+		{"exact", "github", "GitHub", true},
+		{"prefix", "gith", "GitHub", true},
+		{"contains", "hub", "GitHub", true},
+		{"subsequence", "ghb", "GitHub", true},
+		{"typo", "githb", "GitHub", true},
+		{"transposition", "gihtub", "GitHub", true},
+		{"token_prefix", "mail", "google-mail", true},
+		{"no_match", "amazon", "GitHub", false},
+		{"short_no_typo", "gx", "GitHub", false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			score := search.Score(testCase.query, testCase.text)
+			assert.Equal(testCase.match, score > 0, "score %d", score)
+		})
+	}
+	// 排名关系
+	assert.Greater(search.Score("github", "github"), search.Score("git", "github"))
+	assert.Greater(search.Score("git", "github"), search.Score("hub", "github"))
+	assert.Greater(search.Score("hub", "github"), search.Score("githb", "github"))
+}
+
+func TestSearch(t *testing.T) {
+	assert := assert.New(t)
+	entries := []search.Entry{
+		{Key: "github_john", Platform: "GitHub", Username: "john"},
+		{Key: "gitlab_work", Platform: "GitLab", URL: "https://gitlab.example.com"},
+		{Key: "mail", Platform: "Google", Tags: []string{"personal"}},
+		{Key: "bank", Platform: "ICBC", Username: "john.doe", Tags: []string{"finance"}},
+	}
+
+	results := search.Search("github", entries)
+	if assert.NotEmpty(results) {
+		assert.Equal("github_john", results[0].Entry.Key)
+	}
+
+	results = search.Search("example.com", entries)
+	if assert.Len(results, 1) {
+		assert.Equal("gitlab_work", results[0].Entry.Key)
+		assert.Equal(search.FieldURL, results[0].Field)
+	}
+
+	results = search.Search("finance", entries)
+	if assert.Len(results, 1) {
+		assert.Equal("bank", results[0].Entry.Key)
+		assert.Equal(search.FieldTag, results[0].Field)
+	}
+
+	// 多个词需要同时匹配
+	results = search.Search("john bank", entries)
+	if assert.Len(results, 1) {
+		assert.Equal("bank", results[0].Entry.Key)
+	}
+
+	assert.Empty(search.Search("", entries))
+	assert.Empty(search.Search("amazon", entries))
+}
+
+func TestSuggest(t *testing.T) {
+	assert := assert.New(t)
+	keys := []string{"github_john", "gitlab_work", "mail", "bank"}
+	suggestions := search.Suggest("githbu_john", keys, 3)
+	if assert.NotEmpty(suggestions) {
+		assert.Equal("github_john", suggestions[0])
+	}
+	assert.Empty(search.Suggest("zzzz", keys, 3))
+}
@@ -0,0 +1,172 @@
+package secretref
+
+import (
+	"errors"
+	"net/url"
+	"password_manager/service/password"
+	"strings"
+)
+
+const (
+	// Scheme 密码引用的前缀
+	Scheme = "pm://"
+
+	FieldPassword = "password"
+	FieldUsername = "username"
+	FieldURL      = "url"
+	FieldPlatform = "platform"
+	FieldTags     = "tags"
+)
+
+// DefaultVault 当前数据库的名称，引用中省略 vault 时使用
+const DefaultVault = "default"
+
+// Ref 解析后的密码引用 pm://[vault/]key[/field]
+// key 中的 / 需要写成 %2F
+type Ref struct {
+	Vault string
+	Key   string
+	Field string
+}
+
+// String 返回引用的文本形式，Field 为空时省略字段
+// 有 vault 且 key 与字段同名时，省略字段会被解析成 key/field，这时写出默认的 password 字段
+func (r Ref) String() string {
+	s := Scheme
+	if r.Vault != "" {
+		s += url.PathEscape(r.Vault) + "/"
+	}
+	s += url.PathEscape(r.Key)
+	field := r.Field
+	if field == "" && r.Vault != "" && isField(strings.ToLower(r.Key)) {
+		field = FieldPassword
+	}
+	if field != "" {
+		s += "/" + field
+	}
+	return s
+}
+
+// IsRef 判断字符串是否是密码引用
+func IsRef(s string) bool {
+	return strings.HasPrefix(s, Scheme)
+}
+
+// isField 判断是否是可以引用的字段
+func isField(field string) bool {
+	switch field {
+	case FieldPassword, FieldUsername, FieldURL, FieldPlatform, FieldTags:
+		return true
+	}
+	return false
+}
+
+// Parse 解析 pm://[vault/]key[/field]，没有指定字段时为 password
+// 只有两段时，第二段是字段名则解析为 key/field，否则解析为 vault/key
+func Parse(s string) (Ref, error) {
+	if !IsRef(s) {
+		return Ref{}, errors.New("invalid secret reference " + s + ": must start with " + Scheme)
+	}
+	segments := strings.Split(strings.TrimPrefix(s, Scheme), "/")
+	if len(segments) > 3 {
+		return Ref{}, errors.New("invalid secret reference " + s + ": expected pm://[vault/]key[/field]")
+	}
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return Ref{}, errors.New("invalid secret reference " + s + ": " + err.Error())
+		}
+		if unescaped == "" {
+			return Ref{}, errors.New("invalid secret reference " + s + ": empty segment")
+		}
+		segments[i] = unescaped
+	}
+	ref := Ref{Field: FieldPassword}
+	switch len(segments) {
+	case 1:
+		ref.Key = segments[0]
+	case 2:
+		if field := strings.ToLower(segments[1]); isField(field) {
+			ref.Key, ref.Field = segments[0], field
+		} else {
+			ref.Vault, ref.Key = segments[0], segments[1]
+		}
+	case 3:
+		ref.Vault, ref.Key, ref.Field = segments[0], segments[1], strings.ToLower(segments[2])
+	}
+	if !isField(ref.Field) {
+		return Ref{}, errors.New("invalid secret reference " + s + ": unknown field " + ref.Field)
+	}
+	return ref, nil
+}
+
+// Resolver 通过密码服务读取引用的值
+type Resolver struct {
+	srv *password.PasswordService
+	// env 选择的环境，为空时使用默认密码
+	env string
+}
+
+// NewResolver 创建引用解析器
+func NewResolver(srv *password.PasswordService) *Resolver {
+	return &Resolver{srv: srv}
+}
+
+// WithEnv 返回在指定环境下解析密码的解析器
+func (r *Resolver) WithEnv(env string) *Resolver {
+	return &Resolver{srv: r.srv, env: env}
+}
+
+// Resolve 解析引用并返回对应字段的值
+func (r *Resolver) Resolve(s string) (string, error) {
+	ref, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return r.ResolveRef(ref)
+}
+
+// ResolveRef 返回引用对应字段的值，字段为空时返回错误，避免注入空值。
+// 解析密码时记录一次使用，用户名等其他字段不记录
+func (r *Resolver) ResolveRef(ref Ref) (string, error) {
+	value, err := r.fieldValue(ref)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", errors.New("field " + ref.Field + " of " + ref.Key + " is not set")
+	}
+	if ref.Field == FieldPassword {
+		r.srv.RecordAccess(ref.Key)
+	}
+	return value, nil
+}
+
+// fieldValue 读取引用对应字段的值
+func (r *Resolver) fieldValue(ref Ref) (string, error) {
+	if ref.Vault != "" && ref.Vault != DefaultVault {
+		return "", errors.New("vault " + ref.Vault + " not found")
+	}
+	pwd, platform, err := r.srv.GetPasswordForEnv(ref.Key, r.env)
+	if err != nil {
+		return "", err
+	}
+	if ref.Field == FieldPassword {
+		return pwd, nil
+	}
+	if ref.Field == FieldPlatform {
+		return platform, nil
+	}
+	meta, err := r.srv.GetMeta(ref.Key)
+	if err != nil {
+		return "", err
+	}
+	switch ref.Field {
+	case FieldUsername:
+		return meta.Username, nil
+	case FieldURL:
+		return meta.URL, nil
+	default:
+		return strings.Join(meta.Tags, ","), nil
+	}
+}
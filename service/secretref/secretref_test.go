@@ -0,0 +1,156 @@
+package secretref_test
+
+import (
+	"errors"
+	"password_manager/service/password"
+	"password_manager/service/secretref"
+	"password_manager/service/testvault"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		ref       string
+		expected  secretref.Ref
+		hasErr    bool
+	}{
+		{"key_only", "pm://prod_db", secretref.Ref{Key: "prod_db", Field: "password"}, false},
+		{"field", "pm://prod_db/username", secretref.Ref{Key: "prod_db", Field: "username"}, false},
+		{"field_case", "pm://prod_db/URL", secretref.Ref{Key: "prod_db", Field: "url"}, false},
+		{"escaped_key", "pm://team%2Fdb/password", secretref.Ref{Key: "team/db", Field: "password"}, false},
+		{"unicode_key", "pm://邮箱", secretref.Ref{Key: "邮箱", Field: "password"}, false},
+		{"vault_key", "pm://default/prod_db", secretref.Ref{Vault: "default", Key: "prod_db", Field: "password"}, false},
+		{"vault_key_field", "pm://default/prod_db/username", secretref.Ref{Vault: "default", Key: "prod_db", Field: "username"}, false},
+		{"vault_key_named_as_field", "pm://default/password/password", secretref.Ref{Vault: "default", Key: "password", Field: "password"}, false},
+		{"empty_segment", "pm://default//password", secretref.Ref{}, true},
+		{"bad_escape", "pm://prod%zz", secretref.Ref{}, true},
+		{"no_scheme", "prod_db/password", secretref.Ref{}, true},
+		{"empty_key", "pm:///password", secretref.Ref{}, true},
+		{"unknown_field", "pm://default/prod_db/secret", secretref.Ref{}, true},
+		{"too_many", "pm://a/b/c/d", secretref.Ref{}, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			ref, err := secretref.Parse(testCase.ref)
+			if testCase.hasErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(testCase.expected, ref)
+		})
+	}
+	assert.Equal("pm://team%2Fdb/password", secretref.Ref{Key: "team/db", Field: "password"}.String())
+	assert.Equal("pm://default/prod_db/url", secretref.Ref{Vault: "default", Key: "prod_db", Field: "url"}.String())
+	//没有字段时不以 / 结尾
+	assert.Equal("pm://github", secretref.Ref{Key: "github"}.String())
+	assert.Equal("pm://default/github", secretref.Ref{Vault: "default", Key: "github"}.String())
+	//省略字段会产生歧义时写出默认字段
+	assert.Equal("pm://default/url/password", secretref.Ref{Vault: "default", Key: "url"}.String())
+	for _, ref := range []secretref.Ref{{Key: "github"}, {Vault: "default", Key: "github"}, {Vault: "default", Key: "url"}} {
+		parsed, err := secretref.Parse(ref.String())
+		assert.NoError(err)
+		assert.Equal(ref.Key, parsed.Key)
+		assert.Equal(ref.Vault, parsed.Vault)
+		assert.Equal(secretref.FieldPassword, parsed.Field)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	assert := assert.New(t)
+	srv := testvault.New(t).Srv
+	assert.NoError(srv.SavePassword("prod_db", "s3cret", "postgres"))
+	assert.NoError(srv.SetMeta("prod_db", password.Meta{Username: "admin", URL: "db.internal:5432", Tags: []string{"prod", "db"}}))
+
+	resolver := secretref.NewResolver(srv)
+	var testCases = []struct {
+		ref      string
+		expected string
+	}{
+		{"pm://prod_db", "s3cret"},
+		{"pm://prod_db/password", "s3cret"},
+		{"pm://prod_db/username", "admin"},
+		{"pm://prod_db/url", "db.internal:5432"},
+		{"pm://prod_db/platform", "postgres"},
+		{"pm://prod_db/tags", "prod,db"},
+		{"pm://default/prod_db", "s3cret"},
+		{"pm://default/prod_db/username", "admin"},
+	}
+	for _, testCase := range testCases {
+		value, err := resolver.Resolve(testCase.ref)
+		assert.NoError(err, testCase.ref)
+		assert.Equal(testCase.expected, value, testCase.ref)
+	}
+	// 选择环境
+	assert.NoError(srv.SetEnvPassword("prod_db", "prod", "prod-s3cret"))
+	value, err := resolver.WithEnv("prod").Resolve("pm://prod_db")
+	assert.NoError(err)
+	assert.Equal("prod-s3cret", value)
+	value, err = resolver.Resolve("pm://prod_db")
+	assert.NoError(err)
+	assert.Equal("s3cret", value)
+	_, err = resolver.WithEnv("dev").Resolve("pm://prod_db")
+	assert.Error(err)
+	// 解析成功的密码各记录一次使用，用户名等其他字段不记录
+	access, err := srv.GetAccess("prod_db")
+	assert.NoError(err)
+	assert.Equal(5, access.Count)
+
+	_, err = resolver.Resolve("pm://missing")
+	assert.Error(err)
+	_, err = resolver.Resolve("pm://work/prod_db/password")
+	assert.EqualError(err, "vault work not found")
+
+	// 没有设置的字段不能解析为空字符串
+	assert.NoError(srv.SavePassword("api", "tok3n", ""))
+	for _, field := range []string{secretref.FieldUsername, secretref.FieldPlatform, secretref.FieldURL, secretref.FieldTags} {
+		_, err = resolver.Resolve("pm://api/" + field)
+		assert.EqualError(err, "field "+field+" of api is not set", field)
+	}
+	_, err = secretref.Render([]byte("u={{ pm://api/username }} p={{ pm://api/platform }}"), resolver.ResolveRef)
+	assert.Error(err)
+}
+
+func TestRender(t *testing.T) {
+	assert := assert.New(t)
+	values := map[string]string{"prod_db": "s3cret", "api": "tok en"}
+	resolve := func(ref secretref.Ref) (string, error) {
+		value, ok := values[ref.Key]
+		if !ok {
+			return "", errors.New("key:" + ref.Key + " not found")
+		}
+		return ref.Field + "=" + value, nil
+	}
+	var testCases = []struct {
+		test_name string
+		template  string
+		expected  string
+		errs      []string
+	}{
+		{"no_ref", "a: 1\n", "a: 1\n", nil},
+		{"refs", "db: {{ pm://prod_db }}\napi: {{pm://default/api/username}}\n", "db: password=s3cret\napi: username=tok en\n", nil},
+		{"repeated", "{{ pm://prod_db }}{{ pm://prod_db }}", "password=s3cretpassword=s3cret", nil},
+		{"other_braces", "name: {{ .Values.name }}\n", "name: {{ .Values.name }}\n", nil},
+		{"missing", "a: {{ pm://prod_db }}\nb: {{ pm://missing }}\nc: {{ pm://default/prod_db/secret }}\n", "",
+			[]string{"line 2: pm://missing: key:missing not found", "line 3: invalid secret reference pm://default/prod_db/secret"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			out, err := secretref.Render([]byte(testCase.template), resolve)
+			if testCase.errs != nil {
+				assert.Error(err)
+				assert.Nil(out)
+				for _, msg := range testCase.errs {
+					assert.Contains(err.Error(), msg)
+				}
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(testCase.expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package secretref
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateRef 模板中的引用 {{ pm://... }}，其他的 {{ }} 原样保留
+var templateRef = regexp.MustCompile(`\{\{\s*(pm://[^\s{}]+)\s*\}\}`)
+
+// Render 把模板中的 {{ pm://... }} 替换为引用的值
+// 任何一个引用无法解析时返回所有失败的引用，不输出部分结果
+func Render(template []byte, resolve func(Ref) (string, error)) ([]byte, error) {
+	var (
+		out      bytes.Buffer
+		failures []string
+		last     int
+	)
+	cache := make(map[string]string)
+	for _, match := range templateRef.FindAllSubmatchIndex(template, -1) {
+		out.Write(template[last:match[0]])
+		last = match[1]
+		text := string(template[match[2]:match[3]])
+		line := strconv.Itoa(bytes.Count(template[:match[0]], []byte("\n")) + 1)
+		if value, ok := cache[text]; ok {
+			out.WriteString(value)
+			continue
+		}
+		ref, err := Parse(text)
+		if err != nil {
+			failures = append(failures, "line "+line+": "+err.Error())
+			continue
+		}
+		value, err := resolve(ref)
+		if err != nil {
+			failures = append(failures, "line "+line+": "+text+": "+err.Error())
+			continue
+		}
+		cache[text] = value
+		out.WriteString(value)
+	}
+	if len(failures) != 0 {
+		return nil, errors.New("failed to resolve secret references:\n  " + strings.Join(failures, "\n  "))
+	}
+	out.Write(template[last:])
+	return out.Bytes(), nil
+}
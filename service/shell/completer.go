@@ -0,0 +1,74 @@
+package shell
+
+import (
+	"sort"
+	"strings"
+)
+
+// Completer 根据已输入的内容补全命令名和key
+type Completer struct {
+	// commands 可以补全的命令
+	commands []string
+	// keyCommands 第一个参数是key的命令
+	keyCommands map[string]bool
+	// keys 获取当前所有的key，会话锁定时返回nil
+	keys func() []string
+}
+
+// NewCompleter 创建补全器
+func NewCompleter(commands []string, keyCommands []string, keys func() []string) *Completer {
+	sorted := append([]string(nil), commands...)
+	sort.Strings(sorted)
+	keyCommandSet := make(map[string]bool, len(keyCommands))
+	for _, command := range keyCommands {
+		keyCommandSet[command] = true
+	}
+	return &Completer{
+		commands:    sorted,
+		keyCommands: keyCommandSet,
+		keys:        keys,
+	}
+}
+
+// Complete 返回补全后的整行候选
+func (c *Completer) Complete(line string) []string {
+	fields := strings.Fields(line)
+	endsWithSpace := strings.HasSuffix(line, " ")
+	// 补全命令名
+	if len(fields) == 0 || (len(fields) == 1 && !endsWithSpace) {
+		prefix := ""
+		if len(fields) == 1 {
+			prefix = fields[0]
+		}
+		return withPrefix(c.commands, prefix, "")
+	}
+	// 补全key
+	if !c.keyCommands[fields[0]] || c.keys == nil {
+		return nil
+	}
+	if len(fields) == 1 && endsWithSpace {
+		return withPrefix(c.sortedKeys(), "", fields[0]+" ")
+	}
+	if len(fields) == 2 && !endsWithSpace {
+		return withPrefix(c.sortedKeys(), fields[1], fields[0]+" ")
+	}
+	return nil
+}
+
+// sortedKeys 返回排序后的key
+func (c *Completer) sortedKeys() []string {
+	keys := append([]string(nil), c.keys()...)
+	sort.Strings(keys)
+	return keys
+}
+
+// withPrefix 返回以 prefix 开头的候选项，并加上 head
+func withPrefix(candidates []string, prefix, head string) []string {
+	var result []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			result = append(result, head+candidate)
+		}
+	}
+	return result
+}
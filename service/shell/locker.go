@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrWrongPassword 解锁时输入的主密码错误
+var ErrWrongPassword = errors.New("wrong master password")
+
+// Locker 交互式会话的空闲锁定，超过空闲时间后调用 onLock 释放已经解锁的数据
+type Locker struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	timer   *time.Timer
+	busy    bool
+	locked  bool
+	onLock  func()
+}
+
+// NewLocker 创建空闲锁定器，并开始计时
+func NewLocker(timeout time.Duration, onLock func()) *Locker {
+	l := &Locker{
+		timeout: timeout,
+		onLock:  onLock,
+	}
+	l.timer = time.AfterFunc(timeout, l.expire)
+	return l
+}
+
+// Begin 开始执行命令，会话已经锁定时返回false
+// 命令执行期间不会因为空闲而锁定
+func (l *Locker) Begin() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locked {
+		return false
+	}
+	l.busy = true
+	l.timer.Stop()
+	return true
+}
+
+// End 命令执行结束，重新开始计时
+func (l *Locker) End() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.busy = false
+	if !l.locked {
+		l.timer.Reset(l.timeout)
+	}
+}
+
+// Lock 立即锁定会话
+func (l *Locker) Lock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lockLocked()
+}
+
+// Unlock 解锁会话并重新开始计时
+func (l *Locker) Unlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locked = false
+	l.timer.Reset(l.timeout)
+}
+
+// UnlockWithPassword 验证主密码后解锁会话，verify 与 tui.Options.Verify 相同，为 nil 时直接解锁
+// 密码错误或者验证出错时会话保持锁定
+func (l *Locker) UnlockWithPassword(verify func(password string) (bool, error), password string) error {
+	if verify != nil {
+		ok, err := verify(password)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrWrongPassword
+		}
+	}
+	l.Unlock()
+	return nil
+}
+
+// Locked 会话是否已经锁定
+func (l *Locker) Locked() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.locked
+}
+
+// Stop 停止计时
+func (l *Locker) Stop() {
+	l.timer.Stop()
+}
+
+// expire 空闲时间到达
+func (l *Locker) expire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.busy {
+		return
+	}
+	l.lockLocked()
+}
+
+// lockLocked 在持有锁的情况下锁定会话
+func (l *Locker) lockLocked() {
+	if l.locked {
+		return
+	}
+	l.locked = true
+	l.timer.Stop()
+	if l.onLock != nil {
+		l.onLock()
+	}
+}
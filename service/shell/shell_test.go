@@ -0,0 +1,120 @@
+package shell_test
+
+import (
+	"password_manager/service/master"
+	"password_manager/service/shell"
+	"password_manager/service/testvault"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		test_name string
+		line      string
+		expected  []string
+		hasErr    bool
+	}{
+		{"empty", "   ", nil, false},
+		{"simple", "query github", []string{"query", "github"}, false},
+		{"extra_space", "  list   ", []string{"list"}, false},
+		{"double_quote", `add "my key" --tag "a b"`, []string{"add", "my key", "--tag", "a b"}, false},
+		{"single_quote", `search 'john doe'`, []string{"search", "john doe"}, false},
+		{"escape", `query my\ key`, []string{"query", "my key"}, false},
+		{"empty_quote", `update key --url ""`, []string{"update", "key", "--url", ""}, false},
+		{"unterminated", `query "abc`, nil, true},
+		{"trailing_escape", `query abc\`, nil, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.test_name, func(t *testing.T) {
+			args, err := shell.Split(testCase.line)
+			if testCase.hasErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(testCase.expected, args)
+		})
+	}
+}
+
+func TestCompleter(t *testing.T) {
+	assert := assert.New(t)
+	keys := []string{"github_john", "gitlab", "mail"}
+	completer := shell.NewCompleter(
+		[]string{"query", "quit", "list", "del"},
+		[]string{"query", "del"},
+		func() []string { return keys },
+	)
+	assert.Equal([]string{"query", "quit"}, completer.Complete("q"))
+	assert.Equal([]string{"del", "list", "query", "quit"}, completer.Complete(""))
+	assert.Equal([]string{"query github_john", "query gitlab"}, completer.Complete("query git"))
+	assert.Equal([]string{"del github_john", "del gitlab", "del mail"}, completer.Complete("del "))
+	assert.Nil(completer.Complete("list g"))
+	assert.Nil(completer.Complete("query github_john "))
+
+	// 锁定后不补全key
+	locked := shell.NewCompleter([]string{"query"}, []string{"query"}, func() []string { return nil })
+	assert.Nil(locked.Complete("query g"))
+}
+
+func TestLocker(t *testing.T) {
+	assert := assert.New(t)
+	var lockCount int32
+	locker := shell.NewLocker(50*time.Millisecond, func() {
+		atomic.AddInt32(&lockCount, 1)
+	})
+	defer locker.Stop()
+
+	// 执行命令期间不会锁定
+	assert.True(locker.Begin())
+	time.Sleep(100 * time.Millisecond)
+	assert.False(locker.Locked())
+	locker.End()
+
+	// 空闲超时后锁定
+	time.Sleep(100 * time.Millisecond)
+	assert.True(locker.Locked())
+	assert.Equal(int32(1), atomic.LoadInt32(&lockCount))
+	assert.False(locker.Begin())
+
+	// 解锁后重新计时
+	locker.Unlock()
+	assert.False(locker.Locked())
+	assert.True(locker.Begin())
+	locker.End()
+
+	// 手动锁定只触发一次
+	locker.Lock()
+	locker.Lock()
+	assert.True(locker.Locked())
+	assert.Equal(int32(2), atomic.LoadInt32(&lockCount))
+}
+
+func TestLockerUnlockWithPassword(t *testing.T) {
+	assert := assert.New(t)
+	masterSrv := master.NewMasterService(testvault.New(t).DB)
+	assert.NoError(masterSrv.SetMasterPassword("correct horse"))
+	locker := shell.NewLocker(time.Minute, nil)
+	defer locker.Stop()
+	locker.Lock()
+
+	// 主密码错误时保持锁定
+	assert.ErrorIs(locker.UnlockWithPassword(masterSrv.Verify, "wrong horse"), shell.ErrWrongPassword)
+	assert.True(locker.Locked())
+	assert.False(locker.Begin())
+	assert.ErrorIs(locker.UnlockWithPassword(masterSrv.Verify, ""), shell.ErrWrongPassword)
+	assert.True(locker.Locked())
+
+	assert.NoError(locker.UnlockWithPassword(masterSrv.Verify, "correct horse"))
+	assert.False(locker.Locked())
+
+	// 没有设置主密码时直接解锁
+	locker.Lock()
+	assert.NoError(locker.UnlockWithPassword(nil, ""))
+	assert.False(locker.Locked())
+}
@@ -0,0 +1,219 @@
+package sshagent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// SocketEnv 指定 ssh agent socket 路径的环境变量
+const SocketEnv = "PM_SSH_AUTH_SOCK"
+
+// ErrRefused 用户拒绝了签名请求
+var ErrRefused = errors.New("signature refused by user")
+
+// ConfirmRequest 签名前需要用户确认的内容
+type ConfirmRequest struct {
+	// Comment 密钥的注释，从密码库加载的密钥为条目的 key
+	Comment     string
+	Fingerprint string
+}
+
+// Options ssh agent 的配置
+type Options struct {
+	// ConfirmAll 每次签名都需要确认
+	ConfirmAll bool
+	// Confirm 签名前询问用户，返回 true 表示允许
+	Confirm func(req ConfirmRequest) (bool, error)
+}
+
+// Agent 实现 OpenSSH agent 协议，密钥只保存在内存中
+// 签名、锁定等操作交给 x/crypto 的 keyring，这里只增加签名确认和从密码库加载
+type Agent struct {
+	logger  *zap.Logger
+	opts    Options
+	keyring agent.ExtendedAgent
+
+	mu sync.Mutex
+	// confirmKeys 添加时要求确认的密钥，key 为公钥的序列化结果
+	confirmKeys map[string]bool
+	// vaultKeys 从密码库加载的密钥，重新加载时先删除
+	vaultKeys map[string]ssh.PublicKey
+	// confirmMu 同一时间只弹出一个确认
+	confirmMu sync.Mutex
+}
+
+// NewAgent 创建没有密钥的 agent
+func NewAgent(opts Options) *Agent {
+	return &Agent{
+		logger:      zap.L(),
+		opts:        opts,
+		keyring:     agent.NewKeyring().(agent.ExtendedAgent),
+		confirmKeys: make(map[string]bool),
+		vaultKeys:   make(map[string]ssh.PublicKey),
+	}
+}
+
+// DefaultSocketPath 返回 ssh agent socket 的默认路径
+// 优先使用环境变量 PM_SSH_AUTH_SOCK，其次是 XDG_RUNTIME_DIR，最后是临时目录下只属于当前用户的目录
+func DefaultSocketPath() string {
+	if path := os.Getenv(SocketEnv); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "pm-ssh-agent.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("pm-agent-%d", os.Getuid()), "ssh-agent.sock")
+}
+
+// Serve 接受连接并按照 agent 协议处理，监听关闭后返回nil
+func (a *Agent) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := agent.ServeAgent(a, conn); err != nil && !errors.Is(err, io.EOF) {
+				a.logger.Debug("ssh agent connection closed", zap.Error(err))
+			}
+		}()
+	}
+}
+
+// List 返回所有密钥的公钥
+func (a *Agent) List() ([]*agent.Key, error) {
+	return a.keyring.List()
+}
+
+// Add 添加密钥，ConfirmBeforeUse 的密钥每次签名都需要确认
+func (a *Agent) Add(key agent.AddedKey) error {
+	if err := a.keyring.Add(key); err != nil {
+		return err
+	}
+	signer, err := ssh.NewSignerFromKey(key.PrivateKey)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	blob := string(signer.PublicKey().Marshal())
+	if key.ConfirmBeforeUse {
+		a.confirmKeys[blob] = true
+	} else {
+		delete(a.confirmKeys, blob)
+	}
+	return nil
+}
+
+// Remove 删除密钥
+func (a *Agent) Remove(key ssh.PublicKey) error {
+	if err := a.keyring.Remove(key); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.confirmKeys, string(key.Marshal()))
+	delete(a.vaultKeys, string(key.Marshal()))
+	return nil
+}
+
+// RemoveAll 删除所有密钥
+func (a *Agent) RemoveAll() error {
+	if err := a.keyring.RemoveAll(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.confirmKeys = make(map[string]bool)
+	a.vaultKeys = make(map[string]ssh.PublicKey)
+	return nil
+}
+
+// Lock 使用口令锁定 agent，锁定后不能列出密钥和签名
+func (a *Agent) Lock(passphrase []byte) error {
+	return a.keyring.Lock(passphrase)
+}
+
+// Unlock 使用锁定时的口令解锁
+func (a *Agent) Unlock(passphrase []byte) error {
+	return a.keyring.Unlock(passphrase)
+}
+
+// Sign 使用密钥签名
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags 使用密钥签名，需要确认时先询问用户
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if err := a.confirm(key); err != nil {
+		return nil, err
+	}
+	return a.keyring.SignWithFlags(key, data, flags)
+}
+
+// Signers 返回所有密钥的签名器，只在进程内部使用，不需要确认
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return a.keyring.Signers()
+}
+
+// Extension 不支持任何扩展
+func (a *Agent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+// confirm 需要确认时询问用户
+func (a *Agent) confirm(key ssh.PublicKey) error {
+	a.mu.Lock()
+	required := a.opts.ConfirmAll || a.confirmKeys[string(key.Marshal())]
+	a.mu.Unlock()
+	if !required {
+		return nil
+	}
+	//找到密钥的注释，锁定或者密钥不存在时交给 keyring 返回错误
+	keys, err := a.keyring.List()
+	if err != nil {
+		return err
+	}
+	req := ConfirmRequest{Fingerprint: ssh.FingerprintSHA256(key)}
+	found := false
+	for _, k := range keys {
+		if bytes.Equal(k.Blob, key.Marshal()) {
+			req.Comment = k.Comment
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+	if a.opts.Confirm == nil {
+		return errors.New("confirmation is required but no prompt is available")
+	}
+	a.confirmMu.Lock()
+	defer a.confirmMu.Unlock()
+	allowed, err := a.opts.Confirm(req)
+	if err != nil {
+		a.logger.Warn("confirm signature failed", zap.Error(err))
+		return err
+	}
+	if !allowed {
+		a.logger.Info("signature refused", zap.String("key", req.Comment))
+		return ErrRefused
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package sshagent_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"net"
+	"os"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/password"
+	"password_manager/service/sshagent"
+	"password_manager/service/testvault"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// marshalKey 生成 OpenSSH 格式的 PEM 私钥
+func marshalKey(t *testing.T, key any, passphrase string) []byte {
+	var (
+		block *pem.Block
+		err   error
+	)
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+// startAgent 在临时目录的 socket 上启动 agent，返回 x/crypto 的客户端
+func startAgent(t *testing.T, a *sshagent.Agent) agent.ExtendedAgent {
+	dir, err := os.MkdirTemp("", "pmssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("unix", filepath.Join(dir, "agent.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go a.Serve(listener)
+	conn, err := net.Dial("unix", filepath.Join(dir, "agent.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		listener.Close()
+		os.RemoveAll(dir)
+	})
+	return agent.NewClient(conn)
+}
+
+func TestAgent(t *testing.T) {
+	assert := assert.New(t)
+	vault := testvault.New(t)
+	srv := vault.Srv
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+	_, encryptedKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+
+	_, err = sshagent.ImportKey(srv, "id_ed25519", "github", marshalKey(t, edKey, ""), "")
+	assert.NoError(err)
+	_, err = sshagent.ImportKey(srv, "id_rsa", "", marshalKey(t, rsaKey, ""), "")
+	assert.NoError(err)
+	_, err = sshagent.ImportKey(srv, "id_ecdsa", "", marshalKey(t, ecKey, "secret"), "secret")
+	assert.NoError(err)
+	//加密的私钥没有口令时不能导入
+	_, err = sshagent.ImportKey(srv, "id_encrypted", "", marshalKey(t, encryptedKey, "secret"), "")
+	assert.Error(err)
+	_, err = sshagent.ImportKey(srv, "garbage", "", []byte("not a key"), "")
+	assert.Error(err)
+	record, err := srv.GetRecord("id_ed25519")
+	assert.NoError(err)
+	edSigner, err := ssh.NewSignerFromKey(edKey)
+	assert.NoError(err)
+	assert.Equal(string(ssh.MarshalAuthorizedKey(edSigner.PublicKey())), record.Fields["public_key"]+"\n")
+
+	//口令错误的条目跳过，其他密钥正常加载
+	assert.NoError(srv.SaveRecord("broken", "", password.Record{Type: password.TypeSSHKey, Fields: map[string]string{
+		"private_key": string(marshalKey(t, encryptedKey, "secret")),
+		"passphrase":  "wrong",
+	}}))
+	//加载时只解密 ssh_key 条目，其他条目的密文损坏也不影响
+	assert.NoError(srv.SavePassword("github_login", "secret", "github"))
+	assert.NoError(vault.DB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.PasswordBucketName))
+		value := append([]byte(nil), bucket.Get([]byte("github_login"))...)
+		value[len(value)-1] ^= 0xff
+		return bucket.Put([]byte("github_login"), value)
+	}))
+	var confirms atomic.Int32
+	var allow atomic.Bool
+	a := sshagent.NewAgent(sshagent.Options{Confirm: func(req sshagent.ConfirmRequest) (bool, error) {
+		confirms.Add(1)
+		assert.Equal("id_rsa", req.Comment)
+		return allow.Load(), nil
+	}})
+	names, err := a.LoadVault(srv)
+	assert.Error(err)
+	assert.Contains(err.Error(), "broken")
+	assert.Equal([]string{"id_ecdsa", "id_ed25519", "id_rsa"}, names)
+
+	client := startAgent(t, a)
+	keys, err := client.List()
+	assert.NoError(err)
+	assert.Len(keys, 3)
+	comments := map[string]*agent.Key{}
+	for _, key := range keys {
+		comments[key.Comment] = key
+	}
+	assert.Equal(ssh.KeyAlgoED25519, comments["id_ed25519"].Type())
+	assert.Equal(ssh.KeyAlgoRSA, comments["id_rsa"].Type())
+	assert.Equal(ssh.KeyAlgoECDSA256, comments["id_ecdsa"].Type())
+
+	//每种密钥签名后使用公钥验证
+	data := []byte("session data")
+	for _, key := range keys {
+		signature, err := client.Sign(key, data)
+		assert.NoError(err, key.Comment)
+		assert.NoError(key.Verify(data, signature), key.Comment)
+	}
+	signature, err := client.SignWithFlags(comments["id_rsa"], data, agent.SignatureFlagRsaSha512)
+	assert.NoError(err)
+	assert.Equal(ssh.KeyAlgoRSASHA512, signature.Format)
+	assert.Equal(int32(0), confirms.Load())
+
+	//添加时要求确认的密钥，拒绝时签名失败
+	assert.NoError(client.Add(agent.AddedKey{PrivateKey: rsaKey, Comment: "id_rsa", ConfirmBeforeUse: true}))
+	_, err = client.Sign(comments["id_rsa"], data)
+	assert.Error(err)
+	allow.Store(true)
+	_, err = client.Sign(comments["id_rsa"], data)
+	assert.NoError(err)
+	assert.Equal(int32(2), confirms.Load())
+
+	//锁定后不能列出密钥和签名
+	assert.NoError(client.Lock([]byte("pass")))
+	keys, err = client.List()
+	assert.NoError(err)
+	assert.Len(keys, 0)
+	_, err = client.Sign(comments["id_ed25519"], data)
+	assert.Error(err)
+	assert.Error(client.Unlock([]byte("wrong")))
+	assert.NoError(client.Unlock([]byte("pass")))
+
+	//删除密钥后重新加载，密码库中的密钥恢复
+	assert.NoError(client.Remove(comments["id_ed25519"]))
+	keys, err = client.List()
+	assert.NoError(err)
+	assert.Len(keys, 2)
+	assert.NoError(srv.DeletePassword("broken"))
+	names, err = a.LoadVault(srv)
+	assert.NoError(err)
+	assert.Len(names, 3)
+	keys, err = client.List()
+	assert.NoError(err)
+	assert.Len(keys, 3)
+	//每次加载记录一次使用
+	access, err := srv.GetAccess("id_rsa")
+	assert.NoError(err)
+	assert.Equal(2, access.Count)
+}
+
+func TestConfirmAll(t *testing.T) {
+	assert := assert.New(t)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+	//没有确认方式时拒绝签名
+	a := sshagent.NewAgent(sshagent.Options{ConfirmAll: true})
+	client := startAgent(t, a)
+	assert.NoError(client.Add(agent.AddedKey{PrivateKey: edKey, Comment: "manual"}))
+	keys, err := client.List()
+	assert.NoError(err)
+	_, err = client.Sign(keys[0], []byte("data"))
+	assert.Error(err)
+
+	var requests []sshagent.ConfirmRequest
+	a = sshagent.NewAgent(sshagent.Options{ConfirmAll: true, Confirm: func(req sshagent.ConfirmRequest) (bool, error) {
+		requests = append(requests, req)
+		return true, nil
+	}})
+	client = startAgent(t, a)
+	assert.NoError(client.Add(agent.AddedKey{PrivateKey: edKey, Comment: "manual"}))
+	_, err = client.Sign(keys[0], []byte("data"))
+	assert.NoError(err)
+	assert.Equal([]sshagent.ConfirmRequest{{Comment: "manual", Fingerprint: ssh.FingerprintSHA256(keys[0])}}, requests)
+}
@@ -0,0 +1,120 @@
+package sshagent
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"password_manager/service/password"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ErrPassphraseRequired 私钥已加密，需要口令
+var ErrPassphraseRequired = errors.New("private key is encrypted, a passphrase is required")
+
+// ParsePrivateKey 解析 PEM 格式的私钥，只支持 ed25519、RSA 和 ECDSA
+func ParsePrivateKey(pemData []byte, passphrase string) (any, error) {
+	var (
+		key any
+		err error
+	)
+	if passphrase == "" {
+		key, err = ssh.ParseRawPrivateKey(pemData)
+	} else {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(pemData, []byte(passphrase))
+	}
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, ErrPassphraseRequired
+		}
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *ed25519.PrivateKey:
+		return *k, nil
+	case ed25519.PrivateKey, *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, errors.New("unsupported private key type, expected ed25519, RSA or ECDSA")
+	}
+}
+
+// ImportKey 把私钥保存为密码库中的 ssh_key 条目，公钥从私钥中生成
+func ImportKey(srv *password.PasswordService, name, platform string, pemData []byte, passphrase string) (any, error) {
+	key, err := ParsePrivateKey(pemData, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{
+		"private_key": string(pemData),
+		"public_key":  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+		"passphrase":  passphrase,
+	}
+	if err := srv.SaveRecord(name, platform, password.Record{Type: password.TypeSSHKey, Fields: fields}); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// LoadVault 加载密码库中所有 ssh_key 条目，之前从密码库加载的密钥会先删除，加载的每个密钥记录一次使用
+// 按类型过滤时只解密类型，其他条目的密码不会解密。单个条目加载失败不影响其他条目，失败的原因合并后返回
+func (a *Agent) LoadVault(srv *password.PasswordService) ([]string, error) {
+	all, err := srv.GetAllEntries()
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	previous := a.vaultKeys
+	a.vaultKeys = make(map[string]ssh.PublicKey)
+	a.mu.Unlock()
+	for _, publicKey := range previous {
+		if err := a.keyring.Remove(publicKey); err != nil {
+			a.logger.Warn("remove vault key failed", zap.Error(err))
+		}
+	}
+
+	var names []string
+	var errs []error
+	for _, data := range all {
+		if data.Type != password.TypeSSHKey {
+			continue
+		}
+		name := data.Key
+		record, err := srv.GetRecord(name)
+		if err != nil {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+			continue
+		}
+		key, err := ParsePrivateKey([]byte(record.Fields["private_key"]), record.Fields["passphrase"])
+		if err != nil {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+			continue
+		}
+		if err := a.Add(agent.AddedKey{PrivateKey: key, Comment: name}); err != nil {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+			continue
+		}
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+			continue
+		}
+		a.mu.Lock()
+		a.vaultKeys[string(signer.PublicKey().Marshal())] = signer.PublicKey()
+		a.mu.Unlock()
+		srv.RecordAccess(name)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, errors.Join(errs...)
+}
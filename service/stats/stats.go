@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"password_manager/service/password"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultRecent 统计最近创建、修改和使用的条目时使用的时间范围
+	DefaultRecent = 30 * 24 * time.Hour
+	// DefaultTop 最常用和最久没有使用的列表最多列出的条目数量
+	DefaultTop = 5
+)
+
+// Options 统计的配置
+type Options struct {
+	// Recent 最近的时间范围
+	Recent time.Duration
+	// Top 列表最多列出的条目数量，0 表示不列出
+	Top int
+	// Now 当前时间，为零值时使用 time.Now
+	Now time.Time
+}
+
+// TypeCount 某个类型的条目数量
+type TypeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// Usage 条目的使用情况，从未使用时 LastUsed 为零值
+type Usage struct {
+	Key      string    `json:"key"`
+	UseCount int       `json:"use_count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Report 统计结果
+type Report struct {
+	Total int `json:"total"`
+	// Types 各个类型的条目数量，按数量从多到少排序
+	Types []TypeCount `json:"types"`
+	// RecentDays 最近的时间范围（天），CreatedRecently、UpdatedRecently 和 UsedRecently 是这段时间内的条目数量
+	RecentDays      int `json:"recent_days"`
+	CreatedRecently int `json:"created_recently"`
+	UpdatedRecently int `json:"updated_recently"`
+	UsedRecently    int `json:"used_recently"`
+	// NeverUsed 没有使用记录的条目数量
+	NeverUsed int `json:"never_used"`
+	// UnknownCreated 旧版本保存的条目没有创建时间
+	UnknownCreated int `json:"unknown_created"`
+	TotalUses      int `json:"total_uses"`
+	// MostUsed 使用次数最多的条目，LeastRecentlyUsed 最久没有使用的条目，从未使用的排在最前
+	MostUsed          []Usage `json:"most_used"`
+	LeastRecentlyUsed []Usage `json:"least_recently_used"`
+}
+
+// Run 统计所有条目，只需要不解密的信息
+func Run(entries []password.PasswordData, opts Options) Report {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	since := now.Add(-opts.Recent)
+	report := Report{
+		Total:             len(entries),
+		Types:             []TypeCount{},
+		RecentDays:        int(opts.Recent.Hours() / 24),
+		MostUsed:          []Usage{},
+		LeastRecentlyUsed: []Usage{},
+	}
+	types := make(map[string]int)
+	usages := make([]Usage, 0, len(entries))
+	for _, data := range entries {
+		kind := data.Type
+		if kind == "" {
+			kind = password.TypeLogin
+		}
+		types[kind]++
+		if data.Created.IsZero() {
+			report.UnknownCreated++
+		} else if data.Created.After(since) {
+			report.CreatedRecently++
+		}
+		if data.Modified.After(since) {
+			report.UpdatedRecently++
+		}
+		if data.UseCount == 0 {
+			report.NeverUsed++
+		} else if data.LastUsed.After(since) {
+			report.UsedRecently++
+		}
+		report.TotalUses += data.UseCount
+		usages = append(usages, Usage{Key: data.Key, UseCount: data.UseCount, LastUsed: data.LastUsed})
+	}
+	for kind, count := range types {
+		report.Types = append(report.Types, TypeCount{Type: kind, Count: count})
+	}
+	sort.Slice(report.Types, func(i, j int) bool {
+		a, b := report.Types[i], report.Types[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Type < b.Type
+	})
+	if opts.Top <= 0 {
+		return report
+	}
+	//使用次数从多到少，次数相同时最近使用的在前
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.UseCount != b.UseCount {
+			return a.UseCount > b.UseCount
+		}
+		if !a.LastUsed.Equal(b.LastUsed) {
+			return a.LastUsed.After(b.LastUsed)
+		}
+		return a.Key < b.Key
+	})
+	for _, usage := range usages[:min(opts.Top, len(usages))] {
+		if usage.UseCount == 0 {
+			break
+		}
+		report.MostUsed = append(report.MostUsed, usage)
+	}
+	//最后使用的时间从早到晚，从未使用的零值排在最前
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if !a.LastUsed.Equal(b.LastUsed) {
+			return a.LastUsed.Before(b.LastUsed)
+		}
+		return a.Key < b.Key
+	})
+	report.LeastRecentlyUsed = append(report.LeastRecentlyUsed, usages[:min(opts.Top, len(usages))]...)
+	return report
+}
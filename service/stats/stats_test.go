@@ -0,0 +1,57 @@
+package stats_test
+
+import (
+	"password_manager/service/password"
+	"password_manager/service/stats"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	entries := []password.PasswordData{
+		{Key: "github", Created: day(100), Modified: day(10), LastUsed: day(1), UseCount: 12},
+		{Key: "gitlab", Created: day(5), Modified: day(5), LastUsed: day(40), UseCount: 3},
+		{Key: "bank", Type: password.TypeCard, Created: day(200), Modified: day(200), LastUsed: day(60), UseCount: 3},
+		{Key: "deploy", Type: password.TypeSSHKey, Created: day(2), Modified: day(2)},
+		{Key: "legacy"},
+	}
+	report := stats.Run(entries, stats.Options{Recent: stats.DefaultRecent, Top: 3, Now: now})
+	assert.Equal(5, report.Total)
+	assert.Equal([]stats.TypeCount{
+		{Type: password.TypeLogin, Count: 3},
+		{Type: password.TypeCard, Count: 1},
+		{Type: password.TypeSSHKey, Count: 1},
+	}, report.Types)
+	assert.Equal(30, report.RecentDays)
+	assert.Equal(2, report.CreatedRecently)
+	assert.Equal(3, report.UpdatedRecently)
+	assert.Equal(1, report.UsedRecently)
+	assert.Equal(2, report.NeverUsed)
+	assert.Equal(1, report.UnknownCreated)
+	assert.Equal(18, report.TotalUses)
+
+	//次数相同时最近使用的在前，从未使用的不算常用
+	assert.Equal([]stats.Usage{
+		{Key: "github", UseCount: 12, LastUsed: day(1)},
+		{Key: "gitlab", UseCount: 3, LastUsed: day(40)},
+		{Key: "bank", UseCount: 3, LastUsed: day(60)},
+	}, report.MostUsed)
+	assert.Equal([]stats.Usage{
+		{Key: "deploy"},
+		{Key: "legacy"},
+		{Key: "bank", UseCount: 3, LastUsed: day(60)},
+	}, report.LeastRecentlyUsed)
+
+	//Top 为 0 时不列出
+	report = stats.Run(entries, stats.Options{Now: now})
+	assert.Empty(report.MostUsed)
+	assert.Empty(report.LeastRecentlyUsed)
+	report = stats.Run(nil, stats.Options{Top: 3, Now: now})
+	assert.Equal(0, report.Total)
+	assert.Empty(report.Types)
+}
@@ -0,0 +1,67 @@
+package strength
+
+import (
+	"bufio"
+	"embed"
+	"strings"
+)
+
+//go:embed data/*.txt
+var dataFS embed.FS
+
+// 内置字典的名称，按常用程度排序，越靠前越容易被猜到
+const (
+	dictPasswords  = "passwords"
+	dictWords      = "words"
+	dictNames      = "names"
+	dictUserInputs = "user_inputs"
+)
+
+// rankedDictionaries 字典名称到 单词->排名 的映射，排名从1开始
+var rankedDictionaries = loadDictionaries(dictPasswords, dictWords, dictNames)
+
+// loadDictionaries 读取内置的字典文件，重复的单词保留第一次出现的排名
+func loadDictionaries(names ...string) map[string]map[string]int {
+	result := make(map[string]map[string]int, len(names))
+	for _, name := range names {
+		file, err := dataFS.Open("data/" + name + ".txt")
+		if err != nil {
+			panic("strength: missing dictionary " + name)
+		}
+		ranks := make(map[string]int)
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			if word == "" {
+				continue
+			}
+			if _, ok := ranks[word]; !ok {
+				ranks[word] = len(ranks) + 1
+			}
+		}
+		file.Close()
+		result[name] = ranks
+	}
+	return result
+}
+
+// userDictionary 把用户相关的输入（key、平台、用户名）作为排名最靠前的字典
+func userDictionary(inputs []string) map[string]int {
+	ranks := make(map[string]int)
+	for _, input := range inputs {
+		for _, word := range strings.FieldsFunc(strings.ToLower(input), isSeparator) {
+			if len([]rune(word)) < 3 {
+				continue
+			}
+			if _, ok := ranks[word]; !ok {
+				ranks[word] = len(ranks) + 1
+			}
+		}
+	}
+	return ranks
+}
+
+// isSeparator 拆分用户输入时使用的分隔符
+func isSeparator(r rune) bool {
+	return strings.ContainsRune(" _-.@/:,", r)
+}
@@ -0,0 +1,114 @@
+package strength
+
+import "unicode"
+
+// feedback 根据最长的模式给出警告和建议，分数足够高时不需要提示
+func feedback(score, length int, sequence []match) ([]string, []string) {
+	if length == 0 {
+		return []string{"Password is empty"}, []string{"Use a few words, avoid common phrases"}
+	}
+	if score > ScoreFair {
+		return nil, nil
+	}
+	suggestions := []string{"Add another word or two. Uncommon words are better."}
+	var warnings []string
+	if length < 8 {
+		warnings = append(warnings, "Short passwords are easy to guess")
+	}
+	longest := -1
+	for k, m := range sequence {
+		if m.pattern == patternBruteforce {
+			continue
+		}
+		if longest < 0 || len([]rune(m.token)) > len([]rune(sequence[longest].token)) {
+			longest = k
+		}
+	}
+	if longest < 0 {
+		return warnings, suggestions
+	}
+	m := sequence[longest]
+	warning, extra := matchFeedback(m, len(sequence) == 1)
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings, append(suggestions, extra...)
+}
+
+// matchFeedback 单个模式的警告和建议
+func matchFeedback(m match, soleMatch bool) (string, []string) {
+	switch m.pattern {
+	case patternDictionary:
+		return dictionaryFeedback(m, soleMatch)
+	case patternSpatial:
+		if m.turns == 1 {
+			return "Straight rows of keys are easy to guess", []string{"Use a longer keyboard pattern with more turns"}
+		}
+		return "Short keyboard patterns are easy to guess", []string{"Use a longer keyboard pattern with more turns"}
+	case patternRepeat:
+		if len([]rune(m.baseToken)) == 1 {
+			return "Repeats like \"aaa\" are easy to guess", []string{"Avoid repeated words and characters"}
+		}
+		return "Repeats like \"abcabcabc\" are only slightly harder to guess than \"abc\"", []string{"Avoid repeated words and characters"}
+	case patternSequence:
+		return "Sequences like abc or 6543 are easy to guess", []string{"Avoid sequences"}
+	case patternYear:
+		return "Recent years are easy to guess", []string{"Avoid recent years", "Avoid years that are associated with you"}
+	case patternDate:
+		return "Dates are often easy to guess", []string{"Avoid dates and years that are associated with you"}
+	}
+	return "", nil
+}
+
+// dictionaryFeedback 字典匹配的警告
+func dictionaryFeedback(m match, soleMatch bool) (string, []string) {
+	var warning string
+	switch m.dictionary {
+	case dictPasswords:
+		switch {
+		case soleMatch && !m.l33t && !m.reversed && m.rank <= 10:
+			warning = "This is a top-10 common password"
+		case soleMatch && !m.l33t && !m.reversed && m.rank <= 100:
+			warning = "This is a top-100 common password"
+		case soleMatch && !m.l33t && !m.reversed:
+			warning = "This is a very common password"
+		default:
+			warning = "This is similar to a commonly used password"
+		}
+	case dictWords:
+		if soleMatch {
+			warning = "A word by itself is easy to guess"
+		}
+	case dictNames:
+		if soleMatch {
+			warning = "Names and surnames by themselves are easy to guess"
+		} else {
+			warning = "Common names and surnames are easy to guess"
+		}
+	case dictUserInputs:
+		warning = "The password contains the key, platform or username of the entry"
+	}
+	var suggestions []string
+	runes := []rune(m.token)
+	if len(runes) > 1 && allUpper(runes) {
+		suggestions = append(suggestions, "All-uppercase is almost as easy to guess as all-lowercase")
+	} else if unicode.IsUpper(runes[0]) {
+		suggestions = append(suggestions, "Capitalization doesn't help very much")
+	}
+	if m.reversed && len(runes) >= 4 {
+		suggestions = append(suggestions, "Reversed words aren't much harder to guess")
+	}
+	if m.l33t {
+		suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+	}
+	return warning, suggestions
+}
+
+func allUpper(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,543 @@
+package strength
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 匹配到的模式
+const (
+	patternDictionary = "dictionary"
+	patternSpatial    = "spatial"
+	patternSequence   = "sequence"
+	patternRepeat     = "repeat"
+	patternDate       = "date"
+	patternYear       = "year"
+	patternBruteforce = "bruteforce"
+)
+
+// match 密码中被识别出的一段，i 和 j 是首尾字符的下标（包含）
+type match struct {
+	pattern string
+	i, j    int
+	token   string
+	guesses float64
+
+	// 字典匹配
+	dictionary string
+	rank       int
+	reversed   bool
+	l33t       bool
+	// 键盘匹配
+	turns int
+	// 重复匹配
+	repeatCount int
+	baseToken   string
+}
+
+// l33tTable 常见的字符替换，1 可能代表 i 或 l，分两次替换
+var l33tTables = []map[rune]rune{
+	{'4': 'a', '@': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+	{'4': 'a', '@': 'a', '3': 'e', '1': 'l', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '9': 'g'},
+}
+
+// keyboardRows qwerty 键盘的四行，以及按住 shift 时的字符
+var keyboardRows = []struct {
+	plain   string
+	shifted string
+	offset  float64
+}{
+	{"`1234567890-=", "~!@#$%^&*()_+", 0},
+	{"qwertyuiop[]\\", "QWERTYUIOP{}|", 0.5},
+	{"asdfghjkl;'", "ASDFGHJKL:\"", 0.75},
+	{"zxcvbnm,./", "ZXCVBNM<>?", 1.25},
+}
+
+// keyPosition 键盘上的位置
+type keyPosition struct {
+	row     int
+	x       float64
+	shifted bool
+}
+
+// keyboard 字符到键盘位置的映射
+var keyboard = buildKeyboard()
+
+// keyboardStartingPositions 和 keyboardAverageDegree 用于估算键盘模式的猜测次数
+const (
+	keyboardStartingPositions = 94
+	keyboardAverageDegree     = 4.6
+)
+
+func buildKeyboard() map[rune]keyPosition {
+	positions := make(map[rune]keyPosition)
+	for row, keys := range keyboardRows {
+		for col, r := range []rune(keys.plain) {
+			positions[r] = keyPosition{row: row, x: float64(col) + keys.offset}
+		}
+		for col, r := range []rune(keys.shifted) {
+			positions[r] = keyPosition{row: row, x: float64(col) + keys.offset, shifted: true}
+		}
+	}
+	return positions
+}
+
+// omnimatch 找出密码中所有可能的模式
+func omnimatch(password []rune, userInputs map[string]int) []match {
+	var matches []match
+	matches = append(matches, dictionaryMatches(password, userInputs)...)
+	matches = append(matches, reverseDictionaryMatches(password, userInputs)...)
+	matches = append(matches, l33tMatches(password, userInputs)...)
+	matches = append(matches, spatialMatches(password)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, repeatMatches(password, userInputs)...)
+	matches = append(matches, dateMatches(password)...)
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].i != matches[b].i {
+			return matches[a].i < matches[b].i
+		}
+		return matches[a].j < matches[b].j
+	})
+	return matches
+}
+
+// dictionaryMatches 在所有字典中查找密码的子串
+func dictionaryMatches(password []rune, userInputs map[string]int) []match {
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		//大小写转换改变了长度时按原样匹配
+		lower = password
+	}
+	dictionaries := map[string]map[string]int{dictUserInputs: userInputs}
+	for name, ranks := range rankedDictionaries {
+		dictionaries[name] = ranks
+	}
+	var matches []match
+	for i := range lower {
+		for j := i; j < len(lower) && j-i < 32; j++ {
+			word := string(lower[i : j+1])
+			for name, ranks := range dictionaries {
+				rank, ok := ranks[word]
+				if !ok {
+					continue
+				}
+				token := string(password[i : j+1])
+				matches = append(matches, match{
+					pattern:    patternDictionary,
+					i:          i,
+					j:          j,
+					token:      token,
+					dictionary: name,
+					rank:       rank,
+					guesses:    float64(rank) * uppercaseVariations(token),
+				})
+			}
+		}
+	}
+	return matches
+}
+
+// reverseDictionaryMatches 查找倒着写的单词
+func reverseDictionaryMatches(password []rune, userInputs map[string]int) []match {
+	reversed := reverseRunes(password)
+	var matches []match
+	for _, m := range dictionaryMatches(reversed, userInputs) {
+		if len([]rune(m.token)) < 3 {
+			continue
+		}
+		m.i, m.j = len(password)-1-m.j, len(password)-1-m.i
+		m.token = string(password[m.i : m.j+1])
+		m.reversed = true
+		m.guesses *= 2
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// l33tMatches 把常见的替换字符还原后再查找单词
+func l33tMatches(password []rune, userInputs map[string]int) []match {
+	var matches []match
+	for _, table := range l33tTables {
+		subbed := make([]rune, len(password))
+		changed := false
+		for i, r := range password {
+			if replacement, ok := table[r]; ok {
+				subbed[i] = replacement
+				changed = true
+			} else {
+				subbed[i] = r
+			}
+		}
+		if !changed {
+			continue
+		}
+		for _, m := range dictionaryMatches(subbed, userInputs) {
+			token := password[m.i : m.j+1]
+			substitutions := 0
+			for _, r := range token {
+				if _, ok := table[r]; ok {
+					substitutions++
+				}
+			}
+			//至少要有一个替换字符，单个字符的替换没有意义
+			if substitutions == 0 || len(token) < 3 {
+				continue
+			}
+			m.token = string(token)
+			m.l33t = true
+			m.guesses *= math.Pow(2, float64(min(substitutions, 4)))
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// uppercaseVariations 大小写变化带来的额外猜测次数，只有首字母或全部大写时很容易猜到
+func uppercaseVariations(token string) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	runes := []rune(token)
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(runes[0]) || unicode.IsUpper(runes[len(runes)-1]))) {
+		return 2
+	}
+	variations := 0.0
+	for k := 1; k <= min(upper, lower); k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return variations
+}
+
+// spatialMatches 查找键盘上相邻按键组成的模式，例如 qwerty、1qaz
+func spatialMatches(password []rune) []match {
+	var matches []match
+	i := 0
+	for i < len(password)-2 {
+		j := i
+		turns := 0
+		shifted := 0
+		lastDirection := ""
+		if keyboard[password[i]].shifted {
+			shifted++
+		}
+		for j+1 < len(password) {
+			direction, ok := keyDirection(password[j], password[j+1])
+			if !ok {
+				break
+			}
+			if direction != lastDirection {
+				turns++
+				lastDirection = direction
+			}
+			j++
+			if keyboard[password[j]].shifted {
+				shifted++
+			}
+		}
+		if j-i+1 >= 3 {
+			token := string(password[i : j+1])
+			matches = append(matches, match{
+				pattern: patternSpatial,
+				i:       i,
+				j:       j,
+				token:   token,
+				turns:   turns,
+				guesses: spatialGuesses(j-i+1, turns, shifted),
+			})
+			i = j + 1
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// keyDirection 两个按键相邻时返回方向
+func keyDirection(a, b rune) (string, bool) {
+	pa, ok := keyboard[a]
+	if !ok {
+		return "", false
+	}
+	pb, ok := keyboard[b]
+	if !ok {
+		return "", false
+	}
+	dr := pb.row - pa.row
+	dx := pb.x - pa.x
+	switch {
+	case dr == 0 && math.Abs(dx) == 1:
+		return "h" + strconv.FormatFloat(dx, 'f', 0, 64), true
+	case (dr == 1 || dr == -1) && math.Abs(dx) <= 1:
+		return "v" + strconv.Itoa(dr) + strconv.FormatFloat(dx, 'f', 2, 64), true
+	}
+	return "", false
+}
+
+// spatialGuesses 键盘模式的猜测次数，与起点数、平均相邻按键数和转折次数有关
+func spatialGuesses(length, turns, shifted int) float64 {
+	guesses := 0.0
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(turns, i-1); j++ {
+			guesses += binomial(i-1, j-1) * keyboardStartingPositions * math.Pow(keyboardAverageDegree, float64(j))
+		}
+	}
+	if shifted > 0 {
+		unshifted := length - shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			for k := 1; k <= min(shifted, unshifted); k++ {
+				variations += binomial(length, k)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
+
+// sequenceMatches 查找步长固定的序列，例如 abc、6543、aceg
+func sequenceMatches(password []rune) []match {
+	var matches []match
+	i := 0
+	for i < len(password)-2 {
+		delta := int(password[i+1]) - int(password[i])
+		if delta == 0 || delta > 5 || delta < -5 || charClass(password[i]) != charClass(password[i+1]) || charClass(password[i]) == "" {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(password) && int(password[j+1])-int(password[j]) == delta && charClass(password[j+1]) == charClass(password[i]) {
+			j++
+		}
+		if j-i+1 >= 3 {
+			token := string(password[i : j+1])
+			matches = append(matches, match{
+				pattern: patternSequence,
+				i:       i,
+				j:       j,
+				token:   token,
+				guesses: sequenceGuesses(password[i], j-i+1, delta),
+			})
+			i = j + 1
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// charClass 字符的类型，只有同类字符才能组成序列
+func charClass(r rune) string {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return "lower"
+	case r >= 'A' && r <= 'Z':
+		return "upper"
+	case r >= '0' && r <= '9':
+		return "digit"
+	}
+	return ""
+}
+
+// sequenceGuesses 从明显的起点开始的序列更容易被猜到
+func sequenceGuesses(first rune, length, delta int) float64 {
+	var base float64
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4
+	case first >= '0' && first <= '9':
+		base = 10
+	default:
+		base = 26
+	}
+	if delta < 0 {
+		base *= 2
+	}
+	return base * float64(length) * math.Abs(float64(delta))
+}
+
+// repeatMatches 查找重复的字符或片段，例如 aaaa、abcabc
+func repeatMatches(password []rune, userInputs map[string]int) []match {
+	var matches []match
+	i := 0
+	for i < len(password) {
+		bestUnit, bestCount := 0, 0
+		for unit := 1; i+unit*2 <= len(password); unit++ {
+			count := 1
+			for i+unit*(count+1) <= len(password) && string(password[i+unit*count:i+unit*(count+1)]) == string(password[i:i+unit]) {
+				count++
+			}
+			if count < 2 || (unit == 1 && count < 3) {
+				continue
+			}
+			if unit*count > bestUnit*bestCount {
+				bestUnit, bestCount = unit, count
+			}
+		}
+		if bestCount == 0 {
+			i++
+			continue
+		}
+		base := password[i : i+bestUnit]
+		baseGuesses := 10.0
+		if len(base) > 1 {
+			baseGuesses = estimateGuesses(base, userInputs)
+		}
+		j := i + bestUnit*bestCount - 1
+		matches = append(matches, match{
+			pattern:     patternRepeat,
+			i:           i,
+			j:           j,
+			token:       string(password[i : j+1]),
+			baseToken:   string(base),
+			repeatCount: bestCount,
+			guesses:     baseGuesses * float64(bestCount),
+		})
+		i = j + 1
+	}
+	return matches
+}
+
+// 日期的格式，分隔符前后必须一致
+var (
+	dateWithSeparator = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+	minYear           = 1000
+	maxYear           = 2050
+)
+
+// referenceYear 估算年份时使用的当前年份
+func referenceYear() int {
+	return time.Now().Year()
+}
+
+// dateMatches 查找日期和年份，例如 19900314、14.03.90、2024
+func dateMatches(password []rune) []match {
+	var matches []match
+	for i := range password {
+		for j := i + 3; j < len(password) && j-i < 10; j++ {
+			token := string(password[i : j+1])
+			if isDigits(token) && len(token) == 4 {
+				if year, _ := strconv.Atoi(token); year >= 1900 && year <= 2039 {
+					matches = append(matches, match{
+						pattern: patternYear,
+						i:       i,
+						j:       j,
+						token:   token,
+						guesses: yearSpace(year),
+					})
+				}
+			}
+			var year int
+			var ok bool
+			separator := false
+			if isDigits(token) {
+				if len(token) > 8 {
+					continue
+				}
+				year, ok = parseDigitsDate(token)
+			} else if parts := dateWithSeparator.FindStringSubmatch(token); parts != nil && parts[2] == parts[4] {
+				year, ok = validDate(parts[1], parts[3], parts[5])
+				separator = true
+			}
+			if !ok {
+				continue
+			}
+			guesses := yearSpace(year) * 365
+			if separator {
+				guesses *= 4
+			}
+			matches = append(matches, match{
+				pattern: patternDate,
+				i:       i,
+				j:       j,
+				token:   token,
+				guesses: guesses,
+			})
+		}
+	}
+	return matches
+}
+
+// parseDigitsDate 尝试把没有分隔符的数字拆成日期
+func parseDigitsDate(token string) (int, bool) {
+	for a := 1; a < len(token)-1; a++ {
+		for b := a + 1; b < len(token); b++ {
+			if year, ok := validDate(token[:a], token[a:b], token[b:]); ok {
+				return year, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// validDate 检查三段数字能否按 年月日、日月年 或 月日年 组成日期，返回年份
+func validDate(first, second, third string) (int, bool) {
+	for _, order := range [][3]string{{first, second, third}, {third, second, first}, {third, first, second}} {
+		year, month, day := order[0], order[1], order[2]
+		if len(month) > 2 || len(day) > 2 || (len(year) != 2 && len(year) != 4) {
+			continue
+		}
+		y, _ := strconv.Atoi(year)
+		m, _ := strconv.Atoi(month)
+		d, _ := strconv.Atoi(day)
+		if len(year) == 2 {
+			if y > 50 {
+				y += 1900
+			} else {
+				y += 2000
+			}
+		}
+		if y >= minYear && y <= maxYear && m >= 1 && m <= 12 && d >= 1 && d <= 31 {
+			return y, true
+		}
+	}
+	return 0, false
+}
+
+// yearSpace 离当前年份越近越容易被猜到
+func yearSpace(year int) float64 {
+	return math.Max(math.Abs(float64(year-referenceYear())), 20)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func reverseRunes(runes []rune) []rune {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return reversed
+}
+
+// binomial 组合数 C(n, k)
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
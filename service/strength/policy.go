@@ -0,0 +1,111 @@
+package strength
+
+import (
+	"encoding/json"
+	"errors"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"strconv"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMinScore 默认要求的最低分数，默认不限制，升级后原来能保存的密码仍然可以保存
+	DefaultMinScore = 0
+	// RecommendedMinScore 建议的最低分数，策略没有限制时审计使用这个分数
+	RecommendedMinScore = ScoreFair
+
+	policyKey = "password_policy"
+)
+
+// Policy 密码强度策略
+type Policy struct {
+	// MinScore 允许保存的最低分数，0 表示不限制
+	MinScore int `json:"min_score"`
+}
+
+// WeakPasswordError 密码不满足强度策略
+type WeakPasswordError struct {
+	// Result 密码的估算结果
+	Result Result
+	// MinScore 策略要求的最低分数
+	MinScore int
+}
+
+func (e *WeakPasswordError) Error() string {
+	return "password is too weak: score " + strconv.Itoa(e.Result.Score) + " (" + Label(e.Result.Score) + "), at least " + strconv.Itoa(e.MinScore) + " (" + Label(e.MinScore) + ") is required"
+}
+
+// Check 检查估算结果是否满足策略，不满足时返回 *WeakPasswordError
+func (p Policy) Check(result Result) error {
+	if result.Score < p.MinScore {
+		return &WeakPasswordError{Result: result, MinScore: p.MinScore}
+	}
+	return nil
+}
+
+// PolicyService 读取和保存密码强度策略，策略保存在数据库中，随备份一起恢复
+type PolicyService struct {
+	logger *zap.Logger
+	db     *bbolt.DB
+}
+
+// NewPolicyService 创建策略服务
+func NewPolicyService(db *bbolt.DB) *PolicyService {
+	return &PolicyService{
+		logger: zap.L(),
+		db:     db,
+	}
+}
+
+// GetPolicy 读取策略，没有设置时返回默认策略
+func (srv *PolicyService) GetPolicy() (Policy, error) {
+	policy := Policy{MinScore: DefaultMinScore}
+	var value []byte
+	err := srv.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(dbfilekit.SettingsBucketName))
+		if bucket == nil {
+			return nil
+		}
+		if data := bucket.Get([]byte(policyKey)); data != nil {
+			value = append([]byte(nil), data...)
+		}
+		return nil
+	})
+	if err != nil {
+		srv.logger.Error("read password policy failed:", zap.Error(err))
+		return policy, err
+	}
+	if value == nil {
+		return policy, nil
+	}
+	if err := json.Unmarshal(value, &policy); err != nil {
+		srv.logger.Error("unmarshal password policy failed:", zap.Error(err))
+		return policy, err
+	}
+	return policy, nil
+}
+
+// SetPolicy 保存策略
+func (srv *PolicyService) SetPolicy(policy Policy) error {
+	if policy.MinScore < ScoreVeryWeak || policy.MinScore > ScoreVeryStrong {
+		return errors.New("min score must be between 0 and 4")
+	}
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	err = srv.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(dbfilekit.SettingsBucketName))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(policyKey), value)
+	})
+	if err != nil {
+		srv.logger.Error("save password policy failed:", zap.Error(err))
+		return err
+	}
+	return nil
+}
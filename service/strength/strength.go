@@ -0,0 +1,228 @@
+package strength
+
+import (
+	"math"
+	"strconv"
+)
+
+const (
+	// maxAnalyzedLength 只分析密码的前面部分，超出的字符按随机字符计算
+	maxAnalyzedLength = 64
+	// bruteforceCardinality 每个随机字符带来的猜测次数
+	bruteforceCardinality = 10
+	// minGuessesBeforeGrowingSequence 每多一段模式增加的猜测次数
+	minGuessesBeforeGrowingSequence = 10000
+	// guessesPerSecond 离线破解使用慢哈希时的速度
+	guessesPerSecond = 1e4
+)
+
+// 分数的含义与 zxcvbn 相同，0 最弱，4 最强
+const (
+	ScoreVeryWeak = iota
+	ScoreWeak
+	ScoreFair
+	ScoreStrong
+	ScoreVeryStrong
+)
+
+// Result 密码强度的估算结果
+type Result struct {
+	// Score 0 到 4 的分数
+	Score int
+	// Guesses 估算的猜测次数
+	Guesses float64
+	// CrackTime 离线破解大约需要的时间
+	CrackTime string
+	// Warnings 密码中容易被猜到的部分
+	Warnings []string
+	// Suggestions 改进的建议
+	Suggestions []string
+	// sequence 估算时使用的模式
+	sequence []match
+}
+
+// Estimate 估算密码的强度，userInputs 是 key、平台、用户名等与密码相关的内容，密码中包含它们时更容易被猜到
+func Estimate(password string, userInputs ...string) Result {
+	runes := []rune(password)
+	extra := 0
+	if len(runes) > maxAnalyzedLength {
+		extra = len(runes) - maxAnalyzedLength
+		runes = runes[:maxAnalyzedLength]
+	}
+	guesses, sequence := mostGuessableSequence(runes, userDictionary(userInputs))
+	guesses *= math.Pow(bruteforceCardinality, float64(extra))
+	result := Result{
+		Score:     scoreFromGuesses(guesses),
+		Guesses:   guesses,
+		CrackTime: displayTime(guesses / guessesPerSecond),
+		sequence:  sequence,
+	}
+	result.Warnings, result.Suggestions = feedback(result.Score, len([]rune(password)), sequence)
+	return result
+}
+
+// Label 分数的文字描述
+func Label(score int) string {
+	switch score {
+	case ScoreVeryWeak:
+		return "very weak"
+	case ScoreWeak:
+		return "weak"
+	case ScoreFair:
+		return "fair"
+	case ScoreStrong:
+		return "strong"
+	default:
+		return "very strong"
+	}
+}
+
+// estimateGuesses 只计算猜测次数，重复模式用它估算被重复的部分
+func estimateGuesses(password []rune, userInputs map[string]int) float64 {
+	guesses, _ := mostGuessableSequence(password, userInputs)
+	return guesses
+}
+
+// mostGuessableSequence 找出猜测次数最少的模式组合
+// 与 zxcvbn 一样，k 段模式的总猜测次数为 k! * 各段乘积 + 10000^(k-1)，没有被模式覆盖的字符按随机字符计算
+func mostGuessableSequence(password []rune, userInputs map[string]int) (float64, []match) {
+	n := len(password)
+	if n == 0 {
+		return 1, nil
+	}
+	matches := omnimatch(password, userInputs)
+	byEnd := make([][]match, n)
+	for _, m := range matches {
+		m.guesses = math.Max(m.guesses, minSubmatchGuesses(m.j-m.i+1))
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+	// best[k][j] 前 j 个字符使用 k 段模式时的最小乘积
+	best := make([][]float64, n+1)
+	last := make([][]*match, n+1)
+	for k := range best {
+		best[k] = make([]float64, n+1)
+		last[k] = make([]*match, n+1)
+		for j := range best[k] {
+			best[k][j] = math.Inf(1)
+		}
+	}
+	best[0][0] = 1
+	for j := 1; j <= n; j++ {
+		candidates := byEnd[j-1]
+		for i := 0; i < j; i++ {
+			candidates = append(candidates, bruteforceMatch(password, i, j-1))
+		}
+		for c := range candidates {
+			m := &candidates[c]
+			for k := 0; k < n; k++ {
+				if math.IsInf(best[k][m.i], 1) {
+					continue
+				}
+				if guesses := best[k][m.i] * m.guesses; guesses < best[k+1][j] {
+					best[k+1][j] = guesses
+					last[k+1][j] = m
+				}
+			}
+		}
+	}
+	bestGuesses := math.Inf(1)
+	bestK := 0
+	for k := 1; k <= n; k++ {
+		if math.IsInf(best[k][n], 1) {
+			continue
+		}
+		guesses := factorial(k)*best[k][n] + math.Pow(minGuessesBeforeGrowingSequence, float64(k-1))
+		if guesses < bestGuesses {
+			bestGuesses, bestK = guesses, k
+		}
+	}
+	sequence := make([]match, bestK)
+	for k, j := bestK, n; k > 0; k-- {
+		m := last[k][j]
+		sequence[k-1] = *m
+		j = m.i
+	}
+	return bestGuesses, sequence
+}
+
+// bruteforceMatch 没有识别出模式的字符
+func bruteforceMatch(password []rune, i, j int) match {
+	length := j - i + 1
+	guesses := math.Pow(bruteforceCardinality, float64(length))
+	if length == 1 {
+		guesses = math.Max(guesses, 11)
+	} else {
+		guesses = math.Max(guesses, 51)
+	}
+	return match{pattern: patternBruteforce, i: i, j: j, token: string(password[i : j+1]), guesses: guesses}
+}
+
+// minSubmatchGuesses 单个模式的最小猜测次数，避免过短的模式拉低分数
+func minSubmatchGuesses(length int) float64 {
+	if length == 1 {
+		return 10
+	}
+	return 50
+}
+
+// scoreFromGuesses 根据猜测次数计算分数
+func scoreFromGuesses(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return ScoreVeryWeak
+	case guesses < 1e6+delta:
+		return ScoreWeak
+	case guesses < 1e8+delta:
+		return ScoreFair
+	case guesses < 1e10+delta:
+		return ScoreStrong
+	default:
+		return ScoreVeryStrong
+	}
+}
+
+// displayTime 把秒数转换为易读的时间
+func displayTime(seconds float64) string {
+	const (
+		minute = 60.0
+		hour   = minute * 60
+		day    = hour * 24
+		month  = day * 31
+		year   = month * 12
+		cent   = year * 100
+	)
+	unit := func(value float64, name string) string {
+		n := int64(math.Round(value))
+		if n != 1 {
+			name += "s"
+		}
+		return strconv.FormatInt(n, 10) + " " + name
+	}
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds < minute:
+		return unit(seconds, "second")
+	case seconds < hour:
+		return unit(seconds/minute, "minute")
+	case seconds < day:
+		return unit(seconds/hour, "hour")
+	case seconds < month:
+		return unit(seconds/day, "day")
+	case seconds < year:
+		return unit(seconds/month, "month")
+	case seconds < cent:
+		return unit(seconds/year, "year")
+	default:
+		return "centuries"
+	}
+}
+
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package strength_test
+
+import (
+	"password_manager/service/strength"
+	"password_manager/service/testvault"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		password string
+		maxScore int
+		minScore int
+		warning  string
+	}{
+		{"password", 0, 0, "top-10 common password"},
+		{"P@ssw0rd", 0, 0, "similar to a commonly used password"},
+		{"drowssap", 0, 0, "similar to a commonly used password"},
+		{"qwertyuiop", 0, 0, "common password"},
+		{"zxcvbn", 0, 0, "common password"},
+		{"poiuytre", 1, 0, "keys"},
+		{"abcdefgh", 0, 0, "Sequences"},
+		{"97531", 0, 0, "Sequences"},
+		{"aaaaaaaa", 0, 0, "Repeats"},
+		{"xyzxyzxyz", 1, 0, "Repeats"},
+		{"14.03.1990", 1, 0, "Dates"},
+		{"19900314", 1, 0, "Dates"},
+		{"sunshine", 0, 0, "common password"},
+		{"jessica", 0, 0, "Short passwords"},
+		{"kX9#mQ2$vLp", 4, 3, ""},
+		{"correct horse battery staple", 4, 4, ""},
+	}
+	for _, tc := range testCases {
+		result := strength.Estimate(tc.password)
+		assert.LessOrEqual(result.Score, tc.maxScore, tc.password)
+		assert.GreaterOrEqual(result.Score, tc.minScore, tc.password)
+		if tc.warning == "" {
+			assert.Empty(result.Warnings, tc.password)
+		} else {
+			assert.Contains(strings.Join(result.Warnings, "\n"), tc.warning, tc.password)
+		}
+	}
+
+	//包含 key 或平台的密码更容易被猜到
+	withInputs := strength.Estimate("octopusdeploy7", "octopus_deploy", "octopus")
+	without := strength.Estimate("octopusdeploy7")
+	assert.Less(withInputs.Guesses, without.Guesses)
+	assert.Contains(strings.Join(strength.Estimate("octopus1", "octopus").Warnings, "\n"), "key, platform or username")
+
+	//大小写和替换只增加少量猜测次数
+	assert.Less(strength.Estimate("Password").Guesses, strength.Estimate("xkqbtmzw").Guesses)
+	assert.NotEmpty(strength.Estimate("").Warnings)
+	assert.Equal("less than a second", strength.Estimate("123456").CrackTime)
+	assert.Equal("centuries", strength.Estimate("kX9#mQ2$vLp-Rw7!tZ4@bN").CrackTime)
+	assert.Equal(strength.ScoreVeryStrong, strength.Estimate(strings.Repeat("q7", 20)+"Zx!9"+strings.Repeat("m", 50)).Score)
+}
+
+func TestPolicy(t *testing.T) {
+	assert := assert.New(t)
+	srv := strength.NewPolicyService(testvault.New(t).DB)
+
+	policy, err := srv.GetPolicy()
+	assert.NoError(err)
+	// 默认不限制
+	assert.Equal(strength.DefaultMinScore, policy.MinScore)
+	assert.NoError(policy.Check(strength.Estimate("password")))
+
+	assert.NoError(srv.SetPolicy(strength.Policy{MinScore: strength.ScoreFair}))
+	policy, err = srv.GetPolicy()
+	assert.NoError(err)
+	assert.Error(policy.Check(strength.Estimate("password")))
+	assert.NoError(policy.Check(strength.Estimate("kX9#mQ2$vLp")))
+
+	assert.Error(srv.SetPolicy(strength.Policy{MinScore: 5}))
+	assert.Error(srv.SetPolicy(strength.Policy{MinScore: -1}))
+	assert.NoError(srv.SetPolicy(strength.Policy{MinScore: 0}))
+	policy, err = srv.GetPolicy()
+	assert.NoError(err)
+	assert.Equal(0, policy.MinScore)
+	assert.NoError(policy.Check(strength.Estimate("password")))
+}
@@ -0,0 +1,67 @@
+// Package testvault 给各个服务的测试创建临时的密码库
+package testvault
+
+import (
+	"password_manager/service/aes"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/password"
+	secretkey "password_manager/service/secret_key"
+	"path/filepath"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+// KeyFileName 密码库目录中密钥文件的名字
+const KeyFileName = "test.gob"
+
+// Vault 一个目录中的数据库、密钥和密码服务
+type Vault struct {
+	Dir       string
+	Kit       *dbfilekit.DBKitImpl
+	DB        *bbolt.DB
+	SecretKey string
+	Srv       *password.PasswordService
+}
+
+// New 在 t.TempDir() 中创建密码库，测试结束时关闭，目录由测试框架删除
+func New(t testing.TB) *Vault {
+	t.Helper()
+	vault, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(vault.Close)
+	return vault
+}
+
+// Open 打开 dir 中的密码库，不存在时创建，用于没有 testing.TB 的子进程
+func Open(dir string) (*Vault, error) {
+	secretKeyInstance := secretkey.NewSecretKeyWithFilePath(filepath.Join(dir, KeyFileName))
+	kit := dbfilekit.NewDBKitWithFilePath(dir, secretKeyInstance)
+	if err := kit.Init(); err != nil {
+		return nil, err
+	}
+	db, err := kit.GetDB()
+	if err != nil {
+		kit.Close()
+		return nil, err
+	}
+	secretKey, err := secretKeyInstance.GetSecretKey()
+	if err != nil {
+		kit.Close()
+		return nil, err
+	}
+	return &Vault{
+		Dir:       dir,
+		Kit:       kit,
+		DB:        db,
+		SecretKey: secretKey,
+		Srv:       password.NewPasswordService(aes.NewAesService(secretKey), db),
+	}, nil
+}
+
+// Close 关闭数据库
+func (v *Vault) Close() {
+	v.Kit.Close()
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"strings"
+)
+
+// 表单字段的下标
+const (
+	fieldKey = iota
+	fieldPassword
+	fieldPlatform
+	fieldUsername
+	fieldURL
+	fieldTags
+)
+
+// formField 表单中的一个输入框
+type formField struct {
+	label  string
+	value  []rune
+	secret bool
+}
+
+// form 新增和编辑条目使用的表单
+type form struct {
+	title string
+	// originKey 编辑时的原始key，新增时为空
+	originKey string
+	fields    []formField
+	focus     int
+}
+
+// newForm 创建表单，data 为空时表示新增
+func newForm(title, originKey string, values [6]string) *form {
+	labels := [6]string{"Key", "Password", "Platform", "Username", "URL", "Tags"}
+	f := &form{title: title, originKey: originKey}
+	for i, label := range labels {
+		f.fields = append(f.fields, formField{
+			label:  label,
+			value:  []rune(values[i]),
+			secret: i == fieldPassword,
+		})
+	}
+	return f
+}
+
+// value 返回字段的值
+func (f *form) value(index int) string {
+	return strings.TrimSpace(string(f.fields[index].value))
+}
+
+// tags 将 Tags 字段按逗号拆分
+func (f *form) tags() []string {
+	var tags []string
+	for _, tag := range strings.Split(f.value(fieldTags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// next 切换到下一个输入框
+func (f *form) next() {
+	f.focus = (f.focus + 1) % len(f.fields)
+}
+
+// prev 切换到上一个输入框
+func (f *form) prev() {
+	f.focus = (f.focus + len(f.fields) - 1) % len(f.fields)
+}
+
+// insert 在当前输入框末尾追加字符
+func (f *form) insert(r rune) {
+	f.fields[f.focus].value = append(f.fields[f.focus].value, r)
+}
+
+// backspace 删除当前输入框的最后一个字符
+func (f *form) backspace() {
+	value := f.fields[f.focus].value
+	if len(value) > 0 {
+		f.fields[f.focus].value = value[:len(value)-1]
+	}
+}
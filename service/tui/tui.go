@@ -0,0 +1,529 @@
+package tui
+
+import (
+	"errors"
+	"password_manager/service/password"
+	"password_manager/service/search"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultLockAfter 默认的空闲锁定时间
+const DefaultLockAfter = 5 * time.Minute
+
+// tickInterval 检查空闲时间的间隔
+const tickInterval = time.Second
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeFilter
+	modeForm
+	modeConfirmDelete
+	modeLocked
+)
+
+// Options 界面的可选配置
+type Options struct {
+	// LockAfter 空闲多久后自动锁定，0 表示使用默认值
+	LockAfter time.Duration
+	// Clipboard 复制文本使用的函数
+	Clipboard func(text string) error
+	// Now 获取当前时间，测试时可以替换
+	Now func() time.Time
+	// Verify 检查解锁时输入的主密码。为 nil 时锁定只是隐藏界面，按 Enter 即可恢复
+	Verify func(password string) (bool, error)
+}
+
+// App 全屏浏览和编辑密码的终端界面
+type App struct {
+	screen tcell.Screen
+	srv    *password.PasswordService
+	opts   Options
+	logger *zap.Logger
+
+	// entries 和 visible 只有平台和附加信息，密码在显示或复制时才解密
+	entries  []password.PasswordData
+	visible  []password.PasswordData
+	filter   []rune
+	selected int
+	offset   int
+	revealed bool
+	mode     mode
+	form     *form
+	status   string
+	// secret 显示中的当前条目的密码，隐藏时清除
+	secret string
+	// lockInput 锁定画面中输入的主密码
+	lockInput []rune
+
+	lastActivity time.Time
+	quit         bool
+}
+
+// NewApp 创建终端界面，screen 需要尚未初始化
+func NewApp(screen tcell.Screen, srv *password.PasswordService, opts Options) *App {
+	if opts.LockAfter <= 0 {
+		opts.LockAfter = DefaultLockAfter
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Clipboard == nil {
+		opts.Clipboard = func(string) error {
+			return errors.New("clipboard is not available")
+		}
+	}
+	return &App{
+		screen: screen,
+		srv:    srv,
+		opts:   opts,
+		logger: zap.L(),
+	}
+}
+
+// Run 初始化屏幕并进入事件循环，直到用户退出
+func (app *App) Run() error {
+	if err := app.screen.Init(); err != nil {
+		app.logger.Error("init screen failed:", zap.Error(err))
+		return err
+	}
+	defer app.screen.Fini()
+	if err := app.reload(); err != nil {
+		return err
+	}
+	app.lastActivity = app.opts.Now()
+
+	// 定时唤醒事件循环，检查是否需要锁定
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				app.screen.PostEvent(tcell.NewEventInterrupt(nil))
+			}
+		}
+	}()
+
+	app.draw()
+	for !app.quit {
+		event := app.screen.PollEvent()
+		if event == nil {
+			return nil
+		}
+		app.handleEvent(event)
+		if !app.quit {
+			app.draw()
+		}
+	}
+	return nil
+}
+
+// handleEvent 处理单个事件
+func (app *App) handleEvent(event tcell.Event) {
+	switch ev := event.(type) {
+	case *tcell.EventResize:
+		app.screen.Sync()
+	case *tcell.EventInterrupt:
+		app.checkIdle()
+	case *tcell.EventKey:
+		app.lastActivity = app.opts.Now()
+		switch app.mode {
+		case modeList:
+			app.handleListKey(ev)
+		case modeFilter:
+			app.handleFilterKey(ev)
+		case modeForm:
+			app.handleFormKey(ev)
+		case modeConfirmDelete:
+			app.handleConfirmKey(ev)
+		case modeLocked:
+			app.handleLockedKey(ev)
+		}
+	}
+}
+
+// checkIdle 空闲超时后锁定
+func (app *App) checkIdle() {
+	if app.mode == modeLocked {
+		return
+	}
+	if app.opts.Now().Sub(app.lastActivity) >= app.opts.LockAfter {
+		app.lock()
+	}
+}
+
+// lock 清除内存中已解密的数据并进入锁定状态
+func (app *App) lock() {
+	app.entries = nil
+	app.visible = nil
+	app.form = nil
+	app.hide()
+	app.mode = modeLocked
+	app.status = ""
+	app.lockInput = nil
+}
+
+// unlock 重新读取数据，退出锁定状态
+func (app *App) unlock() {
+	if err := app.reload(); err != nil {
+		app.status = err.Error()
+		return
+	}
+	app.mode = modeList
+	app.status = "unlocked"
+}
+
+// reload 从数据库重新读取所有条目，按 key 排序，不解密密码
+func (app *App) reload() error {
+	entries, err := app.srv.GetAllEntries()
+	if err != nil {
+		app.logger.Error("load entries failed:", zap.Error(err))
+		return err
+	}
+	app.entries = entries
+	app.applyFilter()
+	return nil
+}
+
+// reveal 解密并显示当前条目的密码
+func (app *App) reveal() {
+	data, ok := app.current()
+	if !ok {
+		return
+	}
+	secret, _, err := app.srv.GetPasswordTracked(data.Key)
+	if err != nil {
+		app.status = err.Error()
+		return
+	}
+	app.secret = secret
+	app.revealed = true
+}
+
+// hide 隐藏并清除已解密的密码
+func (app *App) hide() {
+	app.revealed = false
+	app.secret = ""
+}
+
+// applyFilter 根据过滤条件计算可见的条目，列表变化后重新隐藏密码
+func (app *App) applyFilter() {
+	app.hide()
+	if len(app.filter) == 0 {
+		app.visible = append(app.visible[:0], app.entries...)
+	} else {
+		index := make(map[string]password.PasswordData, len(app.entries))
+		entries := make([]search.Entry, 0, len(app.entries))
+		for _, v := range app.entries {
+			index[v.Key] = v
+			entries = append(entries, search.Entry{
+				Key:      v.Key,
+				Platform: v.Platform,
+				Username: v.Username,
+				URL:      v.URL,
+				Tags:     v.Tags,
+			})
+		}
+		app.visible = app.visible[:0]
+		for _, result := range search.Search(string(app.filter), entries) {
+			app.visible = append(app.visible, index[result.Entry.Key])
+		}
+	}
+	if app.selected >= len(app.visible) {
+		app.selected = len(app.visible) - 1
+	}
+	if app.selected < 0 {
+		app.selected = 0
+	}
+}
+
+// current 返回当前选中的条目
+func (app *App) current() (password.PasswordData, bool) {
+	if app.selected < 0 || app.selected >= len(app.visible) {
+		return password.PasswordData{}, false
+	}
+	return app.visible[app.selected], true
+}
+
+// move 移动选中的条目
+func (app *App) move(delta int) {
+	app.selected += delta
+	if app.selected >= len(app.visible) {
+		app.selected = len(app.visible) - 1
+	}
+	if app.selected < 0 {
+		app.selected = 0
+	}
+	app.hide()
+}
+
+// handleListKey 列表模式下的按键
+func (app *App) handleListKey(ev *tcell.EventKey) {
+	app.status = ""
+	switch ev.Key() {
+	case tcell.KeyUp:
+		app.move(-1)
+		return
+	case tcell.KeyDown:
+		app.move(1)
+		return
+	case tcell.KeyPgUp:
+		app.move(-10)
+		return
+	case tcell.KeyPgDn:
+		app.move(10)
+		return
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		app.quit = true
+		return
+	case tcell.KeyRune:
+	default:
+		return
+	}
+	switch ev.Rune() {
+	case 'q':
+		app.quit = true
+	case 'k':
+		app.move(-1)
+	case 'j':
+		app.move(1)
+	case '/':
+		app.mode = modeFilter
+	case 'r':
+		if app.revealed {
+			app.hide()
+		} else {
+			app.reveal()
+		}
+	case 'c':
+		if data, ok := app.current(); ok {
+			secret, _, err := app.srv.GetPasswordTracked(data.Key)
+			if err != nil {
+				app.status = err.Error()
+				return
+			}
+			if err := app.opts.Clipboard(secret); err != nil {
+				app.status = "copy failed: " + err.Error()
+			} else {
+				app.status = "password of " + data.Key + " copied"
+			}
+		}
+	case 'u':
+		if data, ok := app.current(); ok && data.Username != "" {
+			if err := app.opts.Clipboard(data.Username); err != nil {
+				app.status = "copy failed: " + err.Error()
+			} else {
+				app.status = "username of " + data.Key + " copied"
+			}
+		}
+	case 'a':
+		app.form = newForm("Add entry", "", [6]string{})
+		app.mode = modeForm
+	case 'e':
+		if data, ok := app.current(); ok {
+			app.form = newForm("Edit "+data.Key, data.Key, [6]string{
+				data.Key, "", data.Platform, data.Username, data.URL, joinTags(data.Tags),
+			})
+			app.mode = modeForm
+		}
+	case 'd':
+		if _, ok := app.current(); ok {
+			app.mode = modeConfirmDelete
+		}
+	case 'L':
+		app.lock()
+	}
+}
+
+// handleFilterKey 输入过滤条件时的按键
+func (app *App) handleFilterKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		app.mode = modeList
+	case tcell.KeyEscape:
+		app.filter = nil
+		app.mode = modeList
+		app.applyFilter()
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(app.filter) > 0 {
+			app.filter = app.filter[:len(app.filter)-1]
+			app.applyFilter()
+		}
+	case tcell.KeyUp:
+		app.move(-1)
+	case tcell.KeyDown:
+		app.move(1)
+	case tcell.KeyRune:
+		app.filter = append(app.filter, ev.Rune())
+		app.selected = 0
+		app.applyFilter()
+	}
+}
+
+// handleFormKey 表单中的按键
+func (app *App) handleFormKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		app.form = nil
+		app.mode = modeList
+		app.status = "cancelled"
+	case tcell.KeyTab, tcell.KeyDown:
+		app.form.next()
+	case tcell.KeyBacktab, tcell.KeyUp:
+		app.form.prev()
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		app.form.backspace()
+	case tcell.KeyEnter:
+		app.submitForm()
+	case tcell.KeyRune:
+		app.form.insert(ev.Rune())
+	}
+}
+
+// submitForm 保存表单，新增和修改都只写入一次
+func (app *App) submitForm() {
+	f := app.form
+	key := f.value(fieldKey)
+	if key == "" {
+		app.status = "key cannot be empty"
+		return
+	}
+	username := f.value(fieldUsername)
+	url := f.value(fieldURL)
+	tags := f.tags()
+	if f.originKey == "" {
+		if f.value(fieldPassword) == "" {
+			app.status = "password cannot be empty"
+			return
+		}
+		if err := app.checkStrength(f, key); err != nil {
+			app.status = err.Error()
+			return
+		}
+		meta := password.Meta{Username: username, URL: url, Tags: tags}
+		if err := app.srv.SaveEntry(key, f.value(fieldPassword), f.value(fieldPlatform), meta); err != nil {
+			app.status = err.Error()
+			return
+		}
+	} else {
+		kindName, err := app.srv.GetKind(f.originKey)
+		if err != nil {
+			app.status = err.Error()
+			return
+		}
+		kind, err := password.LookupKind(kindName)
+		if err != nil {
+			app.status = err.Error()
+			return
+		}
+		//只有登录和数据库这类主字段是密码的条目需要满足强度策略
+		if kind.Primary == "password" && f.value(fieldPassword) != "" {
+			if err := app.checkStrength(f, key); err != nil {
+				app.status = err.Error()
+				return
+			}
+		}
+		//表单只修改用户名、URL 和标签，类型、文件夹和到期时间等其他附加信息保持不变
+		//带类型的条目通过类型检查修改，表单中的密码是该类型的主字段
+		update := password.EntryUpdate{Password: f.value(fieldPassword), Username: &username, URL: &url, Tags: &tags}
+		if key != f.originKey {
+			update.Key = key
+		}
+		if old, _ := app.current(); f.value(fieldPlatform) != old.Platform {
+			update.Platform = f.value(fieldPlatform)
+		}
+		if _, err := app.srv.UpdateEntry(f.originKey, update); err != nil {
+			app.status = err.Error()
+			return
+		}
+	}
+	app.form = nil
+	app.mode = modeList
+	if err := app.reload(); err != nil {
+		app.status = err.Error()
+		return
+	}
+	app.selectKey(key)
+	app.status = key + " saved"
+}
+
+// checkStrength 检查表单中的新密码是否满足强度策略
+func (app *App) checkStrength(f *form, key string) error {
+	_, err := app.srv.CheckStrength(f.value(fieldPassword), key, f.value(fieldPlatform), f.value(fieldUsername))
+	return err
+}
+
+// handleConfirmKey 删除确认时的按键
+func (app *App) handleConfirmKey(ev *tcell.EventKey) {
+	app.mode = modeList
+	if ev.Key() != tcell.KeyRune || ev.Rune() != 'y' {
+		app.status = "delete cancelled"
+		return
+	}
+	data, ok := app.current()
+	if !ok {
+		return
+	}
+	if err := app.srv.DeletePassword(data.Key); err != nil {
+		app.status = err.Error()
+		return
+	}
+	if err := app.reload(); err != nil {
+		app.status = err.Error()
+		return
+	}
+	app.status = data.Key + " deleted"
+}
+
+// handleLockedKey 锁定状态下的按键，设置了 Verify 时需要输入主密码才能解锁
+func (app *App) handleLockedKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		if app.opts.Verify != nil {
+			ok, err := app.opts.Verify(string(app.lockInput))
+			app.lockInput = nil
+			if err != nil {
+				app.status = err.Error()
+				return
+			}
+			if !ok {
+				app.status = "wrong master password"
+				return
+			}
+		}
+		app.unlock()
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		app.quit = true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(app.lockInput) > 0 {
+			app.lockInput = app.lockInput[:len(app.lockInput)-1]
+		}
+	case tcell.KeyRune:
+		if app.opts.Verify != nil {
+			app.lockInput = append(app.lockInput, ev.Rune())
+			return
+		}
+		if ev.Rune() == 'q' {
+			app.quit = true
+		}
+	}
+}
+
+// selectKey 选中指定key的条目
+func (app *App) selectKey(key string) {
+	for i, v := range app.visible {
+		if v.Key == key {
+			app.selected = i
+			return
+		}
+	}
+}
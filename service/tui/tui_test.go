@@ -0,0 +1,398 @@
+package tui_test
+
+import (
+	zaplog "password_manager/common/log"
+	"password_manager/service/password"
+	"password_manager/service/testvault"
+	"password_manager/service/tui"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock 可以手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// testScreen 在界面所在的协程里保存每次绘制后的屏幕内容，避免测试协程直接读取模拟屏幕
+type testScreen struct {
+	tcell.SimulationScreen
+	mu       sync.Mutex
+	text     string
+	width    int
+	resizeTo [2]int
+}
+
+// Show 绘制后保存屏幕文本
+func (s *testScreen) Show() {
+	s.SimulationScreen.Show()
+	text := screenText(s.SimulationScreen)
+	width, _ := s.SimulationScreen.Size()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.text = text
+	s.width = width
+}
+
+// Sync 收到窗口大小变化事件时调整模拟屏幕的大小
+func (s *testScreen) Sync() {
+	s.mu.Lock()
+	size := s.resizeTo
+	s.mu.Unlock()
+	if size[0] > 0 {
+		s.SimulationScreen.SetSize(size[0], size[1])
+	}
+	s.SimulationScreen.Sync()
+}
+
+// resize 模拟终端窗口大小变化
+func (s *testScreen) resize(width, height int) {
+	s.mu.Lock()
+	s.resizeTo = [2]int{width, height}
+	s.mu.Unlock()
+	s.PostEvent(tcell.NewEventResize(width, height))
+}
+
+// snapshot 返回最近一次绘制的屏幕文本和宽度
+func (s *testScreen) snapshot() (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text, s.width
+}
+
+// screenText 返回模拟屏幕上的全部文本
+func screenText(screen tcell.SimulationScreen) string {
+	cells, width, _ := screen.GetContents()
+	var builder strings.Builder
+	skip := false
+	for i, cell := range cells {
+		if i > 0 && i%width == 0 {
+			builder.WriteRune('\n')
+			skip = false
+		}
+		if skip {
+			// 宽字符占用的第二个单元格
+			skip = false
+			continue
+		}
+		if len(cell.Runes) > 0 {
+			builder.WriteRune(cell.Runes[0])
+			skip = runewidth.RuneWidth(cell.Runes[0]) > 1
+		}
+	}
+	return builder.String()
+}
+
+// waitFor 等待屏幕满足条件
+func waitFor(t *testing.T, screen *testScreen, check func(text string) bool) bool {
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if text, _ := screen.snapshot(); check(text) {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	text, _ := screen.snapshot()
+	t.Log(text)
+	return false
+}
+
+// typeText 逐个输入字符
+func typeText(screen *testScreen, text string) {
+	for _, r := range text {
+		screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+}
+
+func TestApp(t *testing.T) {
+	assert := assert.New(t)
+	passwordInstance := testvault.New(t).Srv
+	if err := passwordInstance.SavePassword("github", "github-secret", "GitHub"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := passwordInstance.SavePassword("wechat", "wechat-secret", "微信"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+
+	clock := &fakeClock{now: time.Now()}
+	var copiedMu sync.Mutex
+	var copied []string
+	screen := &testScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	app := tui.NewApp(screen, passwordInstance, tui.Options{
+		LockAfter: time.Minute,
+		Now:       clock.Now,
+		Clipboard: func(text string) error {
+			copiedMu.Lock()
+			defer copiedMu.Unlock()
+			copied = append(copied, text)
+			return nil
+		},
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run()
+	}()
+
+	// 列表显示所有条目，密码默认隐藏
+	if !assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "2/2 entries") && strings.Contains(text, "wechat (微信)")
+	})) {
+		return
+	}
+	text, _ := screen.snapshot()
+	assert.NotContains(text, "github-secret")
+
+	// 拼音过滤
+	typeText(screen, "/wx")
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "1/2 entries") && !strings.Contains(text, "github (GitHub)")
+	}))
+
+	// 显示和复制密码
+	typeText(screen, "r")
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "wechat-secret")
+	}))
+	typeText(screen, "c")
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "password of wechat copied")
+	}))
+	copiedMu.Lock()
+	assert.Equal([]string{"wechat-secret"}, copied)
+	copiedMu.Unlock()
+	// 列表不解密密码，只有显示和复制时记录使用
+	access, err := passwordInstance.GetAccess("wechat")
+	assert.NoError(err)
+	assert.Equal(2, access.Count)
+	access, err = passwordInstance.GetAccess("github")
+	assert.NoError(err)
+	assert.Equal(0, access.Count)
+
+	// 清除过滤条件后新增条目
+	typeText(screen, "/")
+	screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	typeText(screen, "a")
+	typeText(screen, "mail")
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	typeText(screen, "mail-secret")
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	typeText(screen, "Google")
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	typeText(screen, "john")
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "mail saved") && strings.Contains(text, "3/3 entries")
+	}))
+
+	// 编辑条目，表单之外的附加信息保持不变
+	assert.NoError(passwordInstance.MoveToFolder("mail", "personal/mail"))
+	assert.NoError(passwordInstance.SetExpiry("mail", "2030-01-01", 90))
+	typeText(screen, "e")
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "Edit mail")
+	}))
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	typeText(screen, "mail-new-secret")
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "mail saved")
+	}))
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if value, _, _ := passwordInstance.GetPasswordWithKey("mail"); value == "mail-new-secret" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	value, platform, err := passwordInstance.GetPasswordWithKey("mail")
+	assert.NoError(err)
+	assert.Equal("mail-new-secret", value)
+	assert.Equal("Google", platform)
+	meta, err := passwordInstance.GetMeta("mail")
+	assert.NoError(err)
+	assert.Equal("john", meta.Username)
+	assert.Equal("personal/mail", meta.Folder)
+	//编辑后仍然出现在 pm due 中
+	assert.Equal("2030-01-01", meta.ExpiresAt)
+	assert.Equal(90, meta.RotationDays)
+
+	// 删除条目
+	typeText(screen, "d")
+	typeText(screen, "y")
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "mail deleted") && strings.Contains(text, "2/2 entries")
+	}))
+
+	// 没有主密码时空闲超时后隐藏界面，密码不再显示
+	typeText(screen, "r")
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "wechat-secret")
+	}))
+	clock.Add(2 * time.Minute)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "Hidden") && !strings.Contains(text, "secret")
+	}))
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "2/2 entries")
+	}))
+
+	// 窗口大小变化后重新布局
+	screen.resize(50, 20)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		_, width := screen.snapshot()
+		return width == 50 && strings.Contains(text, "2/2 entries")
+	}))
+
+	typeText(screen, "q")
+	select {
+	case err := <-done:
+		assert.NoError(err)
+	case <-time.After(3 * time.Second):
+		t.Error("app did not quit")
+	}
+
+	// 删除已经写入数据库
+	_, _, err = passwordInstance.GetPasswordWithKey("mail")
+	assert.Error(err)
+}
+
+func TestEditTypedEntry(t *testing.T) {
+	assert := assert.New(t)
+	passwordInstance := testvault.New(t).Srv
+	if err := passwordInstance.SaveRecord("deploy", "ci", password.Record{
+		Type:   password.TypeAPIKey,
+		Fields: map[string]string{"key": "ak-old", "client_id": "deployer"},
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+
+	screen := &testScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	app := tui.NewApp(screen, passwordInstance, tui.Options{})
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run()
+	}()
+	if !assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "1/1 entries")
+	})) {
+		return
+	}
+
+	// 不符合类型要求的 URL 不保存
+	typeText(screen, "e")
+	for i := 0; i < 4; i++ {
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	}
+	typeText(screen, "not-a-url")
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "URL:")
+	}))
+	screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+
+	// 修改主字段和 URL，其他字段保持不变
+	typeText(screen, "e")
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	typeText(screen, "ak-new")
+	for i := 0; i < 3; i++ {
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	}
+	typeText(screen, "https://ci.example.com")
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "deploy saved")
+	}))
+	typeText(screen, "q")
+	select {
+	case err := <-done:
+		assert.NoError(err)
+	case <-time.After(3 * time.Second):
+		t.Error("app did not quit")
+	}
+
+	record, err := passwordInstance.GetRecord("deploy")
+	assert.NoError(err)
+	assert.Equal(password.Record{
+		Type:   password.TypeAPIKey,
+		Fields: map[string]string{"key": "ak-new", "client_id": "deployer", "url": "https://ci.example.com"},
+	}, record)
+}
+
+func TestLockWithMasterPassword(t *testing.T) {
+	assert := assert.New(t)
+	passwordInstance := testvault.New(t).Srv
+	if err := passwordInstance.SavePassword("github", "github-secret", "GitHub"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	screen := &testScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	app := tui.NewApp(screen, passwordInstance, tui.Options{
+		Verify: func(password string) (bool, error) {
+			return password == "master", nil
+		},
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run()
+	}()
+	if !assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "1/1 entries")
+	})) {
+		return
+	}
+	typeText(screen, "L")
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "Enter the master password")
+	}))
+
+	// 直接按 Enter 或者输入错误的主密码都不能解锁
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	typeText(screen, "wrong")
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "wrong master password") && !strings.Contains(text, "entries")
+	}))
+
+	// q 是主密码的一部分，不会退出
+	typeText(screen, "masterq")
+	screen.InjectKey(tcell.KeyBackspace2, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(waitFor(t, screen, func(text string) bool {
+		return strings.Contains(text, "1/1 entries")
+	}))
+	typeText(screen, "q")
+	select {
+	case err := <-done:
+		assert.NoError(err)
+	case <-time.After(3 * time.Second):
+		t.Error("app did not quit")
+	}
+}
+
+func init() {
+	zaplog.LoggerInitFileOnly()
+}
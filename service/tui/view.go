@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// maskedPassword 隐藏密码时显示的内容
+const maskedPassword = "••••••••"
+
+var (
+	styleDefault  = tcell.StyleDefault
+	styleTitle    = tcell.StyleDefault.Bold(true).Foreground(tcell.ColorBlue)
+	styleSelected = tcell.StyleDefault.Reverse(true)
+	styleLabel    = tcell.StyleDefault.Foreground(tcell.ColorTeal)
+	styleSecret   = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	styleStatus   = tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	styleHelp     = tcell.StyleDefault.Foreground(tcell.ColorGray)
+)
+
+// draw 根据当前状态绘制整个屏幕
+func (app *App) draw() {
+	app.screen.Clear()
+	width, height := app.screen.Size()
+	if app.mode == modeLocked {
+		app.drawLocked(width, height)
+		app.screen.Show()
+		return
+	}
+
+	// 标题和过滤条件
+	title := "pm - " + strconv.Itoa(len(app.visible)) + "/" + strconv.Itoa(len(app.entries)) + " entries"
+	drawText(app.screen, 0, 0, width, title, styleTitle)
+	if len(app.filter) > 0 || app.mode == modeFilter {
+		filter := "/" + string(app.filter)
+		drawText(app.screen, width-runewidth.StringWidth(filter)-1, 0, width, filter, styleStatus)
+	}
+
+	listWidth := width / 3
+	if listWidth < 20 {
+		listWidth = min(20, width)
+	}
+	app.drawList(0, 2, listWidth, height-4)
+	if app.mode == modeForm {
+		app.drawForm(listWidth+2, 2, width-listWidth-2)
+	} else {
+		app.drawDetail(listWidth+2, 2, width-listWidth-2)
+	}
+	app.drawStatus(width, height)
+	app.screen.Show()
+}
+
+// drawList 绘制左侧的条目列表
+func (app *App) drawList(x, y, width, height int) {
+	if height <= 0 {
+		return
+	}
+	// 保证选中项在可见范围内
+	if app.selected < app.offset {
+		app.offset = app.selected
+	}
+	if app.selected >= app.offset+height {
+		app.offset = app.selected - height + 1
+	}
+	for row := 0; row < height; row++ {
+		index := app.offset + row
+		if index >= len(app.visible) {
+			break
+		}
+		style := styleDefault
+		if index == app.selected {
+			style = styleSelected
+		}
+		line := app.visible[index].Key
+		if app.visible[index].Platform != "" {
+			line += " (" + app.visible[index].Platform + ")"
+		}
+		drawText(app.screen, x, y+row, width, padRight(line, width), style)
+	}
+}
+
+// drawDetail 绘制右侧的详情
+func (app *App) drawDetail(x, y, width int) {
+	data, ok := app.current()
+	if !ok {
+		drawText(app.screen, x, y, width, "No entries", styleHelp)
+		return
+	}
+	secret := maskedPassword
+	if app.revealed {
+		secret = app.secret
+	}
+	rows := []struct {
+		label string
+		value string
+		style tcell.Style
+	}{
+		{"Key", data.Key, styleDefault},
+		{"Platform", data.Platform, styleDefault},
+		{"Username", data.Username, styleDefault},
+		{"URL", data.URL, styleDefault},
+		{"Tags", joinTags(data.Tags), styleDefault},
+		{"Password", secret, styleSecret},
+	}
+	for i, row := range rows {
+		drawText(app.screen, x, y+i, width, row.label+":", styleLabel)
+		drawText(app.screen, x+10, y+i, width-10, row.value, row.style)
+	}
+}
+
+// drawForm 绘制新增或编辑表单
+func (app *App) drawForm(x, y, width int) {
+	f := app.form
+	drawText(app.screen, x, y, width, f.title, styleTitle)
+	for i, field := range f.fields {
+		value := string(field.value)
+		if field.secret {
+			value = strings.Repeat("*", len(field.value))
+		}
+		style := styleDefault
+		if i == f.focus {
+			style = styleSelected
+			value += "_"
+		}
+		drawText(app.screen, x, y+2+i, width, field.label+":", styleLabel)
+		drawText(app.screen, x+10, y+2+i, width-10, padRight(value, width-10), style)
+	}
+	hint := "Tab: next field  Enter: save  Esc: cancel"
+	if f.originKey != "" {
+		hint += "  (leave password empty to keep it)"
+	}
+	drawText(app.screen, x, y+3+len(f.fields), width, hint, styleHelp)
+}
+
+// drawStatus 绘制底部的状态栏和快捷键提示
+func (app *App) drawStatus(width, height int) {
+	var help string
+	switch app.mode {
+	case modeFilter:
+		help = "type to filter  Enter: done  Esc: clear"
+	case modeForm:
+		help = "editing"
+	case modeConfirmDelete:
+		data, _ := app.current()
+		help = "delete " + data.Key + "? (y/n)"
+	default:
+		help = "/: filter  j/k: move  r: reveal  c: copy  u: copy user  a: add  e: edit  d: delete  L: lock  q: quit"
+	}
+	if app.status != "" {
+		drawText(app.screen, 0, height-2, width, app.status, styleStatus)
+	}
+	drawText(app.screen, 0, height-1, width, help, styleHelp)
+}
+
+// drawLocked 绘制锁定画面，没有主密码时只是隐藏了界面
+func (app *App) drawLocked(width, height int) {
+	message := "Hidden. Press Enter to show, q to quit."
+	if app.opts.Verify != nil {
+		message = "Locked. Enter the master password: "
+	}
+	x := (width - runewidth.StringWidth(message)) / 2
+	if x < 0 {
+		x = 0
+	}
+	message += strings.Repeat("*", len(app.lockInput))
+	drawText(app.screen, x, height/2, width, message, styleTitle)
+	if app.status != "" {
+		drawText(app.screen, x, height/2+1, width, app.status, styleStatus)
+	}
+}
+
+// drawText 在指定位置绘制文本，超出宽度的部分截断
+func drawText(screen tcell.Screen, x, y, width int, text string, style tcell.Style) {
+	col := 0
+	for _, r := range text {
+		w := runewidth.RuneWidth(r)
+		if col+w > width {
+			return
+		}
+		screen.SetContent(x+col, y, r, nil, style)
+		col += w
+	}
+}
+
+// padRight 用空格补齐到指定宽度，让选中行的背景铺满
+func padRight(text string, width int) string {
+	if w := runewidth.StringWidth(text); w < width {
+		return text + strings.Repeat(" ", width-w)
+	}
+	return text
+}
+
+// joinTags 用逗号连接标签
+func joinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
@@ -0,0 +1,251 @@
+'use strict';
+
+(function () {
+  const state = { csrf: '', selected: null, editing: null };
+  const $ = (id) => document.getElementById(id);
+
+  // api sends a request to the local server and returns the decoded body.
+  async function api(method, path, body) {
+    const options = { method, headers: {}, credentials: 'same-origin' };
+    if (method !== 'GET') {
+      options.headers['X-CSRF-Token'] = state.csrf;
+    }
+    if (body !== undefined) {
+      options.headers['Content-Type'] = 'application/json';
+      options.body = JSON.stringify(body);
+    }
+    const resp = await fetch(path, options);
+    if (resp.status === 401 && path !== '/api/unlock') {
+      showUnlock();
+      throw new Error('locked');
+    }
+    const text = await resp.text();
+    const data = text ? JSON.parse(text) : null;
+    if (!resp.ok) {
+      throw new Error((data && data.error) || resp.statusText);
+    }
+    return data;
+  }
+
+  function entryPath(key) {
+    return '/api/v1/passwords/' + encodeURIComponent(key);
+  }
+
+  function showUnlock() {
+    state.csrf = '';
+    state.selected = null;
+    $('entries').replaceChildren();
+    renderDetail(null);
+    $('main').hidden = true;
+    $('unlock').hidden = false;
+    $('master-password').focus();
+  }
+
+  function showMain() {
+    $('unlock').hidden = true;
+    $('main').hidden = false;
+    $('search').focus();
+    loadEntries();
+  }
+
+  function flash(text) {
+    $('message').textContent = text;
+    setTimeout(() => { if ($('message').textContent === text) $('message').textContent = ''; }, 3000);
+  }
+
+  async function loadEntries() {
+    const query = $('search').value.trim();
+    const entries = query
+      ? await api('GET', '/api/v1/search?q=' + encodeURIComponent(query))
+      : await api('GET', '/api/v1/passwords');
+    const list = $('entries');
+    list.replaceChildren();
+    for (const entry of entries) {
+      const item = document.createElement('li');
+      item.textContent = entry.key;
+      if (entry.platform) {
+        const platform = document.createElement('span');
+        platform.className = 'platform';
+        platform.textContent = entry.platform;
+        item.append(platform);
+      }
+      if (entry.key === state.selected) item.classList.add('selected');
+      item.addEventListener('click', () => select(entry.key));
+      list.append(item);
+    }
+  }
+
+  async function select(key) {
+    state.selected = key;
+    for (const item of $('entries').children) {
+      item.classList.toggle('selected', item.firstChild.textContent === key);
+    }
+    renderDetail(await api('GET', entryPath(key)));
+  }
+
+  function field(list, name, value) {
+    const dt = document.createElement('dt');
+    dt.textContent = name;
+    const dd = document.createElement('dd');
+    dd.textContent = value || '';
+    list.append(dt, dd);
+    return dd;
+  }
+
+  function button(text, className, onClick) {
+    const b = document.createElement('button');
+    b.type = 'button';
+    b.textContent = text;
+    if (className) b.className = className;
+    b.addEventListener('click', onClick);
+    return b;
+  }
+
+  function renderDetail(entry) {
+    const detail = $('detail');
+    detail.replaceChildren();
+    if (!entry) {
+      const empty = document.createElement('p');
+      empty.className = 'empty';
+      empty.textContent = 'Select an entry';
+      detail.append(empty);
+      return;
+    }
+    const list = document.createElement('dl');
+    field(list, 'Key', entry.key);
+    const password = field(list, 'Password', '••••••••');
+    field(list, 'Platform', entry.platform);
+    field(list, 'Username', entry.username);
+    field(list, 'URL', entry.url);
+    field(list, 'Tags', (entry.tags || []).join(', '));
+    let revealed = false;
+    const actions = document.createElement('div');
+    actions.className = 'actions';
+    actions.append(
+      button('Reveal', 'secondary', (event) => {
+        revealed = !revealed;
+        password.textContent = revealed ? entry.password : '••••••••';
+        event.target.textContent = revealed ? 'Hide' : 'Reveal';
+      }),
+      button('Copy password', '', () => copy(entry.password, 'Password copied')),
+      button('Copy username', 'secondary', () => copy(entry.username || '', 'Username copied')),
+      button('Edit', 'secondary', () => openEditor(entry)),
+      button('Delete', 'danger', () => remove(entry.key)),
+    );
+    detail.append(list, actions);
+  }
+
+  async function copy(text, message) {
+    try {
+      await navigator.clipboard.writeText(text);
+      flash(message);
+    } catch (err) {
+      flash('Copy failed: ' + err.message);
+    }
+  }
+
+  async function remove(key) {
+    if (!confirm('Delete ' + key + '?')) return;
+    try {
+      await api('DELETE', entryPath(key));
+      state.selected = null;
+      renderDetail(null);
+      await loadEntries();
+      flash('Deleted ' + key);
+    } catch (err) {
+      flash(err.message);
+    }
+  }
+
+  function openEditor(entry) {
+    state.editing = entry;
+    $('editor-title').textContent = entry ? 'Edit ' + entry.key : 'Add entry';
+    $('field-key').value = entry ? entry.key : '';
+    $('field-password').value = '';
+    $('field-password').required = !entry;
+    $('field-password').placeholder = entry ? 'Leave empty to keep the current password' : '';
+    $('field-platform').value = entry ? entry.platform : '';
+    $('field-username').value = entry ? entry.username || '' : '';
+    $('field-url').value = entry ? entry.url || '' : '';
+    $('field-tags').value = entry ? (entry.tags || []).join(', ') : '';
+    $('editor-error').textContent = '';
+    $('editor').showModal();
+  }
+
+  async function saveEditor(event) {
+    event.preventDefault();
+    const tags = $('field-tags').value.split(',').map((t) => t.trim()).filter((t) => t);
+    const values = {
+      key: $('field-key').value.trim(),
+      password: $('field-password').value,
+      platform: $('field-platform').value.trim(),
+      username: $('field-username').value.trim(),
+      url: $('field-url').value.trim(),
+      tags,
+    };
+    try {
+      let saved;
+      if (state.editing) {
+        const changes = { username: values.username, url: values.url, tags };
+        if (values.key !== state.editing.key) changes.key = values.key;
+        if (values.password) changes.password = values.password;
+        if (values.platform && values.platform !== state.editing.platform) changes.platform = values.platform;
+        saved = await api('PATCH', entryPath(state.editing.key), changes);
+      } else {
+        saved = await api('POST', '/api/v1/passwords', values);
+      }
+      $('editor').close();
+      state.selected = saved.key;
+      await loadEntries();
+      renderDetail(saved);
+      flash('Saved ' + saved.key);
+    } catch (err) {
+      $('editor-error').textContent = err.message;
+    }
+  }
+
+  async function unlock(event) {
+    event.preventDefault();
+    try {
+      const session = await api('POST', '/api/unlock', { password: $('master-password').value });
+      state.csrf = session.csrf_token;
+      $('master-password').value = '';
+      $('unlock-error').textContent = '';
+      showMain();
+    } catch (err) {
+      $('unlock-error').textContent = err.message;
+    }
+  }
+
+  async function lock() {
+    try {
+      await api('POST', '/api/lock');
+    } finally {
+      showUnlock();
+    }
+  }
+
+  // checkSession shows the unlock screen once the server has locked the session.
+  async function checkSession() {
+    const session = await api('GET', '/api/session');
+    if (!session.unlocked) {
+      if (!$('main').hidden || $('unlock').hidden) showUnlock();
+      return;
+    }
+    state.csrf = session.csrf_token;
+    if ($('main').hidden) showMain();
+  }
+
+  let searchTimer;
+  $('search').addEventListener('input', () => {
+    clearTimeout(searchTimer);
+    searchTimer = setTimeout(() => loadEntries().catch((err) => flash(err.message)), 150);
+  });
+  $('unlock-form').addEventListener('submit', unlock);
+  $('editor-form').addEventListener('submit', saveEditor);
+  $('editor-cancel').addEventListener('click', () => $('editor').close());
+  $('add-button').addEventListener('click', () => openEditor(null));
+  $('lock-button').addEventListener('click', lock);
+  setInterval(() => checkSession().catch(() => {}), 15000);
+  checkSession().catch(() => showUnlock());
+})();
@@ -0,0 +1,294 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"password_manager/service/api"
+	dbfilekit "password_manager/service/dbfile_Kit"
+	"password_manager/service/master"
+	"password_manager/service/password"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultLockAfter 默认的自动锁定时间
+	DefaultLockAfter = 5 * time.Minute
+
+	sessionCookieName = "pm_session"
+	csrfHeaderName    = "X-CSRF-Token"
+	tokenLength       = 32
+	maxBodySize       = 1 << 16
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Options 网页界面的配置
+type Options struct {
+	// LockAfter 会话空闲多久后自动锁定
+	LockAfter time.Duration
+	// Now 获取当前时间，测试时可以替换
+	Now func() time.Time
+}
+
+// session 已经用主密码解锁的浏览器会话
+type session struct {
+	csrfToken  string
+	lastActive time.Time
+}
+
+// Server 提供内嵌的单页面应用以及受会话保护的 API
+type Server struct {
+	logger    *zap.Logger
+	master    *master.MasterService
+	api       http.Handler
+	lockAfter time.Duration
+	now       func() time.Time
+	mux       *http.ServeMux
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewServer 创建网页界面服务
+func NewServer(srv *password.PasswordService, kit *dbfilekit.DBKitImpl, masterSrv *master.MasterService, opts Options) *Server {
+	if opts.LockAfter <= 0 {
+		opts.LockAfter = DefaultLockAfter
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	server := &Server{
+		logger:    zap.L(),
+		master:    masterSrv,
+		api:       http.StripPrefix("/api", api.NewHandler(srv, kit)),
+		lockAfter: opts.LockAfter,
+		now:       opts.Now,
+		mux:       http.NewServeMux(),
+		sessions:  make(map[string]*session),
+	}
+	server.routes()
+	return server
+}
+
+// routes 注册所有路径
+func (s *Server) routes() {
+	static, _ := fs.Sub(staticFiles, "static")
+	s.mux.Handle("GET /", http.FileServerFS(static))
+	s.mux.HandleFunc("GET /api/session", s.handleSession)
+	s.mux.HandleFunc("POST /api/unlock", s.handleUnlock)
+	s.mux.HandleFunc("POST /api/lock", s.requireSession(s.handleLock))
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete} {
+		s.mux.HandleFunc(method+" /api/v1/", s.requireSession(s.api.ServeHTTP))
+	}
+}
+
+// ServeHTTP 实现 http.Handler，先检查 Host 和 Origin
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("Cache-Control", "no-store")
+	header.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Referrer-Policy", "no-referrer")
+	//只接受发往本机的请求，防止 DNS 重绑定
+	if !isLoopbackHost(r.Host) {
+		writeError(w, http.StatusForbidden, "forbidden host")
+		return
+	}
+	//修改数据的请求必须来自同一个页面
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+			writeError(w, http.StatusForbidden, "cross-origin request rejected")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleSession 返回当前会话的状态，不会刷新空闲时间
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	resp := sessionResponse{LockAfter: int(s.lockAfter.Seconds())}
+	s.mu.Lock()
+	if id, current := s.currentSession(r); current != nil {
+		if s.expired(current) {
+			delete(s.sessions, id)
+		} else {
+			resp.Unlocked = true
+			resp.CSRFToken = current.csrfToken
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleUnlock 校验主密码并创建会话
+func (s *Server) handleUnlock(w http.ResponseWriter, r *http.Request) {
+	var req unlockRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ok, err := s.master.Verify(req.Password)
+	if err != nil {
+		s.logger.Error("verify master password failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		s.logger.Warn("wrong master password in web ui")
+		writeError(w, http.StatusUnauthorized, "wrong master password")
+		return
+	}
+	id, err := randomToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.mu.Lock()
+	s.sessions[id] = &session{csrfToken: csrfToken, lastActive: s.now()}
+	s.mu.Unlock()
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	writeJSON(w, http.StatusOK, sessionResponse{
+		Unlocked:  true,
+		CSRFToken: csrfToken,
+		LockAfter: int(s.lockAfter.Seconds()),
+	})
+}
+
+// handleLock 立即锁定当前会话
+func (s *Server) handleLock(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if id, current := s.currentSession(r); current != nil {
+		delete(s.sessions, id)
+	}
+	s.mu.Unlock()
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireSession 要求已解锁的会话，修改数据的请求还要携带 CSRF 令牌
+func (s *Server) requireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		id, current := s.currentSession(r)
+		if current == nil {
+			s.mu.Unlock()
+			writeError(w, http.StatusUnauthorized, "locked")
+			return
+		}
+		if s.expired(current) {
+			delete(s.sessions, id)
+			s.mu.Unlock()
+			writeError(w, http.StatusUnauthorized, "locked")
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			token := r.Header.Get(csrfHeaderName)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(current.csrfToken)) != 1 {
+				s.mu.Unlock()
+				writeError(w, http.StatusForbidden, "invalid CSRF token")
+				return
+			}
+		}
+		current.lastActive = s.now()
+		s.mu.Unlock()
+		next(w, r)
+	}
+}
+
+// currentSession 根据 cookie 查找会话，调用时需要持有锁
+func (s *Server) currentSession(r *http.Request) (string, *session) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return "", nil
+	}
+	return cookie.Value, s.sessions[cookie.Value]
+}
+
+// expired 会话是否已经超过空闲时间
+func (s *Server) expired(current *session) bool {
+	return s.now().Sub(current.lastActive) >= s.lockAfter
+}
+
+// isLoopbackHost Host 是否指向本机
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameOrigin Origin 是否与请求的 Host 相同
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == host
+}
+
+// randomToken 生成随机令牌
+func randomToken() (string, error) {
+	token := make([]byte, tokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// unlockRequest 解锁请求
+type unlockRequest struct {
+	Password string `json:"password"`
+}
+
+// sessionResponse 会话状态
+type sessionResponse struct {
+	Unlocked  bool   `json:"unlocked"`
+	CSRFToken string `json:"csrf_token,omitempty"`
+	LockAfter int    `json:"lock_after"`
+}
+
+// writeJSON 以 JSON 格式返回结果
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError 返回错误信息
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
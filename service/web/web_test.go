@@ -0,0 +1,193 @@
+package web_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"password_manager/service/master"
+	"password_manager/service/testvault"
+	"password_manager/service/web"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const masterPassword = "correct horse"
+
+// fakeClock 可以手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestServer 使用临时密码库创建网页服务
+func newTestServer(t *testing.T, clock *fakeClock) *httptest.Server {
+	vault := testvault.New(t)
+	masterSrv := master.NewMasterService(vault.DB)
+	if err := masterSrv.SetMasterPassword(masterPassword); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(web.NewServer(vault.Srv, vault.Kit, masterSrv, web.Options{
+		LockAfter: time.Minute,
+		Now:       clock.Now,
+	}))
+	jar, _ := cookiejar.New(nil)
+	server.Client().Jar = jar
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do 发送请求，csrf 不为空时携带 CSRF 令牌
+func do(t *testing.T, server *httptest.Server, method, path, body, csrf string) (int, map[string]any) {
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csrf != "" {
+		req.Header.Set("X-CSRF-Token", csrf)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	var value map[string]any
+	json.Unmarshal(data, &value)
+	return resp.StatusCode, value
+}
+
+// unlock 使用主密码解锁并返回 CSRF 令牌
+func unlock(t *testing.T, server *httptest.Server) string {
+	status, body := do(t, server, http.MethodPost, "/api/unlock", `{"password":"`+masterPassword+`"}`, "")
+	if status != http.StatusOK {
+		t.Fatalf("unlock failed: %d %v", status, body)
+	}
+	return body["csrf_token"].(string)
+}
+
+func TestStatic(t *testing.T) {
+	assert := assert.New(t)
+	server := newTestServer(t, &fakeClock{now: time.Now()})
+	resp, err := server.Client().Get(server.URL + "/")
+	if !assert.NoError(err) {
+		return
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Contains(string(data), "<title>pm</title>")
+	assert.Contains(resp.Header.Get("Content-Security-Policy"), "default-src 'self'")
+
+	resp, err = server.Client().Get(server.URL + "/app.js")
+	if assert.NoError(err) {
+		resp.Body.Close()
+		assert.Equal(http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestUnlockAndCSRF(t *testing.T) {
+	assert := assert.New(t)
+	server := newTestServer(t, &fakeClock{now: time.Now()})
+
+	// 未解锁
+	status, _ := do(t, server, http.MethodGet, "/api/v1/passwords", "", "")
+	assert.Equal(http.StatusUnauthorized, status)
+	status, body := do(t, server, http.MethodGet, "/api/session", "", "")
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(false, body["unlocked"])
+
+	// 错误的主密码
+	status, _ = do(t, server, http.MethodPost, "/api/unlock", `{"password":"wrong"}`, "")
+	assert.Equal(http.StatusUnauthorized, status)
+
+	csrf := unlock(t, server)
+	assert.NotEmpty(csrf)
+	status, body = do(t, server, http.MethodGet, "/api/session", "", "")
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(true, body["unlocked"])
+	assert.Equal(csrf, body["csrf_token"])
+
+	// 修改数据需要 CSRF 令牌
+	entry := `{"key":"github","password":"maple-Circuit-73","platform":"github.com"}`
+	status, _ = do(t, server, http.MethodPost, "/api/v1/passwords", entry, "")
+	assert.Equal(http.StatusForbidden, status)
+	status, _ = do(t, server, http.MethodPost, "/api/v1/passwords", entry, "wrong")
+	assert.Equal(http.StatusForbidden, status)
+	status, _ = do(t, server, http.MethodPost, "/api/v1/passwords", entry, csrf)
+	assert.Equal(http.StatusCreated, status)
+
+	status, body = do(t, server, http.MethodGet, "/api/v1/passwords/github", "", "")
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("maple-Circuit-73", body["password"])
+
+	// 跨站请求
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/v1/passwords/github", nil)
+	req.Header.Set("X-CSRF-Token", csrf)
+	req.Header.Set("Origin", "http://evil.example")
+	resp, err := server.Client().Do(req)
+	if assert.NoError(err) {
+		resp.Body.Close()
+		assert.Equal(http.StatusForbidden, resp.StatusCode)
+	}
+
+	// DNS 重绑定
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/api/session", nil)
+	req.Host = "evil.example"
+	resp, err = server.Client().Do(req)
+	if assert.NoError(err) {
+		resp.Body.Close()
+		assert.Equal(http.StatusForbidden, resp.StatusCode)
+	}
+
+	status, _ = do(t, server, http.MethodDelete, "/api/v1/passwords/github", "", csrf)
+	assert.Equal(http.StatusNoContent, status)
+
+	// 手动锁定
+	status, _ = do(t, server, http.MethodPost, "/api/lock", "", csrf)
+	assert.Equal(http.StatusNoContent, status)
+	status, _ = do(t, server, http.MethodGet, "/api/v1/passwords", "", "")
+	assert.Equal(http.StatusUnauthorized, status)
+}
+
+func TestAutoLock(t *testing.T) {
+	assert := assert.New(t)
+	clock := &fakeClock{now: time.Now()}
+	server := newTestServer(t, clock)
+	unlock(t, server)
+
+	// 有操作时重新计时
+	clock.Add(50 * time.Second)
+	status, _ := do(t, server, http.MethodGet, "/api/v1/passwords", "", "")
+	assert.Equal(http.StatusOK, status)
+	clock.Add(50 * time.Second)
+	status, _ = do(t, server, http.MethodGet, "/api/v1/passwords", "", "")
+	assert.Equal(http.StatusOK, status)
+
+	// 查询会话状态不会刷新空闲时间
+	clock.Add(50 * time.Second)
+	_, body := do(t, server, http.MethodGet, "/api/session", "", "")
+	assert.Equal(true, body["unlocked"])
+	clock.Add(20 * time.Second)
+	_, body = do(t, server, http.MethodGet, "/api/session", "", "")
+	assert.Equal(false, body["unlocked"])
+	status, _ = do(t, server, http.MethodGet, "/api/v1/passwords", "", "")
+	assert.Equal(http.StatusUnauthorized, status)
+}